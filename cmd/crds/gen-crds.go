@@ -0,0 +1,31 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/operator"
+)
+
+func main() {
+	docDir, helmTemplate := os.Args[1], os.Args[2]
+
+	manifests, err := operator.CRDManifests()
+	if err != nil {
+		panic(err)
+	}
+	for name, manifest := range manifests {
+		if err := ioutil.WriteFile(filepath.Join(docDir, name), manifest, 0644); err != nil {
+			panic(err)
+		}
+	}
+
+	template, err := operator.CRDHelmTemplate()
+	if err != nil {
+		panic(err)
+	}
+	if err := ioutil.WriteFile(helmTemplate, template, 0644); err != nil {
+		panic(err)
+	}
+}
@@ -14,7 +14,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen/backend"
+	vaultgenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/vault_generator"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
 	"code.cloudfoundry.org/quarks-secret/pkg/kube/operator"
+	"code.cloudfoundry.org/quarks-secret/pkg/plugin"
 	"code.cloudfoundry.org/quarks-secret/version"
 	"code.cloudfoundry.org/quarks-utils/pkg/cmd"
 	"code.cloudfoundry.org/quarks-utils/pkg/config"
@@ -50,9 +55,39 @@ var rootCmd = &cobra.Command{
 
 		cfg.MaxQuarksSecretWorkers = viper.GetInt("max-workers")
 
+		cmd.OperatorNamespace(cfg, log, "operator-namespace")
+		cfg.WebhookServerHost = viper.GetString("webhook-service-host")
+		cfg.WebhookServerPort = viper.GetInt32("webhook-service-port")
+		cfg.WebhookUseServiceRef = viper.GetBool("webhook-use-service-reference")
+
 		cmd.CtxTimeOut(cfg)
 		cmd.Meltdown(cfg)
 
+		defaults := credsgen.Defaults{
+			KeyAlgorithm:       viper.GetString("generator-key-algorithm"),
+			KeySize:            viper.GetInt("generator-key-size"),
+			CertificateExpiry:  viper.GetInt("generator-certificate-expiry"),
+			PasswordLength:     viper.GetInt("generator-password-length"),
+			PasswordCharacters: viper.GetString("generator-password-characters"),
+			FIPS:               viper.GetBool("fips"),
+		}
+		if err := defaults.Validate(); err != nil {
+			return wrapError(err, "Invalid generator defaults.")
+		}
+
+		generators, err := backend.New(log, defaults, backend.Config{
+			Default: viper.GetString("generator"),
+			Vault: vaultgenerator.Config{
+				Address: viper.GetString("vault-address"),
+				Token:   viper.GetString("vault-token"),
+				PKIPath: viper.GetString("vault-pki-path"),
+				PKIRole: viper.GetString("vault-pki-role"),
+			},
+		})
+		if err != nil {
+			return wrapError(err, "Invalid generator backends.")
+		}
+
 		ctx := ctxlog.NewParentContext(log)
 
 		err = cmd.ApplyCRDs(ctx, operator.ApplyCRDs, restConfig)
@@ -60,7 +95,13 @@ var rootCmd = &cobra.Command{
 			return wrapError(err, "Couldn't apply CRDs.")
 		}
 
-		mgr, err := operator.NewManager(ctx, cfg, restConfig, manager.Options{
+		options := quarkssecret.Options{
+			Config:       cfg,
+			Generators:   generators,
+			Defaults:     &defaults,
+			PluginRunner: plugin.NewRunner(viper.GetString("plugin-dir")),
+		}
+		mgr, err := operator.NewManager(ctx, options, restConfig, manager.Options{
 			MetricsBindAddress: "0",
 			LeaderElection:     false,
 		})
@@ -103,11 +144,72 @@ func init() {
 	cmd.LoggerFlags(pf, argToEnv)
 	cmd.ApplyCRDsFlags(pf, argToEnv)
 	cmd.MeltdownFlags(pf, argToEnv)
+	cmd.OperatorNamespaceFlags(pf, argToEnv, "operator-namespace")
 
 	pf.Int("max-workers", 1, "Maximum number of workers concurrently running the controller")
 	_ = viper.BindPFlag("max-workers", pf.Lookup("max-workers"))
 	argToEnv["max-workers"] = "MAX_WORKERS"
 
+	pf.String("webhook-service-host", "", "Hostname/IP under which the webhook server can be reached from the cluster, webhooks are disabled if empty and no service reference is used")
+	_ = viper.BindPFlag("webhook-service-host", pf.Lookup("webhook-service-host"))
+	argToEnv["webhook-service-host"] = "WEBHOOK_SERVICE_HOST"
+
+	pf.Int32("webhook-service-port", 2999, "Port the webhook server listens on")
+	_ = viper.BindPFlag("webhook-service-port", pf.Lookup("webhook-service-port"))
+	argToEnv["webhook-service-port"] = "WEBHOOK_SERVICE_PORT"
+
+	pf.Bool("webhook-use-service-reference", false, "If true the webhook service is targeted using a service reference instead of a URL")
+	_ = viper.BindPFlag("webhook-use-service-reference", pf.Lookup("webhook-use-service-reference"))
+	argToEnv["webhook-use-service-reference"] = "WEBHOOK_USE_SERVICE_REFERENCE"
+
+	pf.String("generator-key-algorithm", credsgen.DefaultKeyAlgorithm, "Default algorithm of generated certificate keys, rsa or ecdsa")
+	_ = viper.BindPFlag("generator-key-algorithm", pf.Lookup("generator-key-algorithm"))
+	argToEnv["generator-key-algorithm"] = "GENERATOR_KEY_ALGORITHM"
+
+	pf.Int("generator-key-size", credsgen.DefaultKeySize, "Default size of generated keys in bits, 256, 384 or 521 for ecdsa")
+	_ = viper.BindPFlag("generator-key-size", pf.Lookup("generator-key-size"))
+	argToEnv["generator-key-size"] = "GENERATOR_KEY_SIZE"
+
+	pf.Int("generator-certificate-expiry", credsgen.DefaultCertificateExpiry, "Default validity of generated certificates in days")
+	_ = viper.BindPFlag("generator-certificate-expiry", pf.Lookup("generator-certificate-expiry"))
+	argToEnv["generator-certificate-expiry"] = "GENERATOR_CERTIFICATE_EXPIRY"
+
+	pf.Int("generator-password-length", credsgen.DefaultPasswordLength, "Default length of generated passwords")
+	_ = viper.BindPFlag("generator-password-length", pf.Lookup("generator-password-length"))
+	argToEnv["generator-password-length"] = "GENERATOR_PASSWORD_LENGTH"
+
+	pf.String("generator-password-characters", "", "Characters generated passwords consist of, alphanumeric if empty")
+	_ = viper.BindPFlag("generator-password-characters", pf.Lookup("generator-password-characters"))
+	argToEnv["generator-password-characters"] = "GENERATOR_PASSWORD_CHARACTERS"
+
+	pf.Bool("fips", false, "If true only FIPS 140 approved algorithms and key sizes are used to generate secrets")
+	_ = viper.BindPFlag("fips", pf.Lookup("fips"))
+	argToEnv["fips"] = "FIPS"
+
+	pf.String("generator", backend.InMemory, "Generator backend used by quarks secrets without a generator reference, in-memory or vault")
+	_ = viper.BindPFlag("generator", pf.Lookup("generator"))
+	argToEnv["generator"] = "GENERATOR"
+
+	pf.String("vault-address", "", "Address of the Vault server, the vault generator is disabled if empty")
+	_ = viper.BindPFlag("vault-address", pf.Lookup("vault-address"))
+	argToEnv["vault-address"] = "VAULT_ADDR"
+
+	pf.String("vault-token", "", "Token the vault generator authenticates with")
+	_ = viper.BindPFlag("vault-token", pf.Lookup("vault-token"))
+	argToEnv["vault-token"] = "VAULT_TOKEN"
+
+	pf.String("vault-pki-path", "pki", "Mount path of the Vault PKI secrets engine")
+	_ = viper.BindPFlag("vault-pki-path", pf.Lookup("vault-pki-path"))
+	argToEnv["vault-pki-path"] = "VAULT_PKI_PATH"
+
+	pf.String("vault-pki-role", "", "Role of the Vault PKI secrets engine certificates are issued for")
+	_ = viper.BindPFlag("vault-pki-role", pf.Lookup("vault-pki-role"))
+	argToEnv["vault-pki-role"] = "VAULT_PKI_ROLE"
+
+	pf.String("plugin-dir", "", "Directory with the exec plugins for custom secret types, e.g. <dir>/example.com/ldap-password for the type example.com/ldap-password")
+	_ = viper.BindPFlag("plugin-dir", pf.Lookup("plugin-dir"))
+	argToEnv["plugin-dir"] = "PLUGIN_DIR"
+
 	// Add env variables to help
 	cmd.AddEnvToUsage(rootCmd, argToEnv)
 
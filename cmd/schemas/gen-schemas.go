@@ -0,0 +1,15 @@
+package main
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/apis/schemagen"
+)
+
+func main() {
+	for _, dir := range os.Args[1:] {
+		if err := schemagen.Generate(dir); err != nil {
+			panic(err)
+		}
+	}
+}
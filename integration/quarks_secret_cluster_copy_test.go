@@ -0,0 +1,118 @@
+package integration_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/testing/machine"
+)
+
+// kubeconfigFor returns a kubeconfig for the API server of the rest config
+func kubeconfigFor(config *rest.Config) []byte {
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters["remote"] = &clientcmdapi.Cluster{
+		Server:                   config.Host,
+		CertificateAuthorityData: config.CAData,
+	}
+	kubeconfig.AuthInfos["remote"] = &clientcmdapi.AuthInfo{
+		ClientCertificateData: config.CertData,
+		ClientKeyData:         config.KeyData,
+		Token:                 config.BearerToken,
+	}
+	kubeconfig.Contexts["remote"] = &clientcmdapi.Context{Cluster: "remote", AuthInfo: "remote"}
+	kubeconfig.CurrentContext = "remote"
+
+	data, err := clientcmd.Write(*kubeconfig)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+var _ = Describe("QuarksCopies to a remote cluster", func() {
+	var (
+		remote       *envtest.Environment
+		remoteClient crc.Client
+		tearDowns    []machine.TearDownFunc
+	)
+	const (
+		qsecName        = "test.qsec"
+		clusterName     = "remote-cluster"
+		remoteNamespace = "workload"
+		remoteSecret    = "remote-copy"
+	)
+
+	BeforeEach(func() {
+		By("Starting the remote API server")
+		remote = &envtest.Environment{}
+		remoteConfig, err := remote.Start()
+		Expect(err).NotTo(HaveOccurred())
+
+		remoteClient, err = crc.New(remoteConfig, crc.Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Creating the kubeconfig secret for the remote cluster")
+		tearDown, err := env.CreateSecret(env.Namespace, corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: env.Namespace},
+			Data:       map[string][]byte{qsv1a1.ClusterKubeconfigKey: kubeconfigFor(remoteConfig)},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		tearDowns = append(tearDowns, tearDown)
+
+		By("Creating the placeholder in the remote cluster")
+		ctx := context.Background()
+		Expect(remoteClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: remoteNamespace}})).To(Succeed())
+		Expect(remoteClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        remoteSecret,
+				Namespace:   remoteNamespace,
+				Annotations: map[string]string{qsv1a1.AnnotationCopyOf: env.Namespace + "/" + qsecName},
+			},
+		})).To(Succeed())
+
+		By("Creating the quarkssecret with a copy in the remote cluster")
+		qsec := env.DefaultQuarksSecret(qsecName)
+		qsec.Spec.Copies = []qsv1a1.Copy{{Name: remoteSecret, Namespace: remoteNamespace, Cluster: clusterName}}
+		_, tearDown, err = env.CreateQuarksSecret(env.Namespace, qsec)
+		Expect(err).NotTo(HaveOccurred())
+		tearDowns = append(tearDowns, tearDown)
+	})
+
+	AfterEach(func() {
+		Expect(env.TearDownAll(tearDowns)).To(Succeed())
+		Expect(remote.Stop()).To(Succeed())
+	})
+
+	It("copies the generated secret to the remote cluster", func() {
+		By("Checking the copy in the remote cluster")
+		Eventually(func() []byte {
+			secret := &corev1.Secret{}
+			err := remoteClient.Get(context.Background(), types.NamespacedName{Name: remoteSecret, Namespace: remoteNamespace}, secret)
+			Expect(err).NotTo(HaveOccurred())
+			return secret.Data["password"]
+		}, 10*time.Second).ShouldNot(BeEmpty())
+
+		By("Checking the copy status of the remote cluster")
+		Eventually(func() qsv1a1.CopyState {
+			qsec, err := env.GetQuarksSecret(env.Namespace, qsecName)
+			Expect(err).NotTo(HaveOccurred())
+			for _, copy := range qsec.Status.Copies {
+				if copy.Cluster == clusterName {
+					return copy.State
+				}
+			}
+			return ""
+		}, 10*time.Second).Should(Equal(qsv1a1.CopyStateSynced))
+	})
+})
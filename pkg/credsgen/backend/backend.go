@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	inmemorygenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/in_memory_generator"
+	vaultgenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/vault_generator"
+)
+
+const (
+	// InMemory is the name of the backend, which generates secrets in the
+	// operator
+	InMemory = "in-memory"
+	// Vault is the name of the backend, which lets Vault generate secrets
+	Vault = "vault"
+)
+
+// Config configures the generator backends of the operator
+type Config struct {
+	// Default is the name of the backend used by quarks secrets without a
+	// generator reference
+	Default string
+	// Vault is only available, if its address is set
+	Vault vaultgenerator.Config
+}
+
+// Generators are the generator backends of the operator by name
+type Generators struct {
+	defaultName string
+	generators  map[string]credsgen.Generator
+}
+
+// New returns the generator backends of the operator. The in-memory backend
+// is always available.
+func New(log *zap.SugaredLogger, defaults credsgen.Defaults, config Config) (*Generators, error) {
+	if config.Default == "" {
+		config.Default = InMemory
+	}
+	g := &Generators{
+		defaultName: config.Default,
+		generators: map[string]credsgen.Generator{
+			InMemory: inmemorygenerator.NewInMemoryGeneratorWithDefaults(log, defaults),
+		},
+	}
+
+	if config.Vault.Address != "" {
+		if config.Vault.PKIRole == "" {
+			return nil, errors.New("the vault generator needs a PKI role")
+		}
+		g.generators[Vault] = vaultgenerator.NewVaultGenerator(log, config.Vault, defaults, &http.Client{Timeout: 30 * time.Second})
+	}
+
+	if _, ok := g.generators[config.Default]; !ok {
+		return nil, errors.Errorf("generator '%s' is not configured, configured generators: %v", config.Default, g.Names())
+	}
+	return g, nil
+}
+
+// Default returns the generator for quarks secrets without a generator
+// reference
+func (g *Generators) Default() credsgen.Generator {
+	return g.generators[g.defaultName]
+}
+
+// Get returns the generator with the name, or the default generator if the
+// name is empty
+func (g *Generators) Get(name string) (credsgen.Generator, error) {
+	if name == "" {
+		return g.Default(), nil
+	}
+	generator, ok := g.generators[name]
+	if !ok {
+		return nil, errors.Errorf("generator '%s' is not configured, configured generators: %v", name, g.Names())
+	}
+	return generator, nil
+}
+
+// Names returns the names of the configured generators
+func (g *Generators) Names() []string {
+	names := make([]string, 0, len(g.generators))
+	for name := range g.generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
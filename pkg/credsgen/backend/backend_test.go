@@ -0,0 +1,74 @@
+package backend_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen/backend"
+	inmemorygenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/in_memory_generator"
+	vaultgenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/vault_generator"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("Generators", func() {
+	var (
+		log    *zap.SugaredLogger
+		config backend.Config
+	)
+
+	BeforeEach(func() {
+		_, log = helper.NewTestLogger()
+		config = backend.Config{}
+	})
+
+	It("always has the in-memory backend as default", func() {
+		generators, err := backend.New(log, credsgen.NewDefaults(), config)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(generators.Names()).To(Equal([]string{backend.InMemory}))
+		Expect(generators.Default()).To(BeAssignableToTypeOf(&inmemorygenerator.InMemoryGenerator{}))
+
+		generator, err := generators.Get("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(generator).To(Equal(generators.Default()))
+	})
+
+	It("returns an error for backends which aren't configured", func() {
+		generators, err := backend.New(log, credsgen.NewDefaults(), config)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = generators.Get(backend.Vault)
+		Expect(err).To(MatchError(ContainSubstring("generator 'vault' is not configured")))
+	})
+
+	Context("with a vault address", func() {
+		BeforeEach(func() {
+			config.Vault = vaultgenerator.Config{Address: "http://127.0.0.1:8200", PKIRole: "quarks"}
+		})
+
+		It("adds the vault backend", func() {
+			config.Default = backend.Vault
+
+			generators, err := backend.New(log, credsgen.NewDefaults(), config)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generators.Names()).To(Equal([]string{backend.InMemory, backend.Vault}))
+			Expect(generators.Default()).To(BeAssignableToTypeOf(&vaultgenerator.VaultGenerator{}))
+		})
+
+		It("needs a PKI role", func() {
+			config.Vault.PKIRole = ""
+
+			_, err := backend.New(log, credsgen.NewDefaults(), config)
+			Expect(err).To(MatchError(ContainSubstring("needs a PKI role")))
+		})
+	})
+
+	It("returns an error if the default backend isn't configured", func() {
+		config.Default = backend.Vault
+
+		_, err := backend.New(log, credsgen.NewDefaults(), config)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,99 @@
+package credsgen
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultKeyAlgorithm is the default algorithm of certificate keys
+	DefaultKeyAlgorithm = "rsa"
+	// DefaultKeySize is the default size of RSA keys in bits
+	DefaultKeySize = 2048
+	// DefaultCertificateExpiry is the default validity of generated
+	// certificates in days
+	DefaultCertificateExpiry = 365
+)
+
+// Defaults are the settings a generator uses for everything a request
+// doesn't specify
+type Defaults struct {
+	// KeyAlgorithm of certificate keys, rsa or ecdsa
+	KeyAlgorithm string
+	// KeySize of certificate keys in bits. RSA and SSH keys use it with the
+	// rsa algorithm.
+	KeySize int
+	// CertificateExpiry is the validity of certificates in days
+	CertificateExpiry int
+	// PasswordLength is the number of characters of passwords
+	PasswordLength int
+	// PasswordCharacters are used in passwords, alphanumeric if empty
+	PasswordCharacters string
+	// FIPS restricts the generator to FIPS 140 approved algorithms. It is
+	// set for the operator and not overridden.
+	FIPS bool
+}
+
+// NewDefaults returns the built-in defaults
+func NewDefaults() Defaults {
+	return Defaults{
+		KeyAlgorithm:      DefaultKeyAlgorithm,
+		KeySize:           DefaultKeySize,
+		CertificateExpiry: DefaultCertificateExpiry,
+		PasswordLength:    DefaultPasswordLength,
+	}
+}
+
+// Override returns the defaults with the non-zero settings of o
+func (d Defaults) Override(o Defaults) Defaults {
+	if o.KeyAlgorithm != "" {
+		d.KeyAlgorithm = o.KeyAlgorithm
+		// the key size of the previous algorithm doesn't fit
+		if o.KeySize == 0 && o.KeyAlgorithm != DefaultKeyAlgorithm {
+			d.KeySize = 256
+		}
+	}
+	if o.KeySize != 0 {
+		d.KeySize = o.KeySize
+	}
+	if o.CertificateExpiry != 0 {
+		d.CertificateExpiry = o.CertificateExpiry
+	}
+	if o.PasswordLength != 0 {
+		d.PasswordLength = o.PasswordLength
+	}
+	if o.PasswordCharacters != "" {
+		d.PasswordCharacters = o.PasswordCharacters
+	}
+	return d
+}
+
+// Validate returns an error if the generator can't use the defaults
+func (d Defaults) Validate() error {
+	switch d.KeyAlgorithm {
+	case "rsa":
+		if d.KeySize < 2048 || d.KeySize > 8192 {
+			return errors.Errorf("rsa key size %d is not between 2048 and 8192", d.KeySize)
+		}
+	case "ecdsa":
+		if d.KeySize != 256 && d.KeySize != 384 && d.KeySize != 521 {
+			return errors.Errorf("ecdsa key size %d is not 256, 384 or 521", d.KeySize)
+		}
+	default:
+		return errors.Errorf("key algorithm '%s' is not rsa or ecdsa", d.KeyAlgorithm)
+	}
+	if d.FIPS {
+		if err := ValidateFIPSKeyRequest(d.KeyAlgorithm, d.KeySize); err != nil {
+			return err
+		}
+	}
+	if d.CertificateExpiry < 1 {
+		return errors.Errorf("certificate expiry %d is less than a day", d.CertificateExpiry)
+	}
+	if d.PasswordLength < 1 {
+		return errors.Errorf("password length %d is less than one character", d.PasswordLength)
+	}
+	if d.PasswordCharacters != "" && (len(d.PasswordCharacters) < 2 || len(d.PasswordCharacters) > 256) {
+		return errors.New("password characters need between 2 and 256 characters")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package credsgen
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// NotApprovedError is returned in FIPS mode for algorithms and key sizes,
+// which are not approved by FIPS 140
+type NotApprovedError struct {
+	message string
+}
+
+// NewNotApprovedError returns a new NotApprovedError
+func NewNotApprovedError(format string, args ...interface{}) error {
+	return &NotApprovedError{message: fmt.Sprintf(format, args...)}
+}
+
+// Error returns the error message
+func (e *NotApprovedError) Error() string {
+	return e.message
+}
+
+// IsNotApproved returns true if the cause of the error is a NotApprovedError
+func IsNotApproved(err error) bool {
+	_, ok := errors.Cause(err).(*NotApprovedError)
+	return ok
+}
+
+// ValidateFIPSKeyRequest returns a NotApprovedError, unless keys with the
+// algorithm and size are FIPS approved: RSA with at least 2048 bits, or
+// ECDSA on P-256 or P-384
+func ValidateFIPSKeyRequest(algorithm string, size int) error {
+	switch algorithm {
+	case "rsa":
+		if size < 2048 {
+			return NewNotApprovedError("rsa key size %d is not FIPS approved, it needs at least 2048 bits", size)
+		}
+	case "ecdsa":
+		if size != 256 && size != 384 {
+			return NewNotApprovedError("ecdsa key size %d is not FIPS approved, only P-256 and P-384 are", size)
+		}
+	default:
+		return NewNotApprovedError("key algorithm '%s' is not FIPS approved", algorithm)
+	}
+	return nil
+}
+
+// ValidateFIPSKey returns a NotApprovedError, unless the public key is
+// FIPS approved
+func ValidateFIPSKey(key crypto.PublicKey) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return ValidateFIPSKeyRequest("rsa", k.N.BitLen())
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256(), elliptic.P384():
+			return nil
+		}
+		return NewNotApprovedError("ecdsa curve %s is not FIPS approved, only P-256 and P-384 are", k.Curve.Params().Name)
+	}
+	return NewNotApprovedError("key type %T is not FIPS approved", key)
+}
+
+// ValidateFIPSCertificate returns a NotApprovedError, unless the key and the
+// signature algorithm of the PEM encoded certificate are FIPS approved
+func ValidateFIPSCertificate(certificate []byte) error {
+	block, _ := pem.Decode(certificate)
+	if block == nil {
+		return errors.New("certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "could not parse certificate")
+	}
+
+	switch cert.SignatureAlgorithm {
+	case x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA,
+		x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS,
+		x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+	default:
+		return NewNotApprovedError("certificate signature algorithm %s is not FIPS approved", cert.SignatureAlgorithm)
+	}
+
+	if err := ValidateFIPSKey(cert.PublicKey); err != nil {
+		return errors.Wrap(err, "certificate key")
+	}
+	return nil
+}
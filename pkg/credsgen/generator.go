@@ -24,6 +24,8 @@ type Certificate struct {
 	IsCA        bool
 	Certificate []byte
 	PrivateKey  []byte
+	// IssuingCA is set by generators, which sign with their own CA
+	IssuingCA []byte
 }
 
 // SSHKey represents an SSH key
@@ -47,3 +49,23 @@ type Generator interface {
 	GenerateSSHKey(name string) (SSHKey, error)
 	GenerateRSAKey(name string) (RSAKey, error)
 }
+
+// CASigner is a generator, which signs certificates with its own CA. Its
+// certificates don't need a CA reference, but it can't generate CAs.
+type CASigner interface {
+	Generator
+	SignsWithOwnCA() bool
+}
+
+// SignsWithOwnCA returns true if the generator signs certificates with its
+// own CA
+func SignsWithOwnCA(generator Generator) bool {
+	signer, ok := generator.(CASigner)
+	return ok && signer.SignsWithOwnCA()
+}
+
+// DefaultsGenerator is a generator, which can use other defaults
+type DefaultsGenerator interface {
+	Generator
+	WithDefaults(Defaults) Generator
+}
@@ -44,6 +44,10 @@ func (g InMemoryGenerator) GenerateCertificateSigningRequest(request credsgen.Ce
 
 	var csReq, privateKey []byte
 
+	if err := g.validateKeyRequest(); err != nil {
+		return csReq, privateKey, err
+	}
+
 	// Generate certificate request
 	certReq := &csr.CertificateRequest{KeyRequest: &csr.KeyRequest{A: g.Algorithm, S: g.Bits}}
 
@@ -91,6 +95,10 @@ func (g InMemoryGenerator) generateCertificate(request credsgen.CertificateGener
 
 // generateCACertificate Generate self-signed root CA certificate and private key
 func (g InMemoryGenerator) generateCACertificate(request credsgen.CertificateGenerationRequest) (credsgen.Certificate, error) {
+	if err := g.validateKeyRequest(); err != nil {
+		return credsgen.Certificate{}, err
+	}
+
 	req := &csr.CertificateRequest{
 		CA:         &csr.CAConfig{Expiry: fmt.Sprintf("%dh", g.Expiry*24)},
 		CN:         request.CommonName,
@@ -141,6 +149,11 @@ func (g InMemoryGenerator) signCertificate(csr []byte, signingProfile *config.Si
 	if err != nil {
 		return []byte{}, errors.Wrap(err, "Parsing CA private key failed.")
 	}
+	if g.FIPS {
+		if err := credsgen.ValidateFIPSCertificate(request.CA.Certificate); err != nil {
+			return []byte{}, errors.Wrap(err, "CA")
+		}
+	}
 
 	s, err := local.NewSigner(parentCAKey, parentCACert, signer.DefaultSigAlgo(parentCAKey), policy)
 	if err != nil {
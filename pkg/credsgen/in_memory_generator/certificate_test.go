@@ -109,6 +109,42 @@ var _ = Describe("InMemoryGenerator", func() {
 					Expect(len(cert.PrivateKey)).To(Equal(227))
 				})
 			})
+
+			Context("in FIPS mode", func() {
+				var g *inmemorygenerator.InMemoryGenerator
+
+				BeforeEach(func() {
+					g = generator.(*inmemorygenerator.InMemoryGenerator)
+					g.FIPS = true
+				})
+
+				It("signs with an approved CA", func() {
+					request.CommonName = "foo.com"
+					_, err := g.GenerateCertificate("foo", request)
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("rejects key sizes, which are not approved", func() {
+					g.Bits = 521
+
+					_, err := g.GenerateCertificate("foo", request)
+					Expect(err).To(HaveOccurred())
+					Expect(credsgen.IsNotApproved(err)).To(BeTrue())
+				})
+
+				It("rejects CAs with keys, which are not approved", func() {
+					g.FIPS = false
+					g.Bits = 521
+					request.CA, _ = g.GenerateCertificate("testca", credsgen.CertificateGenerationRequest{CommonName: caCommonName, IsCA: true})
+					g.FIPS = true
+					g.Bits = 256
+
+					_, err := g.GenerateCertificate("foo", request)
+					Expect(err).To(HaveOccurred())
+					Expect(credsgen.IsNotApproved(err)).To(BeTrue())
+					Expect(err.Error()).To(ContainSubstring("P-521"))
+				})
+			})
 		})
 
 		Context("when generating a CA", func() {
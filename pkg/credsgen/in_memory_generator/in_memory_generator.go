@@ -2,6 +2,8 @@ package inmemorygenerator
 
 import (
 	"go.uber.org/zap"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
 )
 
 // InMemoryGenerator represents a secret generator that generates everything
@@ -11,10 +13,52 @@ type InMemoryGenerator struct {
 	Expiry    int    // Expiration (days)
 	Algorithm string // Algorithm type
 
+	PasswordLength     int    // Default password length
+	PasswordCharacters string // Password characters, alphanumeric if empty
+	FIPS               bool   // Only use FIPS 140 approved algorithms
+
 	log *zap.SugaredLogger
 }
 
 // NewInMemoryGenerator creates a default InMemoryGenerator
 func NewInMemoryGenerator(log *zap.SugaredLogger) *InMemoryGenerator {
-	return &InMemoryGenerator{Bits: 2048, Expiry: 365, Algorithm: "rsa", log: log}
+	return NewInMemoryGeneratorWithDefaults(log, credsgen.NewDefaults())
+}
+
+// NewInMemoryGeneratorWithDefaults creates an InMemoryGenerator, which uses
+// the given defaults
+func NewInMemoryGeneratorWithDefaults(log *zap.SugaredLogger, defaults credsgen.Defaults) *InMemoryGenerator {
+	return &InMemoryGenerator{
+		Bits:               defaults.KeySize,
+		Expiry:             defaults.CertificateExpiry,
+		Algorithm:          defaults.KeyAlgorithm,
+		PasswordLength:     defaults.PasswordLength,
+		PasswordCharacters: defaults.PasswordCharacters,
+		FIPS:               defaults.FIPS,
+		log:                log,
+	}
+}
+
+// WithDefaults returns a copy of the generator, which uses the given defaults.
+// FIPS mode can't be turned off by the defaults.
+func (g InMemoryGenerator) WithDefaults(defaults credsgen.Defaults) credsgen.Generator {
+	defaults.FIPS = defaults.FIPS || g.FIPS
+	return NewInMemoryGeneratorWithDefaults(g.log, defaults)
+}
+
+// validateKeyRequest checks in FIPS mode that the generated certificate keys
+// are approved
+func (g InMemoryGenerator) validateKeyRequest() error {
+	if !g.FIPS {
+		return nil
+	}
+	return credsgen.ValidateFIPSKeyRequest(g.Algorithm, g.Bits)
+}
+
+// rsaBits returns the key size for RSA and SSH keys, which are always RSA
+func (g InMemoryGenerator) rsaBits() int {
+	if g.Algorithm != "rsa" || g.Bits == 0 {
+		return credsgen.DefaultKeySize
+	}
+	return g.Bits
 }
@@ -10,9 +10,15 @@ func (g InMemoryGenerator) GeneratePassword(name string, request credsgen.Passwo
 	g.log.Debugf("Generating password %s", name)
 
 	length := request.Length
+	if length == 0 {
+		length = g.PasswordLength
+	}
 	if length == 0 {
 		length = credsgen.DefaultPasswordLength
 	}
 
+	if g.PasswordCharacters != "" {
+		return uniuri.NewLenChars(length, []byte(g.PasswordCharacters))
+	}
 	return uniuri.NewLen(length)
 }
@@ -31,5 +31,26 @@ var _ = Describe("InMemoryGenerator", func() {
 
 			Expect(len(password)).To(Equal(10))
 		})
+
+		Context("with defaults", func() {
+			BeforeEach(func() {
+				defaults := credsgen.NewDefaults()
+				defaults.PasswordLength = 20
+				defaults.PasswordCharacters = "ab"
+				generator = generator.(credsgen.DefaultsGenerator).WithDefaults(defaults)
+			})
+
+			It("uses the default length and characters", func() {
+				password := generator.GeneratePassword("foo", credsgen.PasswordGenerationRequest{})
+
+				Expect(password).To(MatchRegexp("^[ab]{20}$"))
+			})
+
+			It("prefers the length of the request", func() {
+				password := generator.GeneratePassword("foo", credsgen.PasswordGenerationRequest{Length: 10})
+
+				Expect(password).To(MatchRegexp("^[ab]{10}$"))
+			})
+		})
 	})
 })
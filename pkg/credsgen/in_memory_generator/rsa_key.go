@@ -15,7 +15,7 @@ func (g InMemoryGenerator) GenerateRSAKey(name string) (credsgen.RSAKey, error)
 	g.log.Debugf("Generating RSA key %s", name)
 
 	// generate private key
-	private, err := rsa.GenerateKey(rand.Reader, g.Bits)
+	private, err := rsa.GenerateKey(rand.Reader, g.rsaBits())
 	if err != nil {
 		return credsgen.RSAKey{}, errors.Wrapf(err, "Generating private key failed for secret name %s", name)
 	}
@@ -16,7 +16,7 @@ func (g InMemoryGenerator) GenerateSSHKey(name string) (credsgen.SSHKey, error)
 	g.log.Debugf("Generating SSH key %s", name)
 
 	// generate private key
-	private, err := rsa.GenerateKey(rand.Reader, g.Bits)
+	private, err := rsa.GenerateKey(rand.Reader, g.rsaBits())
 	if err != nil {
 		return credsgen.SSHKey{}, errors.Wrapf(err, "Generating ssh key failed for secret %s", name)
 	}
@@ -32,10 +32,16 @@ func (g InMemoryGenerator) GenerateSSHKey(name string) (credsgen.SSHKey, error)
 		return credsgen.SSHKey{}, err
 	}
 
+	// MD5 is not FIPS approved
+	fingerprint := ssh.FingerprintLegacyMD5(public)
+	if g.FIPS {
+		fingerprint = ssh.FingerprintSHA256(public)
+	}
+
 	key := credsgen.SSHKey{
 		PrivateKey:  privatePEM,
 		PublicKey:   ssh.MarshalAuthorizedKey(public),
-		Fingerprint: ssh.FingerprintLegacyMD5(public),
+		Fingerprint: fingerprint,
 	}
 	return key, nil
 }
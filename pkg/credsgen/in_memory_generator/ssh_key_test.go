@@ -28,5 +28,13 @@ var _ = Describe("InMemoryGenerator", func() {
 			Expect(key.PublicKey).To(MatchRegexp("ssh-rsa\\s.+"))
 			Expect(key.Fingerprint).To(MatchRegexp("([0-9a-f]{2}:){15}[0-9a-f]{2}"))
 		})
+
+		It("uses SHA256 fingerprints in FIPS mode", func() {
+			generator.(*inmemorygenerator.InMemoryGenerator).FIPS = true
+			key, err := generator.GenerateSSHKey("foo")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key.Fingerprint).To(MatchRegexp("^SHA256:[A-Za-z0-9+/]{43}$"))
+		})
 	})
 })
@@ -0,0 +1,66 @@
+package vaultgenerator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+)
+
+// GenerateCertificate issues a certificate with the PKI secrets engine of
+// Vault, signed by its CA. The key algorithm and size are set by the PKI
+// role.
+func (g VaultGenerator) GenerateCertificate(name string, request credsgen.CertificateGenerationRequest) (credsgen.Certificate, error) {
+	g.log.Debugf("Issuing certificate %s with vault", name)
+
+	if request.IsCA {
+		return credsgen.Certificate{}, errors.Errorf("generating CA %s failed: the vault generator doesn't generate CAs, certificates are signed by the CA of the PKI secrets engine", name)
+	}
+	if len(request.CA.Certificate) > 0 {
+		return credsgen.Certificate{}, errors.Errorf("generating certificate %s failed: the vault generator signs with the CA of the PKI secrets engine, not with a referenced CA", name)
+	}
+
+	body := map[string]interface{}{
+		"common_name": request.CommonName,
+		"ttl":         fmt.Sprintf("%dh", g.defaults.CertificateExpiry*24),
+		"format":      "pem",
+	}
+	if len(request.AlternativeNames) > 0 {
+		body["alt_names"] = strings.Join(request.AlternativeNames, ",")
+	}
+
+	data, err := g.write(fmt.Sprintf("%s/issue/%s", g.config.PKIPath, g.config.PKIRole), body)
+	if err != nil {
+		return credsgen.Certificate{}, errors.Wrapf(err, "issuing certificate %s failed", name)
+	}
+
+	cert := credsgen.Certificate{}
+	for key, value := range map[string]*[]byte{"certificate": &cert.Certificate, "private_key": &cert.PrivateKey, "issuing_ca": &cert.IssuingCA} {
+		s, err := stringData(data, key)
+		if err != nil {
+			return credsgen.Certificate{}, errors.Wrapf(err, "issuing certificate %s failed", name)
+		}
+		*value = []byte(s)
+	}
+
+	if g.defaults.FIPS {
+		if err := credsgen.ValidateFIPSCertificate(cert.Certificate); err != nil {
+			return credsgen.Certificate{}, errors.Wrapf(err, "certificate %s issued by vault", name)
+		}
+	}
+	return cert, nil
+}
+
+// SignsWithOwnCA returns true, certificates are always signed by the CA of
+// the PKI secrets engine
+func (g VaultGenerator) SignsWithOwnCA() bool {
+	return true
+}
+
+// GenerateCertificateSigningRequest is not supported by Vault, as the private
+// key of the request would be generated by the operator
+func (g VaultGenerator) GenerateCertificateSigningRequest(request credsgen.CertificateGenerationRequest) ([]byte, []byte, error) {
+	return nil, nil, errors.New("the vault generator doesn't generate certificate signing requests for the cluster signer")
+}
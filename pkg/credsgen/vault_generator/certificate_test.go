@@ -0,0 +1,129 @@
+package vaultgenerator_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	vaultgenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/vault_generator"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("VaultGenerator", func() {
+	var (
+		generator credsgen.Generator
+		vault     *fakeVault
+		request   credsgen.CertificateGenerationRequest
+	)
+
+	BeforeEach(func() {
+		vault = newFakeVault()
+		vault.Data = func(string) map[string]interface{} {
+			return map[string]interface{}{
+				"certificate": "the-certificate",
+				"private_key": "the-private-key",
+				"issuing_ca":  "the-ca",
+			}
+		}
+
+		_, log := helper.NewTestLogger()
+		generator = vaultgenerator.NewVaultGenerator(log, vaultgenerator.Config{
+			Address: vault.URL + "/",
+			Token:   "s.token",
+			PKIPath: "pki_int",
+			PKIRole: "quarks",
+		}, credsgen.NewDefaults(), vault.Client())
+
+		request = credsgen.CertificateGenerationRequest{
+			CommonName:       "example.com",
+			AlternativeNames: []string{"foo.com", "bar.com"},
+		}
+	})
+
+	AfterEach(func() {
+		vault.Close()
+	})
+
+	Describe("GenerateCertificate", func() {
+		It("issues the certificate with the PKI role", func() {
+			_, err := generator.GenerateCertificate("foo", request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(vault.Requests).To(HaveLen(1))
+			Expect(vault.Requests[0].Path).To(Equal("/v1/pki_int/issue/quarks"))
+			Expect(vault.Requests[0].Token).To(Equal("s.token"))
+			Expect(vault.Requests[0].Body).To(Equal(map[string]interface{}{
+				"common_name": "example.com",
+				"alt_names":   "foo.com,bar.com",
+				"ttl":         "8760h",
+				"format":      "pem",
+			}))
+		})
+
+		It("returns the certificate, key and issuing CA", func() {
+			cert, err := generator.GenerateCertificate("foo", request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(cert.Certificate)).To(Equal("the-certificate"))
+			Expect(string(cert.PrivateKey)).To(Equal("the-private-key"))
+			Expect(string(cert.IssuingCA)).To(Equal("the-ca"))
+		})
+
+		It("uses the certificate expiry of the defaults", func() {
+			defaults := credsgen.NewDefaults()
+			defaults.CertificateExpiry = 10
+			generator = generator.(credsgen.DefaultsGenerator).WithDefaults(defaults)
+
+			_, err := generator.GenerateCertificate("foo", request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vault.Requests[0].Body).To(HaveKeyWithValue("ttl", "240h"))
+		})
+
+		It("returns the vault errors", func() {
+			vault.Status = 403
+
+			_, err := generator.GenerateCertificate("foo", request)
+			Expect(err).To(MatchError(ContainSubstring("failed with status 403: permission denied")))
+		})
+
+		It("returns an error if the response is incomplete", func() {
+			vault.Data = func(string) map[string]interface{} {
+				return map[string]interface{}{"certificate": "the-certificate"}
+			}
+
+			_, err := generator.GenerateCertificate("foo", request)
+			Expect(err).To(MatchError(ContainSubstring("vault response has no")))
+		})
+
+		It("doesn't generate CAs", func() {
+			request.IsCA = true
+
+			_, err := generator.GenerateCertificate("foo", request)
+			Expect(err).To(MatchError(ContainSubstring("doesn't generate CAs")))
+			Expect(vault.Requests).To(BeEmpty())
+		})
+
+		It("doesn't sign with a referenced CA", func() {
+			request.CA = credsgen.Certificate{Certificate: []byte("ca"), PrivateKey: []byte("key")}
+
+			_, err := generator.GenerateCertificate("foo", request)
+			Expect(err).To(MatchError(ContainSubstring("not with a referenced CA")))
+			Expect(vault.Requests).To(BeEmpty())
+		})
+
+		It("signs with its own CA", func() {
+			Expect(credsgen.SignsWithOwnCA(generator)).To(BeTrue())
+		})
+
+		Context("in FIPS mode", func() {
+			It("rejects certificates which aren't approved", func() {
+				defaults := credsgen.NewDefaults()
+				defaults.FIPS = true
+				generator = generator.(credsgen.DefaultsGenerator).WithDefaults(defaults)
+
+				_, err := generator.GenerateCertificate("foo", request)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
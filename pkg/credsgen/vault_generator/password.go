@@ -0,0 +1,70 @@
+package vaultgenerator
+
+import (
+	"encoding/base64"
+
+	"github.com/dchest/uniuri"
+	"github.com/pkg/errors"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+)
+
+// GeneratePassword generates a random password from the random bytes of
+// Vault. It returns an empty password, if Vault fails.
+func (g VaultGenerator) GeneratePassword(name string, request credsgen.PasswordGenerationRequest) string {
+	g.log.Debugf("Generating password %s with vault", name)
+
+	length := request.Length
+	if length == 0 {
+		length = g.defaults.PasswordLength
+	}
+	if length == 0 {
+		length = credsgen.DefaultPasswordLength
+	}
+	chars := uniuri.StdChars
+	if g.defaults.PasswordCharacters != "" {
+		chars = []byte(g.defaults.PasswordCharacters)
+	}
+
+	password, err := g.password(length, chars)
+	if err != nil {
+		g.log.Errorf("Generating password %s with vault failed: %v", name, err)
+		return ""
+	}
+	return password
+}
+
+// password maps random bytes to the characters. Bytes above the largest
+// multiple of the number of characters are skipped, to avoid a bias.
+func (g VaultGenerator) password(length int, chars []byte) (string, error) {
+	maxByte := 255 - (256 % len(chars))
+	password := make([]byte, 0, length)
+	for len(password) < length {
+		data, err := g.write("sys/tools/random", map[string]interface{}{
+			"bytes":  length * 2,
+			"format": "base64",
+		})
+		if err != nil {
+			return "", err
+		}
+		encoded, err := stringData(data, "random_bytes")
+		if err != nil {
+			return "", err
+		}
+		random, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", errors.Wrap(err, "could not decode vault random bytes")
+		}
+
+		for _, b := range random {
+			if int(b) > maxByte {
+				continue
+			}
+			password = append(password, chars[int(b)%len(chars)])
+			if len(password) == length {
+				break
+			}
+		}
+	}
+	return string(password), nil
+}
@@ -0,0 +1,115 @@
+package vaultgenerator_test
+
+import (
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	vaultgenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/vault_generator"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("VaultGenerator", func() {
+	var (
+		generator credsgen.Generator
+		vault     *fakeVault
+		random    []byte
+	)
+
+	BeforeEach(func() {
+		random = make([]byte, 512)
+		for i := range random {
+			random[i] = byte(i)
+		}
+
+		vault = newFakeVault()
+		vault.Data = func(string) map[string]interface{} {
+			return map[string]interface{}{"random_bytes": base64.StdEncoding.EncodeToString(random)}
+		}
+
+		_, log := helper.NewTestLogger()
+		generator = vaultgenerator.NewVaultGenerator(log, vaultgenerator.Config{
+			Address: vault.URL,
+			Token:   "s.token",
+			PKIRole: "quarks",
+		}, credsgen.NewDefaults(), vault.Client())
+	})
+
+	AfterEach(func() {
+		vault.Close()
+	})
+
+	Describe("GeneratePassword", func() {
+		It("requests random bytes from vault", func() {
+			generator.GeneratePassword("foo", credsgen.PasswordGenerationRequest{})
+
+			Expect(vault.Requests).To(HaveLen(1))
+			Expect(vault.Requests[0].Path).To(Equal("/v1/sys/tools/random"))
+			Expect(vault.Requests[0].Token).To(Equal("s.token"))
+			Expect(vault.Requests[0].Body).To(HaveKeyWithValue("format", "base64"))
+		})
+
+		It("has a default length", func() {
+			password := generator.GeneratePassword("foo", credsgen.PasswordGenerationRequest{})
+
+			Expect(len(password)).To(Equal(credsgen.DefaultPasswordLength))
+			Expect(password).To(MatchRegexp("^[a-zA-Z0-9]+$"))
+		})
+
+		It("considers custom lengths", func() {
+			password := generator.GeneratePassword("foo", credsgen.PasswordGenerationRequest{Length: 10})
+
+			Expect(len(password)).To(Equal(10))
+		})
+
+		It("skips biased bytes", func() {
+			random = []byte{255, 0, 254, 1}
+
+			password := generator.GeneratePassword("foo", credsgen.PasswordGenerationRequest{Length: 2})
+
+			Expect(password).To(Equal("AB"))
+		})
+
+		It("returns an empty password if vault fails", func() {
+			vault.Status = 403
+
+			password := generator.GeneratePassword("foo", credsgen.PasswordGenerationRequest{})
+
+			Expect(password).To(BeEmpty())
+		})
+
+		Context("with defaults", func() {
+			BeforeEach(func() {
+				defaults := credsgen.NewDefaults()
+				defaults.PasswordLength = 20
+				defaults.PasswordCharacters = "ab"
+				generator = generator.(credsgen.DefaultsGenerator).WithDefaults(defaults)
+			})
+
+			It("uses the length and characters", func() {
+				password := generator.GeneratePassword("foo", credsgen.PasswordGenerationRequest{})
+
+				Expect(password).To(MatchRegexp("^[ab]{20}$"))
+			})
+		})
+	})
+
+	Describe("unsupported secrets", func() {
+		It("doesn't generate SSH keys", func() {
+			_, err := generator.GenerateSSHKey("foo")
+			Expect(err).To(MatchError(ContainSubstring("doesn't support SSH keys")))
+		})
+
+		It("doesn't generate RSA keys", func() {
+			_, err := generator.GenerateRSAKey("foo")
+			Expect(err).To(MatchError(ContainSubstring("doesn't support RSA keys")))
+		})
+
+		It("doesn't generate certificate signing requests", func() {
+			_, _, err := generator.GenerateCertificateSigningRequest(credsgen.CertificateGenerationRequest{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
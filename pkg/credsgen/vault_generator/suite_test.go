@@ -0,0 +1,54 @@
+package vaultgenerator_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestVaultGenerator(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "VaultGenerator Suite")
+}
+
+// vaultRequest is a request received by the vault stand-in
+type vaultRequest struct {
+	Path  string
+	Token string
+	Body  map[string]interface{}
+}
+
+// fakeVault is a local stand-in for the Vault API, which answers every
+// request with the status and data
+type fakeVault struct {
+	*httptest.Server
+
+	Requests []vaultRequest
+	Status   int
+	Data     func(path string) map[string]interface{}
+}
+
+func newFakeVault() *fakeVault {
+	v := &fakeVault{Status: http.StatusOK}
+	v.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer GinkgoRecover()
+
+		req := vaultRequest{Path: r.URL.Path, Token: r.Header.Get("X-Vault-Token")}
+		Expect(r.Method).To(Equal(http.MethodPost))
+		Expect(json.NewDecoder(r.Body).Decode(&req.Body)).To(Succeed())
+		v.Requests = append(v.Requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(v.Status)
+		if v.Status != http.StatusOK {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{"permission denied"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": v.Data(r.URL.Path)})
+	}))
+	return v
+}
@@ -0,0 +1,108 @@
+package vaultgenerator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+)
+
+// Config configures the connection to Vault
+type Config struct {
+	// Address of the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Token to authenticate with
+	Token string
+	// PKIPath is the mount path of the PKI secrets engine
+	PKIPath string
+	// PKIRole is the role of the PKI secrets engine, certificates are
+	// issued for
+	PKIRole string
+}
+
+// VaultGenerator represents a secret generator, which lets Vault generate
+// passwords and certificates, so they are never generated by the operator
+type VaultGenerator struct {
+	config   Config
+	defaults credsgen.Defaults
+	client   *http.Client
+
+	log *zap.SugaredLogger
+}
+
+// NewVaultGenerator creates a VaultGenerator
+func NewVaultGenerator(log *zap.SugaredLogger, config Config, defaults credsgen.Defaults, client *http.Client) *VaultGenerator {
+	if config.PKIPath == "" {
+		config.PKIPath = "pki"
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+	return &VaultGenerator{config: config, defaults: defaults, client: client, log: log}
+}
+
+// WithDefaults returns a copy of the generator, which uses the given defaults.
+// FIPS mode can't be turned off by the defaults.
+func (g VaultGenerator) WithDefaults(defaults credsgen.Defaults) credsgen.Generator {
+	defaults.FIPS = defaults.FIPS || g.defaults.FIPS
+	g.defaults = defaults
+	return &g
+}
+
+// GenerateSSHKey is not supported by Vault
+func (g VaultGenerator) GenerateSSHKey(name string) (credsgen.SSHKey, error) {
+	return credsgen.SSHKey{}, errors.Errorf("generating SSH key %s failed: the vault generator doesn't support SSH keys", name)
+}
+
+// GenerateRSAKey is not supported by Vault
+func (g VaultGenerator) GenerateRSAKey(name string) (credsgen.RSAKey, error) {
+	return credsgen.RSAKey{}, errors.Errorf("generating RSA key %s failed: the vault generator doesn't support RSA keys", name)
+}
+
+// write posts the body to the Vault API path and returns the data of the
+// response
+func (g VaultGenerator) write(path string, body interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal vault request")
+	}
+
+	url := fmt.Sprintf("%s/v1/%s", g.config.Address, path)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create vault request to '%s'", url)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", g.config.Token)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "vault request to '%s' failed", url)
+	}
+	defer resp.Body.Close()
+
+	result := struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []string               `json:"errors"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrapf(err, "could not decode vault response from '%s' with status %d", url, resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Errorf("vault request to '%s' failed with status %d: %s", url, resp.StatusCode, strings.Join(result.Errors, "; "))
+	}
+	return result.Data, nil
+}
+
+// stringData returns the string value of the key in the response data
+func stringData(data map[string]interface{}, key string) (string, error) {
+	value, ok := data[key].(string)
+	if !ok {
+		return "", errors.Errorf("vault response has no '%s'", key)
+	}
+	return value, nil
+}
@@ -1,8 +1,7 @@
 package template
 
 import (
-	"fmt"
-
+	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/engine"
@@ -17,26 +16,33 @@ func New() Template {
 }
 
 // ExecuteMap renders the templates with the passed in values
-func (h Template) ExecuteMap(templates map[string]string, values map[string]interface{}) map[string]string {
+func (h Template) ExecuteMap(templates map[string]string, values map[string]interface{}) (map[string]string, error) {
 	rendered := make(map[string]string, len(templates))
 	vals := map[string]interface{}{"Values": values}
 	for k, template := range templates {
-		rendered[k] = h.render(template, vals)
+		out, err := h.render(template, vals)
+		if err != nil {
+			return map[string]string{}, errors.Wrapf(err, "rendering template '%s'", k)
+		}
+		rendered[k] = out
 	}
-	return rendered
+	return rendered, nil
 }
 
 // render renders the template interpolating the supplied values
-func (h Template) render(template string, values map[string]interface{}) string {
-	out := h.renderFiles([]*chart.File{
+func (h Template) render(template string, values map[string]interface{}) (string, error) {
+	out, err := h.renderFiles([]*chart.File{
 		{Name: "templates", Data: []byte(template)},
 	}, map[string]interface{}{}, values)
+	if err != nil {
+		return "", err
+	}
 
-	return out["templates"]
+	return out["templates"], nil
 }
 
 // renderFiles uses helms chartutil to render the passed in chart.Files
-func (h Template) renderFiles(files []*chart.File, values map[string]interface{}, defaults map[string]interface{}) map[string]string {
+func (h Template) renderFiles(files []*chart.File, values map[string]interface{}, defaults map[string]interface{}) (map[string]string, error) {
 	c := &chart.Chart{
 		Metadata: &chart.Metadata{
 			Name:    "",
@@ -48,12 +54,8 @@ func (h Template) renderFiles(files []*chart.File, values map[string]interface{}
 
 	v, err := chartutil.CoalesceValues(c, defaults)
 	if err != nil {
-		fmt.Println(err)
-	}
-	out, err := engine.Render(c, v)
-	if err != nil {
-		fmt.Println(err)
+		return nil, errors.Wrap(err, "coalescing values")
 	}
 
-	return out
+	return engine.Render(c, v)
 }
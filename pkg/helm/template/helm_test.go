@@ -24,7 +24,9 @@ var _ = Describe("Template", func() {
 	})
 
 	act := func(templates map[string]string) map[string]string {
-		return engine.ExecuteMap(templates, defaults)
+		out, err := engine.ExecuteMap(templates, defaults)
+		Expect(err).ToNot(HaveOccurred())
+		return out
 	}
 
 	Describe("RenderMap", func() {
@@ -59,5 +61,21 @@ var _ = Describe("Template", func() {
 			})
 			Expect(out).To(HaveKeyWithValue("test", ""))
 		})
+
+		It("returns an error for invalid templates", func() {
+			_, err := engine.ExecuteMap(map[string]string{
+				"test": "{{ .Values.outer ",
+			}, defaults)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("rendering template 'test'"))
+		})
+
+		It("returns an error for failing template functions", func() {
+			_, err := engine.ExecuteMap(map[string]string{
+				"test": `{{ required "missing value" .Values.nope }}`,
+			}, defaults)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("missing value"))
+		})
 	})
 })
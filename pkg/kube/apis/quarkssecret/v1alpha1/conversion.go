@@ -0,0 +1,5 @@
+package v1alpha1
+
+// Hub marks v1alpha1 as the version all other QuarksSecret versions
+// are converted to and from. It's also the storage version.
+func (*QuarksSecret) Hub() {}
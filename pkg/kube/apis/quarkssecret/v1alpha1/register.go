@@ -3,13 +3,12 @@ package v1alpha1
 import (
 	"fmt"
 
-	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	apis "code.cloudfoundry.org/quarks-secret/pkg/kube/apis"
-	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
 )
 
 // This file looks almost the same for all controllers
@@ -20,6 +19,26 @@ const (
 	QuarksSecretResourceKind = "QuarksSecret"
 	// QuarksSecretResourcePlural is the plural name of QuarksSecret
 	QuarksSecretResourcePlural = "quarkssecrets"
+
+	// QuarksSecretRotationPolicyResourceKind is the kind name of QuarksSecretRotationPolicy
+	QuarksSecretRotationPolicyResourceKind = "QuarksSecretRotationPolicy"
+	// QuarksSecretRotationPolicyResourcePlural is the plural name of QuarksSecretRotationPolicy
+	QuarksSecretRotationPolicyResourcePlural = "quarkssecretrotationpolicies"
+
+	// QuarksSecretCopyPolicyResourceKind is the kind name of QuarksSecretCopyPolicy
+	QuarksSecretCopyPolicyResourceKind = "QuarksSecretCopyPolicy"
+	// QuarksSecretCopyPolicyResourcePlural is the plural name of QuarksSecretCopyPolicy
+	QuarksSecretCopyPolicyResourcePlural = "quarkssecretcopypolicies"
+
+	// QuarksSecretNotificationConfigResourceKind is the kind name of QuarksSecretNotificationConfig
+	QuarksSecretNotificationConfigResourceKind = "QuarksSecretNotificationConfig"
+	// QuarksSecretNotificationConfigResourcePlural is the plural name of QuarksSecretNotificationConfig
+	QuarksSecretNotificationConfigResourcePlural = "quarkssecretnotificationconfigs"
+
+	// QuarksSecretDefaultsResourceKind is the kind name of QuarksSecretDefaults
+	QuarksSecretDefaultsResourceKind = "QuarksSecretDefaults"
+	// QuarksSecretDefaultsResourcePlural is the plural name of QuarksSecretDefaults
+	QuarksSecretDefaultsResourcePlural = "quarkssecretdefaults"
 )
 
 var (
@@ -32,93 +51,6 @@ var (
 	// QuarksSecretResourceShortNames is the short names of QuarksSecret
 	QuarksSecretResourceShortNames = []string{"qsec", "qsecs"}
 
-	// QuarksSecretValidation is the validation schema for QuarksSecret
-	QuarksSecretValidation = extv1.CustomResourceValidation{
-		OpenAPIV3Schema: &extv1.JSONSchemaProps{
-			Type: "object",
-			Properties: map[string]extv1.JSONSchemaProps{
-				"spec": {
-					Type: "object",
-					Properties: map[string]extv1.JSONSchemaProps{
-						"secretName": {
-							Type:        "string",
-							MinLength:   pointers.Int64(1),
-							Description: "The name of the generated secret",
-						},
-						"type": {
-							Type:        "string",
-							MinLength:   pointers.Int64(1),
-							Description: "What kind of secret to generate: password, certificate, ssh, rsa",
-						},
-						"request": {
-							Type:                   "object",
-							XPreserveUnknownFields: pointers.Bool(true),
-							Properties: map[string]extv1.JSONSchemaProps{
-								"templatedConfig": {
-									Type:        "object",
-									Description: "TemplatedConfig renders the template map into the generated secret",
-									Properties: map[string]extv1.JSONSchemaProps{
-										"type": {
-											Type:        "string",
-											Description: "Type of template being used (helm)",
-										},
-										"templates": {
-											Type:                   "object",
-											XPreserveUnknownFields: pointers.Bool(true),
-											Description:            "Template values to interpolate in the generated secret",
-										},
-										"values": {
-											Type:                   "object",
-											XPreserveUnknownFields: pointers.Bool(true),
-											Description:            "Template values to interpolate in the generated secret",
-										},
-									},
-								},
-							},
-						},
-						"copies": {
-							Type:        "array",
-							Description: "A list of namespaced names where to copy generated secrets",
-							Items: &extv1.JSONSchemaPropsOrArray{
-								Schema: &extv1.JSONSchemaProps{
-									Type:                   "object",
-									XPreserveUnknownFields: pointers.Bool(true),
-								},
-							},
-						},
-						"secretLabels": {
-							Type:                   "object",
-							XPreserveUnknownFields: pointers.Bool(true),
-						},
-						"secretAnnotations": {
-							Type:                   "object",
-							XPreserveUnknownFields: pointers.Bool(true),
-						},
-					},
-					Required: []string{
-						"secretName",
-						"type",
-					},
-				},
-				"status": {
-					Type: "object",
-					Properties: map[string]extv1.JSONSchemaProps{
-						"generated": {
-							Type: "boolean",
-						},
-						"copied": {
-							Type: "boolean",
-						},
-						"lastReconcile": {
-							Type:     "string",
-							Nullable: true,
-						},
-					},
-				},
-			},
-		},
-	}
-
 	// QuarksSecretAdditionalPrinterColumns are used by `kubectl get`
 	QuarksSecretAdditionalPrinterColumns = []extv1.CustomResourceColumnDefinition{
 		{
@@ -150,6 +82,97 @@ var (
 	// QuarksSecretResourceName is the resource name of QuarksSecret
 	QuarksSecretResourceName = fmt.Sprintf("%s.%s", QuarksSecretResourcePlural, apis.GroupName)
 
+	// QuarksSecretRotationPolicyResourceShortNames is the short names of QuarksSecretRotationPolicy
+	QuarksSecretRotationPolicyResourceShortNames = []string{"qsecrp"}
+
+	// QuarksSecretRotationPolicyAdditionalPrinterColumns are used by `kubectl get`
+	QuarksSecretRotationPolicyAdditionalPrinterColumns = []extv1.CustomResourceColumnDefinition{
+		{
+			Name:     "schedule",
+			Type:     "string",
+			JSONPath: ".spec.schedule",
+		},
+		{
+			Name:     "last",
+			Type:     "date",
+			JSONPath: ".status.lastScheduleTime",
+		},
+		{
+			Name:     "next",
+			Type:     "date",
+			JSONPath: ".status.nextScheduleTime",
+		},
+		{
+			Name:     "age",
+			Type:     "date",
+			JSONPath: ".metadata.creationTimestamp",
+		},
+	}
+	// QuarksSecretRotationPolicyResourceName is the resource name of QuarksSecretRotationPolicy
+	QuarksSecretRotationPolicyResourceName = fmt.Sprintf("%s.%s", QuarksSecretRotationPolicyResourcePlural, apis.GroupName)
+
+	// QuarksSecretCopyPolicyResourceShortNames is the short names of QuarksSecretCopyPolicy
+	QuarksSecretCopyPolicyResourceShortNames = []string{"qseccp"}
+
+	// QuarksSecretCopyPolicyAdditionalPrinterColumns are used by `kubectl get`
+	QuarksSecretCopyPolicyAdditionalPrinterColumns = []extv1.CustomResourceColumnDefinition{
+		{
+			Name:     "sources",
+			Type:     "string",
+			JSONPath: ".spec.sourceNamespaces",
+		},
+		{
+			Name:     "age",
+			Type:     "date",
+			JSONPath: ".metadata.creationTimestamp",
+		},
+	}
+	// QuarksSecretCopyPolicyResourceName is the resource name of QuarksSecretCopyPolicy
+	QuarksSecretCopyPolicyResourceName = fmt.Sprintf("%s.%s", QuarksSecretCopyPolicyResourcePlural, apis.GroupName)
+
+	// QuarksSecretNotificationConfigResourceShortNames is the short names of QuarksSecretNotificationConfig
+	QuarksSecretNotificationConfigResourceShortNames = []string{"qsecnc"}
+
+	// QuarksSecretNotificationConfigAdditionalPrinterColumns are used by `kubectl get`
+	QuarksSecretNotificationConfigAdditionalPrinterColumns = []extv1.CustomResourceColumnDefinition{
+		{
+			Name:     "events",
+			Type:     "string",
+			JSONPath: ".spec.events",
+		},
+		{
+			Name:     "age",
+			Type:     "date",
+			JSONPath: ".metadata.creationTimestamp",
+		},
+	}
+	// QuarksSecretNotificationConfigResourceName is the resource name of QuarksSecretNotificationConfig
+	QuarksSecretNotificationConfigResourceName = fmt.Sprintf("%s.%s", QuarksSecretNotificationConfigResourcePlural, apis.GroupName)
+
+	// QuarksSecretDefaultsResourceShortNames is the short names of QuarksSecretDefaults
+	QuarksSecretDefaultsResourceShortNames = []string{"qsecdef"}
+
+	// QuarksSecretDefaultsAdditionalPrinterColumns are used by `kubectl get`
+	QuarksSecretDefaultsAdditionalPrinterColumns = []extv1.CustomResourceColumnDefinition{
+		{
+			Name:     "algorithm",
+			Type:     "string",
+			JSONPath: ".spec.keyAlgorithm",
+		},
+		{
+			Name:     "size",
+			Type:     "integer",
+			JSONPath: ".spec.keySize",
+		},
+		{
+			Name:     "age",
+			Type:     "date",
+			JSONPath: ".metadata.creationTimestamp",
+		},
+	}
+	// QuarksSecretDefaultsResourceName is the resource name of QuarksSecretDefaults
+	QuarksSecretDefaultsResourceName = fmt.Sprintf("%s.%s", QuarksSecretDefaultsResourcePlural, apis.GroupName)
+
 	// SchemeGroupVersion is group version used to register these objects
 	SchemeGroupVersion = schema.GroupVersion{Group: apis.GroupName, Version: "v1alpha1"}
 )
@@ -169,6 +192,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&QuarksSecret{},
 		&QuarksSecretList{},
+		&QuarksSecretRotationPolicy{},
+		&QuarksSecretRotationPolicyList{},
+		&QuarksSecretCopyPolicy{},
+		&QuarksSecretCopyPolicyList{},
+		&QuarksSecretNotificationConfig{},
+		&QuarksSecretNotificationConfigList{},
+		&QuarksSecretDefaults{},
+		&QuarksSecretDefaultsList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
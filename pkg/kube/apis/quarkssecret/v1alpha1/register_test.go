@@ -0,0 +1,399 @@
+package v1alpha1_test
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	certv1 "k8s.io/api/certificates/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/apis/schemagen"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+)
+
+var _ = Describe("Validation schemas", func() {
+	It("are generated from the go types, run 'make gen-crds' if not", func() {
+		schemas, err := schemagen.Schemas(".")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schemas).To(Equal(map[string]*extv1.JSONSchemaProps{
+			"QuarksSecret":                   qsv1a1.QuarksSecretValidation.OpenAPIV3Schema,
+			"QuarksSecretRotationPolicy":     qsv1a1.QuarksSecretRotationPolicyValidation.OpenAPIV3Schema,
+			"QuarksSecretCopyPolicy":         qsv1a1.QuarksSecretCopyPolicyValidation.OpenAPIV3Schema,
+			"QuarksSecretNotificationConfig": qsv1a1.QuarksSecretNotificationConfigValidation.OpenAPIV3Schema,
+			"QuarksSecretDefaults":           qsv1a1.QuarksSecretDefaultsValidation.OpenAPIV3Schema,
+		}))
+	})
+
+	It("allows all secret types", func() {
+		types := []string{
+			qsv1a1.Password,
+			qsv1a1.Certificate,
+			qsv1a1.TLS,
+			qsv1a1.SSHKey,
+			qsv1a1.RSAKey,
+			qsv1a1.BasicAuth,
+			qsv1a1.DockerConfigJSON,
+			qsv1a1.SecretCopy,
+			qsv1a1.TemplatedConfig,
+			qsv1a1.PluginTypePattern,
+		}
+		pattern := qsv1a1.QuarksSecretValidation.OpenAPIV3Schema.Properties["spec"].Properties["type"].Pattern
+		Expect(pattern).To(Equal("^(" + strings.Join(types, "|") + ")$"))
+	})
+})
+
+var _ = Describe("QuarksSecretValidation", func() {
+	var structural *structuralschema.Structural
+
+	BeforeEach(func() {
+		internal := &apiextensions.JSONSchemaProps{}
+		err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(qsv1a1.QuarksSecretValidation.OpenAPIV3Schema, internal, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		structural, err = structuralschema.NewStructural(internal)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("is a structural schema", func() {
+		Expect(structuralschema.ValidateStructural(field.NewPath("openAPIV3Schema"), structural)).To(BeEmpty())
+	})
+
+	Context("when pruning", func() {
+		prune := func(obj interface{}) map[string]interface{} {
+			raw, err := json.Marshal(obj)
+			Expect(err).ToNot(HaveOccurred())
+
+			u := map[string]interface{}{}
+			Expect(json.Unmarshal(raw, &u)).To(Succeed())
+
+			pruning.Prune(u, structural, true)
+			return u
+		}
+
+		It("keeps all fields of the go types", func() {
+			qsec := qsv1a1.QuarksSecret{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+				Spec: qsv1a1.QuarksSecretSpec{
+					Type:       qsv1a1.Certificate,
+					SecretName: "generated",
+					Request: qsv1a1.Request{
+						BasicAuthRequest: qsv1a1.BasicAuthRequest{Username: "user"},
+						CertificateRequest: qsv1a1.CertificateRequest{
+							CommonName:                  "example.com",
+							AlternativeNames:            []string{"foo.example.com"},
+							IsCA:                        true,
+							CARef:                       qsv1a1.SecretReference{Name: "ca", Key: "certificate"},
+							CAKeyRef:                    qsv1a1.SecretReference{Name: "ca", Key: "private_key"},
+							SignerType:                  qsv1a1.ClusterSigner,
+							Usages:                      []certv1.KeyUsage{certv1.UsageServerAuth},
+							ServiceRef:                  []qsv1a1.ServiceReference{{Name: "svc"}},
+							ActivateEKSWorkaroundForSAN: true,
+						},
+						ImageCredentialsRequest: qsv1a1.ImageCredentialsRequest{
+							Username: qsv1a1.SecretReference{Name: "user", Key: "username"},
+							Password: qsv1a1.SecretReference{Name: "pass", Key: "password"},
+							Registry: "registry",
+							Email:    "email",
+						},
+						TemplatedConfigRequest: qsv1a1.TemplatedConfigRequest{
+							Type:      "helm",
+							Templates: map[string]string{"config": "{{ .Values.foo }}"},
+							Values:    map[string]qsv1a1.SecretReference{"foo": {Name: "foo", Key: "bar"}},
+						},
+						PluginRequest: qsv1a1.PluginRequest{
+							Parameters: map[string]string{"length": "32"},
+							Values:     map[string]qsv1a1.SecretReference{"seed": {Name: "seed", Key: "value"}},
+						},
+					},
+					Copies: []qsv1a1.Copy{{
+						Name:        "copy",
+						Namespace:   "other",
+						Cluster:     "workload-1",
+						IncludeKeys: []string{"certificate"},
+						ExcludeKeys: []string{"private_key"},
+						RenameKeys:  map[string]string{"certificate": "ca.crt"},
+						Type:        "Opaque",
+						Labels:      map[string]string{"label": "value"},
+						Annotations: map[string]string{"annotation": "value"},
+					}},
+					CopySelector: &qsv1a1.CopySelector{
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels:      map[string]string{"team": "a"},
+							MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"prod"}}},
+						},
+						Name:       "copy",
+						RenameKeys: map[string]string{"certificate": "ca.crt"},
+					},
+					SecretLabels:      map[string]string{"label": "value"},
+					SecretAnnotations: map[string]string{"annotation": "value"},
+					Rotation: qsv1a1.RotationSpec{
+						Generation:   1,
+						KeepPrevious: true,
+						GracePeriod:  &metav1.Duration{Duration: time.Hour},
+					},
+					HistoryLimit:         5,
+					RollbackTo:           pointers.Int64(3),
+					DeletionPolicy:       qsv1a1.DeletionPolicyOrphan,
+					DriftPolicy:          qsv1a1.DriftPolicyReport,
+					ExistingSecretPolicy: qsv1a1.ExistingSecretPolicyAdopt,
+					PublicOutput:         &qsv1a1.PublicOutput{Name: "foo-public", Namespaces: []string{"default", "other"}},
+					RestartOnChange: &qsv1a1.RestartOnChange{
+						Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+						AutoDiscover: true,
+					},
+					UnusedAfter:  &metav1.Duration{Duration: 24 * time.Hour},
+					GeneratorRef: "vault",
+				},
+				Status: qsv1a1.QuarksSecretStatus{
+					LastReconcile: &metav1.Time{},
+					Generated:     pointers.Bool(true),
+					Copied:        pointers.Bool(true),
+					Copies: []qsv1a1.CopyStatus{{
+						Name:        "copy",
+						Namespace:   "other",
+						Cluster:     "workload-1",
+						State:       qsv1a1.CopyStateSkippedNoPlaceholder,
+						Message:     "no placeholder",
+						ContentHash: "abc",
+					}},
+					PublicOutputs:   []qsv1a1.PublicOutputStatus{{Name: "foo-public", Namespace: "other"}},
+					RestartChecksum: "abc",
+					Consumers:       []qsv1a1.ConsumerStatus{{Kind: "Deployment", Name: "app", Namespace: "other", Secret: "copy"}},
+				},
+			}
+			obj := prune(qsec)
+
+			Expect(obj).To(Equal(prune(obj)))
+			raw, err := json.Marshal(obj)
+			Expect(err).ToNot(HaveOccurred())
+			result := qsv1a1.QuarksSecret{}
+			Expect(json.Unmarshal(raw, &result)).To(Succeed())
+			Expect(result.Spec).To(Equal(qsec.Spec))
+			Expect(result.Status.IsGenerated()).To(BeTrue())
+			Expect(result.Status.IsCopied()).To(BeTrue())
+			Expect(result.Status.Copies).To(Equal(qsec.Status.Copies))
+			Expect(result.Status.PublicOutputs).To(Equal(qsec.Status.PublicOutputs))
+			Expect(result.Status.RestartChecksum).To(Equal(qsec.Status.RestartChecksum))
+			Expect(result.Status.Consumers).To(Equal(qsec.Status.Consumers))
+		})
+
+		It("drops misspelled fields", func() {
+			obj := prune(map[string]interface{}{
+				"spec": map[string]interface{}{
+					"type":       "certificate",
+					"secretName": "generated",
+					"request": map[string]interface{}{
+						"certificate": map[string]interface{}{
+							"commonName":      "example.com",
+							"alternativeName": []string{"foo.example.com"},
+							"CAref":           map[string]interface{}{"name": "ca", "key": "certificate"},
+						},
+					},
+				},
+			})
+
+			Expect(obj["spec"].(map[string]interface{})["request"]).To(Equal(map[string]interface{}{
+				"certificate": map[string]interface{}{
+					"commonName": "example.com",
+				},
+			}))
+		})
+	})
+})
+
+var _ = Describe("QuarksSecretRotationPolicyValidation", func() {
+	var structural *structuralschema.Structural
+
+	BeforeEach(func() {
+		internal := &apiextensions.JSONSchemaProps{}
+		err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(qsv1a1.QuarksSecretRotationPolicyValidation.OpenAPIV3Schema, internal, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		structural, err = structuralschema.NewStructural(internal)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("is a structural schema", func() {
+		Expect(structuralschema.ValidateStructural(field.NewPath("openAPIV3Schema"), structural)).To(BeEmpty())
+	})
+
+	It("keeps all fields of the go types when pruning", func() {
+		policy := qsv1a1.QuarksSecretRotationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			Spec: qsv1a1.QuarksSecretRotationPolicySpec{
+				Schedule: "0 2 1-7 * *",
+				TimeZone: "Europe/Berlin",
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "foo"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"db"}},
+					},
+				},
+				Types: []string{qsv1a1.BasicAuth},
+				MaintenanceWindows: []qsv1a1.MaintenanceWindow{
+					{Days: []string{"Sunday"}, Start: "02:00", End: "04:00"},
+				},
+				MaxConcurrency: 2,
+			},
+			Status: qsv1a1.QuarksSecretRotationPolicyStatus{
+				Pending:    []string{"foo"},
+				InProgress: []string{"bar"},
+			},
+		}
+		raw, err := json.Marshal(policy)
+		Expect(err).ToNot(HaveOccurred())
+		u := map[string]interface{}{}
+		Expect(json.Unmarshal(raw, &u)).To(Succeed())
+
+		pruning.Prune(u, structural, true)
+
+		raw, err = json.Marshal(u)
+		Expect(err).ToNot(HaveOccurred())
+		result := qsv1a1.QuarksSecretRotationPolicy{}
+		Expect(json.Unmarshal(raw, &result)).To(Succeed())
+		Expect(result.Spec).To(Equal(policy.Spec))
+		Expect(result.Status).To(Equal(policy.Status))
+	})
+})
+
+var _ = Describe("QuarksSecretCopyPolicyValidation", func() {
+	var structural *structuralschema.Structural
+
+	BeforeEach(func() {
+		internal := &apiextensions.JSONSchemaProps{}
+		err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(qsv1a1.QuarksSecretCopyPolicyValidation.OpenAPIV3Schema, internal, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		structural, err = structuralschema.NewStructural(internal)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("is a structural schema", func() {
+		Expect(structuralschema.ValidateStructural(field.NewPath("openAPIV3Schema"), structural)).To(BeEmpty())
+	})
+
+	It("keeps all fields of the go types when pruning", func() {
+		policy := qsv1a1.QuarksSecretCopyPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			Spec: qsv1a1.QuarksSecretCopyPolicySpec{
+				SourceNamespaces: []string{"platform"},
+				TargetNamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "a"},
+				},
+			},
+		}
+		raw, err := json.Marshal(policy)
+		Expect(err).ToNot(HaveOccurred())
+		u := map[string]interface{}{}
+		Expect(json.Unmarshal(raw, &u)).To(Succeed())
+
+		pruning.Prune(u, structural, true)
+
+		raw, err = json.Marshal(u)
+		Expect(err).ToNot(HaveOccurred())
+		result := qsv1a1.QuarksSecretCopyPolicy{}
+		Expect(json.Unmarshal(raw, &result)).To(Succeed())
+		Expect(result.Spec).To(Equal(policy.Spec))
+	})
+})
+
+var _ = Describe("QuarksSecretNotificationConfigValidation", func() {
+	var structural *structuralschema.Structural
+
+	BeforeEach(func() {
+		internal := &apiextensions.JSONSchemaProps{}
+		err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(qsv1a1.QuarksSecretNotificationConfigValidation.OpenAPIV3Schema, internal, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		structural, err = structuralschema.NewStructural(internal)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("is a structural schema", func() {
+		Expect(structuralschema.ValidateStructural(field.NewPath("openAPIV3Schema"), structural)).To(BeEmpty())
+	})
+
+	It("keeps all fields of the go types when pruning", func() {
+		config := qsv1a1.QuarksSecretNotificationConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: qsv1a1.QuarksSecretNotificationConfigSpec{
+				Endpoints: []qsv1a1.NotificationEndpoint{{
+					URL:           "https://cmdb.example.com/hooks/quarks",
+					SigningKeyRef: &qsv1a1.SecretReference{Name: "cmdb-hook", Key: "key"},
+					MaxRetries:    pointers.Int(3),
+				}},
+				Events: []qsv1a1.NotificationEvent{qsv1a1.NotificationRotated, qsv1a1.NotificationExpiringSoon},
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "a"},
+				},
+				ExpiringSoonBefore: &metav1.Duration{Duration: 7 * 24 * time.Hour},
+			},
+		}
+		raw, err := json.Marshal(config)
+		Expect(err).ToNot(HaveOccurred())
+		u := map[string]interface{}{}
+		Expect(json.Unmarshal(raw, &u)).To(Succeed())
+
+		pruning.Prune(u, structural, true)
+
+		raw, err = json.Marshal(u)
+		Expect(err).ToNot(HaveOccurred())
+		result := qsv1a1.QuarksSecretNotificationConfig{}
+		Expect(json.Unmarshal(raw, &result)).To(Succeed())
+		Expect(result.Spec).To(Equal(config.Spec))
+	})
+})
+
+var _ = Describe("QuarksSecretDefaultsValidation", func() {
+	var structural *structuralschema.Structural
+
+	BeforeEach(func() {
+		internal := &apiextensions.JSONSchemaProps{}
+		err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(qsv1a1.QuarksSecretDefaultsValidation.OpenAPIV3Schema, internal, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		structural, err = structuralschema.NewStructural(internal)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("is a structural schema", func() {
+		Expect(structuralschema.ValidateStructural(field.NewPath("openAPIV3Schema"), structural)).To(BeEmpty())
+	})
+
+	It("keeps all fields of the go types when pruning", func() {
+		defaults := qsv1a1.QuarksSecretDefaults{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+			Spec: qsv1a1.QuarksSecretDefaultsSpec{
+				KeyAlgorithm:      "ecdsa",
+				KeySize:           384,
+				CertificateExpiry: 90,
+				PasswordPolicy: &qsv1a1.PasswordPolicy{
+					Length:     32,
+					Characters: "abcdef0123456789",
+				},
+			},
+		}
+		raw, err := json.Marshal(defaults)
+		Expect(err).ToNot(HaveOccurred())
+		u := map[string]interface{}{}
+		Expect(json.Unmarshal(raw, &u)).To(Succeed())
+
+		pruning.Prune(u, structural, true)
+
+		raw, err = json.Marshal(u)
+		Expect(err).ToNot(HaveOccurred())
+		result := qsv1a1.QuarksSecretDefaults{}
+		Expect(json.Unmarshal(raw, &result)).To(Succeed())
+		Expect(result.Spec).To(Equal(defaults.Spec))
+	})
+})
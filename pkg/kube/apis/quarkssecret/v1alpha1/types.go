@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"regexp"
 
 	certv1 "k8s.io/api/certificates/v1beta1"
 	corev1 "k8s.io/api/core/v1"
@@ -39,7 +40,14 @@ const (
 	TemplatedConfig  SecretType = "templatedconfig"
 )
 
+// PluginTypePattern matches the custom secret types, which are generated by
+// exec plugins, e.g. example.com/ldap-password
+const PluginTypePattern = `[a-z0-9]([-a-z0-9.]*[a-z0-9])?/[a-z0-9]([-a-z0-9]*[a-z0-9])?`
+
+var pluginTypeRegexp = regexp.MustCompile("^" + PluginTypePattern + "$")
+
 // SignerType defines the type of the certificate signer
+// +kubebuilder:validation:Enum=local;cluster
 type SignerType = string
 
 // Valid values for signer types
@@ -72,98 +80,573 @@ var (
 	// rotation. If set, then creating the config map will trigger secret
 	// rotation.
 	LabelSecretRotationTrigger = fmt.Sprintf("%s/secret-rotation", apis.GroupName)
+	// ClusterKubeconfigKey is the key of the kubeconfig in the secret a
+	// copy to a remote cluster refers to
+	ClusterKubeconfigKey = "kubeconfig"
 	// RotateQSecretListName is the name of the config map entry, which
 	// contains a JSON array of quarks secret names to rotate
 	RotateQSecretListName = "secrets"
+	// RotateQSecretSelectorName is the name of the config map entry, which
+	// contains a label selector for the quarks secrets to rotate
+	RotateQSecretSelectorName = "selector"
+	// RotateQSecretTypesName is the name of the config map entry, which
+	// contains a JSON array of secret types to rotate
+	RotateQSecretTypesName = "types"
+	// RotateQSecretAllName is the name of the config map entry, which
+	// rotates all quarks secrets in the namespace if set to "true"
+	RotateQSecretAllName = "all"
+	// RotateQSecretResultsName is the name of the config map entry, which
+	// the operator writes the rotation result per quarks secret to
+	RotateQSecretResultsName = "results"
+	// AnnotationRotate is set on a quarks secret to trigger secret rotation.
+	// Changing its value regenerates the secret.
+	AnnotationRotate = fmt.Sprintf("%s/rotate", apis.GroupName)
+	// AnnotationRollback is set on a quarks secret to repeat a rollback.
+	// Changing its value restores spec.rollbackTo again.
+	AnnotationRollback = fmt.Sprintf("%s/rollback", apis.GroupName)
+	// AnnotationPreviousExpires is set on a generated secret, which keeps
+	// the previous values of its credentials. The previous values are
+	// removed after this time.
+	AnnotationPreviousExpires = fmt.Sprintf("%s/previous-expires", apis.GroupName)
+	// AnnotationVersion is the version of the generated secret, which is
+	// kept in a history secret
+	AnnotationVersion = fmt.Sprintf("%s/version", apis.GroupName)
+	// AnnotationVersionReason is the reason a version of the generated
+	// secret was created, e.g. rotation or rollback
+	AnnotationVersionReason = fmt.Sprintf("%s/version-reason", apis.GroupName)
+	// AnnotationContentHash is the hash of the data of a generated secret,
+	// which is used to detect changes outside of the operator
+	AnnotationContentHash = fmt.Sprintf("%s/content-hash", apis.GroupName)
+	// AnnotationPublicOutputOf is the annotation key for config maps,
+	// which hold the public parts of a generated secret
+	AnnotationPublicOutputOf = fmt.Sprintf("%s/public-output-of", apis.GroupName)
+	// AnnotationRestartChecksumPrefix is the prefix of the pod template
+	// annotation, which holds the content hash of a generated secret. It is
+	// followed by the secret name.
+	AnnotationRestartChecksumPrefix = fmt.Sprintf("checksum.%s/", apis.GroupName)
+	// Finalizer is set on quarks secrets, so their copies are cleaned up
+	// according to the deletion policy
+	Finalizer = fmt.Sprintf("%s/finalizer", apis.GroupName)
 )
 
 const (
 	// GeneratedSecretKind is the kind of generated secret
 	GeneratedSecretKind = "generated"
+	// HistorySecretKind is the kind of secret, which keeps a version of a
+	// generated secret
+	HistorySecretKind = "history"
+	// PublicOutputKind is the kind of config map, which holds the public
+	// parts of a generated secret
+	PublicOutputKind = "public-output"
 )
 
 // SecretReference specifies a reference to another secret
 type SecretReference struct {
+	// The name of the referenced secret
 	Name string `json:"name"`
-	Key  string `json:"key"`
+	// The key in the referenced secret
+	Key string `json:"key"`
 }
 
 // ServiceReference specifies a reference to a service
 type ServiceReference struct {
+	// The name of the service
 	Name string
 }
 
 // CertificateRequest specifies the details for the certificate generation
 type CertificateRequest struct {
-	CommonName                  string             `json:"commonName"`
-	AlternativeNames            []string           `json:"alternativeNames"`
-	IsCA                        bool               `json:"isCA"`
-	CARef                       SecretReference    `json:"CARef"`
-	CAKeyRef                    SecretReference    `json:"CAKeyRef"`
-	SignerType                  SignerType         `json:"signerType,omitempty"`
-	Usages                      []certv1.KeyUsage  `json:"usages"`
-	ServiceRef                  []ServiceReference `json:"serviceRef"`
-	ActivateEKSWorkaroundForSAN bool               `json:"activateEKSWorkaroundForSAN,omitempty"`
+	// The common name of the certificate
+	CommonName string `json:"commonName"`
+	// Subject alternative names of the certificate
+	// +optional
+	// +nullable
+	AlternativeNames []string `json:"alternativeNames"`
+	// Generate a CA certificate
+	// +optional
+	IsCA bool `json:"isCA"`
+	// Reference to the secret containing the CA certificate
+	// +optional
+	CARef SecretReference `json:"CARef"`
+	// Reference to the secret containing the CA private key
+	// +optional
+	CAKeyRef SecretReference `json:"CAKeyRef"`
+	// Who signs the certificate: local or cluster
+	SignerType SignerType `json:"signerType,omitempty"`
+	// Key usages requested from the cluster signer
+	// +optional
+	// +nullable
+	Usages []certv1.KeyUsage `json:"usages"`
+	// Services whose cluster DNS names are added to the certificate
+	// +optional
+	// +nullable
+	ServiceRef []ServiceReference `json:"serviceRef"`
+	// Pass alternative names as IP addresses to the EKS cluster signer
+	ActivateEKSWorkaroundForSAN bool `json:"activateEKSWorkaroundForSAN,omitempty"`
 }
 
 // BasicAuthRequest specifies the details for generating a basic-auth secret
 type BasicAuthRequest struct {
+	// The username, a random one is generated if empty
+	// +optional
 	Username string `json:"username"`
 }
 
 // ImageCredentialsRequest specifies the details for the image credentials
 type ImageCredentialsRequest struct {
+	// Reference to the secret containing the username
+	// +optional
 	Username SecretReference `json:"username"`
+	// Reference to the secret containing the password
+	// +optional
 	Password SecretReference `json:"password"`
-	Registry string          `json:"registry"`
-	Email    string          `json:"email"`
+	// The docker registry
+	Registry string `json:"registry"`
+	// The email of the registry user
+	// +optional
+	Email string `json:"email"`
 }
 
 // TemplatedConfigRequest defines the type of the template engine, a map of templates, one
 // per key and the variables for the templates.
 type TemplatedConfigRequest struct {
-	Type      string                     `json:"type,omitempty"`
-	Templates map[string]string          `json:"templates,omitempty"`
-	Values    map[string]SecretReference `json:"values,omitempty"`
+	// Type of template being used (helm)
+	Type string `json:"type,omitempty"`
+	// Templates to render, one per key of the generated secret
+	Templates map[string]string `json:"templates,omitempty"`
+	// Template values to interpolate in the generated secret
+	Values map[string]SecretReference `json:"values,omitempty"`
+}
+
+// PluginRequest defines the parameters and the referenced secret values
+// passed to the exec plugin of a custom secret type
+type PluginRequest struct {
+	// Parameters passed to the plugin
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Values of referenced secret keys passed to the plugin
+	Values map[string]SecretReference `json:"values,omitempty"`
 }
 
 // Request specifies details for the secret generation
 type Request struct {
-	BasicAuthRequest        BasicAuthRequest        `json:"basic-auth"`
-	CertificateRequest      CertificateRequest      `json:"certificate"`
+	// BasicAuth generates a password for the given username
+	// +optional
+	BasicAuthRequest BasicAuthRequest `json:"basic-auth"`
+	// Certificate generates a certificate and its private key
+	// +optional
+	CertificateRequest CertificateRequest `json:"certificate"`
+	// ImageCredentials generates a docker config json from the referenced
+	// secrets
+	// +optional
 	ImageCredentialsRequest ImageCredentialsRequest `json:"imageCredentials"`
-	TemplatedConfigRequest  TemplatedConfigRequest  `json:"templatedConfig,omitempty"`
+	// TemplatedConfig renders the template map into the generated secret
+	TemplatedConfigRequest TemplatedConfigRequest `json:"templatedConfig,omitempty"`
+	// Plugin passes parameters and secret values to the exec plugin of a
+	// custom type
+	PluginRequest PluginRequest `json:"plugin,omitempty"`
 }
 
-// Copy defines the destination of a copied generated secret
+// Copy defines the destination of a copied generated secret and how its
+// keys, type and metadata are transformed
 // We can't use types.NamespacedName because it doesn't marshal properly
 type Copy struct {
-	Name      string `json:"name"`
+	// The name of the copied secret
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// The namespace of the copied secret
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	Namespace string `json:"namespace"`
+	// Name of a secret in the namespace of the quarks secret, which holds
+	// the kubeconfig of a remote cluster to copy to, in the 'kubeconfig' key
+	Cluster string `json:"cluster,omitempty"`
+
+	// Only these keys of the generated secret are copied, all if empty
+	IncludeKeys []string `json:"includeKeys,omitempty"`
+	// These keys of the generated secret are never copied
+	ExcludeKeys []string `json:"excludeKeys,omitempty"`
+	// Renames keys in the copy, from the key in the generated secret to the
+	// key in the copy
+	RenameKeys map[string]string `json:"renameKeys,omitempty"`
+	// Type of the copied secret, defaults to the type of the generated secret
+	Type corev1.SecretType `json:"type,omitempty"`
+	// Labels added to the copied secret
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations added to the copied secret
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 func (c *Copy) String() string {
+	if c.Cluster != "" {
+		return fmt.Sprintf("%s:%s/%s", c.Cluster, c.Namespace, c.Name)
+	}
 	return fmt.Sprintf("%s/%s", c.Namespace, c.Name)
 }
 
+// CopySelector selects the namespaces, which receive a copy of the generated
+// secret, by label. Copies need a QuarksSecretCopyPolicy or a placeholder in
+// the target namespace.
+type CopySelector struct {
+	// Label selector for the target namespaces
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
+	// Name of the copies, defaults to the secret name
+	Name string `json:"name,omitempty"`
+
+	// Only these keys of the generated secret are copied, all if empty
+	IncludeKeys []string `json:"includeKeys,omitempty"`
+	// These keys of the generated secret are never copied
+	ExcludeKeys []string `json:"excludeKeys,omitempty"`
+	// Renames keys in the copy, from the key in the generated secret to the
+	// key in the copy
+	RenameKeys map[string]string `json:"renameKeys,omitempty"`
+	// Type of the copied secret, defaults to the type of the generated secret
+	Type corev1.SecretType `json:"type,omitempty"`
+	// Labels added to the copied secret
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations added to the copied secret
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// PublicOutput defines the config maps, which receive the public parts of
+// the generated secret, e.g. the CA certificate or the public key
+type PublicOutput struct {
+	// Name of the config maps, defaults to the secret name
+	Name string `json:"name,omitempty"`
+	// Namespaces of the config maps, defaults to the namespace of the
+	// quarks secret
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// RestartOnChange selects the deployments and stateful sets in the namespace
+// of the quarks secret, which are restarted when the generated secret changes
+type RestartOnChange struct {
+	// Label selector for the workloads
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Restarts the workloads, which mount the generated secret or use it
+	// in environment variables
+	AutoDiscover bool `json:"autoDiscover,omitempty"`
+}
+
+// DeletionPolicy defines what happens to the generated secret and its
+// copies, when the quarks secret is deleted
+// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy = string
+
+// Valid values for deletion policies
+const (
+	// DeletionPolicyDelete deletes the generated secret and its copies
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the generated secret and deletes its copies
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan keeps the generated secret and its copies, the
+	// copies are no longer updated
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// DriftPolicy defines what happens, when the generated secret is deleted or
+// its data is changed outside of the operator
+// +kubebuilder:validation:Enum=Restore;Report;Ignore
+type DriftPolicy = string
+
+// Valid values for drift policies
+const (
+	// DriftPolicyRestore restores the last version of the generated secret.
+	// A deleted secret is regenerated, if there is no history, which is
+	// reported by the drift condition and not as a rotation.
+	DriftPolicyRestore DriftPolicy = "Restore"
+	// DriftPolicyReport reports the drift in the status
+	DriftPolicyReport DriftPolicy = "Report"
+	// DriftPolicyIgnore ignores changes to the generated secret
+	DriftPolicyIgnore DriftPolicy = "Ignore"
+)
+
+// Reasons for drift of the generated secret
+const (
+	DriftReasonDeleted  = "Deleted"
+	DriftReasonModified = "Modified"
+)
+
+// ExistingSecretPolicy defines what happens, when a secret with the name of
+// the generated secret exists, but was not generated by the operator
+// +kubebuilder:validation:Enum=Skip;Adopt;Validate
+type ExistingSecretPolicy = string
+
+// Valid values for existing secret policies
+const (
+	// ExistingSecretPolicySkip leaves the existing secret alone
+	ExistingSecretPolicySkip ExistingSecretPolicy = "Skip"
+	// ExistingSecretPolicyAdopt takes ownership of the existing secret, it
+	// is regenerated on the next rotation
+	ExistingSecretPolicyAdopt ExistingSecretPolicy = "Adopt"
+	// ExistingSecretPolicyValidate leaves the existing secret alone, but
+	// reports if it doesn't match the spec
+	ExistingSecretPolicyValidate ExistingSecretPolicy = "Validate"
+)
+
+// QuarksSecretConditionType is the type of a quarks secret condition
+type QuarksSecretConditionType = string
+
+// Valid values for condition types
+const (
+	// ConditionExistingSecret reports how a secret, which exists but was not
+	// generated, is handled
+	ConditionExistingSecret QuarksSecretConditionType = "ExistingSecret"
+	// ConditionUnused reports if the generated secret and its copies have
+	// no consumers for longer than spec.unusedAfter
+	ConditionUnused QuarksSecretConditionType = "Unused"
+	// ConditionExpiringSoon reports if the generated certificate expires
+	// within the window of the notification configs
+	ConditionExpiringSoon QuarksSecretConditionType = "ExpiringSoon"
+	// ConditionFIPSCompliant reports in FIPS mode if the request only uses
+	// FIPS 140 approved algorithms and key sizes
+	ConditionFIPSCompliant QuarksSecretConditionType = "FIPSCompliant"
+	// ConditionDrift reports how the drift policy resolved a generated
+	// secret, which was deleted or changed outside of the operator
+	ConditionDrift QuarksSecretConditionType = "Drift"
+	// ConditionRollback reports if the last spec.rollbackTo version was
+	// restored
+	ConditionRollback QuarksSecretConditionType = "Rollback"
+)
+
+// Reasons for the existing secret condition
+const (
+	ExistingSecretReasonSkipped = "Skipped"
+	ExistingSecretReasonAdopted = "Adopted"
+	ExistingSecretReasonValid   = "Valid"
+	ExistingSecretReasonInvalid = "Invalid"
+)
+
+// Reasons for the unused condition
+const (
+	UnusedReasonNoConsumers = "NoConsumers"
+	UnusedReasonConsumed    = "Consumed"
+)
+
+// Reasons for the expiring soon condition
+const (
+	ExpiryReasonExpiring    = "Expiring"
+	ExpiryReasonNotExpiring = "NotExpiring"
+)
+
+// Reasons for the FIPS compliant condition
+const (
+	FIPSReasonApproved    = "Approved"
+	FIPSReasonNotApproved = "NotApproved"
+)
+
+// Reasons for the drift condition
+const (
+	// DriftConditionRestored means the current version was restored from
+	// the history
+	DriftConditionRestored = "Restored"
+	// DriftConditionRegenerated means the deleted secret was regenerated
+	// with new credentials, because its version is not in the history
+	DriftConditionRegenerated = "Regenerated"
+)
+
+// Reasons for the rollback condition
+const (
+	RollbackReasonRestored        = "Restored"
+	RollbackReasonVersionNotFound = "VersionNotFound"
+	RollbackReasonNotGenerated    = "NotGenerated"
+)
+
+// RotationSpec specifies how the generated secret is rotated
+type RotationSpec struct {
+	// Bumping the generation regenerates the secret
+	Generation int64 `json:"generation,omitempty"`
+	// Keep the previous password, private key and certificate in the
+	// generated secret for a grace period, after it is regenerated
+	KeepPrevious bool `json:"keepPrevious,omitempty"`
+	// How long the previous values are kept, e.g. 30m, defaults to one hour
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
 // QuarksSecretSpec defines the desired state of QuarksSecret
 type QuarksSecretSpec struct {
-	Type              SecretType        `json:"type"`
-	Request           Request           `json:"request"`
-	SecretName        string            `json:"secretName"`
-	Copies            []Copy            `json:"copies,omitempty"`
-	SecretLabels      map[string]string `json:"secretLabels,omitempty"`
+	// What kind of secret to generate: password, certificate, tls, ssh,
+	// rsa, basic-auth, dockerconfigjson, copy, templatedconfig or a
+	// <domain>/<name> type generated by a plugin, see PluginTypePattern
+	// +kubebuilder:validation:Pattern=`^(password|certificate|tls|ssh|rsa|basic-auth|dockerconfigjson|copy|templatedconfig|[a-z0-9]([-a-z0-9.]*[a-z0-9])?/[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`
+	Type SecretType `json:"type"`
+	// Details for the secret generation, depending on the type
+	// +optional
+	Request Request `json:"request"`
+	// Rotation of the generated secret
+	Rotation RotationSpec `json:"rotation,omitempty"`
+	// The name of the generated secret
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+	// A list of namespaced names where to copy generated secrets
+	Copies []Copy `json:"copies,omitempty"`
+	// Copies the generated secret to all namespaces matching the selector
+	CopySelector *CopySelector `json:"copySelector,omitempty"`
+	// Labels added to the generated secret
+	SecretLabels map[string]string `json:"secretLabels,omitempty"`
+	// Annotations added to the generated secret
 	SecretAnnotations map[string]string `json:"secretAnnotations,omitempty"`
+	// Number of generated versions kept in history secrets, none if zero
+	HistoryLimit int `json:"historyLimit,omitempty"`
+	// Restores this version from the history into the generated secret
+	// and its copies. Changing the rollback annotation restores it again.
+	RollbackTo *int64 `json:"rollbackTo,omitempty"`
+	// What happens to the generated secret and its copies, when the quarks
+	// secret is deleted, defaults to Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// What happens, when the generated secret is deleted or changed outside
+	// of the operator, defaults to Restore
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+	// What happens, when a secret with the secret name exists, but was not
+	// generated, defaults to Skip
+	ExistingSecretPolicy ExistingSecretPolicy `json:"existingSecretPolicy,omitempty"`
+	// Writes the public parts of the generated secret to config maps
+	PublicOutput *PublicOutput `json:"publicOutput,omitempty"`
+	// Restarts deployments and stateful sets, when the generated secret
+	// changes
+	RestartOnChange *RestartOnChange `json:"restartOnChange,omitempty"`
+	// How long the generated secret and its copies may have no consumers,
+	// before the quarks secret is flagged as unused, defaults to 30 days
+	UnusedAfter *metav1.Duration `json:"unusedAfter,omitempty"`
+	// Name of the generator backend, which generates the secret, defaults
+	// to the generator of the operator
+	GeneratorRef string `json:"generatorRef,omitempty"`
 }
 
 // QuarksSecretStatus defines the observed state of QuarksSecret
 type QuarksSecretStatus struct {
 	// Timestamp for the last reconcile
+	// +optional
+	// +nullable
 	LastReconcile *metav1.Time `json:"lastReconcile"`
 	// Indicates if the secret has already been generated
+	// +optional
+	// +nullable
 	Generated *bool `json:"generated"`
 	// Indicates if the copy secrets have been updated
+	// +optional
+	// +nullable
 	Copied *bool `json:"copied"`
+	// The last rotation of the generated secret
+	Rotation *RotationStatus `json:"rotation,omitempty"`
+	// The version of the generated secret, counting up with every change
+	Version int64 `json:"version,omitempty"`
+	// The last rollback of the generated secret
+	Rollback *RollbackStatus `json:"rollback,omitempty"`
+	// The copies written by the operator
+	Copies []CopyStatus `json:"copies,omitempty"`
+	// Reports changes to the generated secret outside of the operator
+	Drift *DriftStatus `json:"drift,omitempty"`
+	// Conditions of the quarks secret, e.g. the validation of an existing
+	// secret
+	Conditions []QuarksSecretCondition `json:"conditions,omitempty"`
+	// The config maps written with the public parts of the generated secret
+	PublicOutputs []PublicOutputStatus `json:"publicOutputs,omitempty"`
+	// The content hash of the generated secret, which the workloads were
+	// last restarted for
+	RestartChecksum string `json:"restartChecksum,omitempty"`
+	// The pods, deployments and stateful sets using the generated secret or
+	// its copies
+	Consumers []ConsumerStatus `json:"consumers,omitempty"`
+	// Since when the generated secret and its copies have no consumers
+	UnusedSince *metav1.Time `json:"unusedSince,omitempty"`
+}
+
+// QuarksSecretCondition describes the state of a quarks secret
+type QuarksSecretCondition struct {
+	Type   QuarksSecretConditionType `json:"type"`
+	Status corev1.ConditionStatus    `json:"status"`
+	// Machine readable reason for the condition
+	Reason string `json:"reason,omitempty"`
+	// Human readable details
+	Message string `json:"message,omitempty"`
+	// Timestamp of the last change of the status
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// DriftStatus reports a generated secret, which was deleted or changed
+// outside of the operator
+type DriftStatus struct {
+	// Deleted or Modified
+	Reason string `json:"reason"`
+	// Timestamp of the detection
+	DetectedAt *metav1.Time `json:"detectedAt,omitempty"`
+}
+
+// CopyState is the state of a copy of the generated secret
+// +kubebuilder:validation:Enum=Synced;SkippedNoPlaceholder;SkippedWrongAnnotation;Error
+type CopyState = string
+
+// Valid values for copy states
+const (
+	// CopyStateSynced means the copy was written
+	CopyStateSynced CopyState = "Synced"
+	// CopyStateSkippedNoPlaceholder means the target namespace has no
+	// placeholder and no copy policy allows the copy
+	CopyStateSkippedNoPlaceholder CopyState = "SkippedNoPlaceholder"
+	// CopyStateSkippedWrongAnnotation means the placeholder in the target
+	// namespace is not a copy of the quarks secret
+	CopyStateSkippedWrongAnnotation CopyState = "SkippedWrongAnnotation"
+	// CopyStateError means writing the copy failed
+	CopyStateError CopyState = "Error"
+)
+
+// CopyStatus records a copy of the generated secret
+type CopyStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Name of the kubeconfig secret of the remote cluster, empty for copies
+	// in the same cluster
+	Cluster string `json:"cluster,omitempty"`
+	// State of the copy
+	State CopyState `json:"state,omitempty"`
+	// Why the copy was skipped or failed
+	Message string `json:"message,omitempty"`
+	// The last time the copy was written with new content
+	LastSync *metav1.Time `json:"lastSync,omitempty"`
+	// Hash of the data written to the copy
+	ContentHash string `json:"contentHash,omitempty"`
+}
+
+// PublicOutputStatus records a config map with the public parts of the
+// generated secret
+type PublicOutputStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ConsumerStatus records a pod, deployment or stateful set, which uses the
+// generated secret or one of its copies
+type ConsumerStatus struct {
+	// Pod, Deployment or StatefulSet
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Name of the used secret
+	Secret string `json:"secret"`
+}
+
+// RotationStatus records which rotation request the generated secret reflects
+type RotationStatus struct {
+	// The spec.rotation.generation of the generated secret
+	Generation int64 `json:"generation,omitempty"`
+	// The value of the rotate annotation of the generated secret
+	Annotation string `json:"annotation,omitempty"`
+	// Timestamp of the last rotation
+	LastRotation *metav1.Time `json:"lastRotation,omitempty"`
+	// A rotation config or policy requested the rotation of the generated
+	// secret, which has not been written yet
+	Requested bool `json:"requested,omitempty"`
+}
+
+// RollbackStatus records the last rollback of the generated secret. The
+// rollback condition reports if the version was restored.
+type RollbackStatus struct {
+	// The spec.rollbackTo version of the last rollback
+	Version int64 `json:"version"`
+	// The value of the rollback annotation of the last rollback
+	Annotation string `json:"annotation,omitempty"`
+	// Timestamp of the rollback
+	Time *metav1.Time `json:"time,omitempty"`
 }
 
 // IsCopied returns true if the copied field is a true value
@@ -193,6 +676,35 @@ func (qs QuarksSecretStatus) NotGenerated() bool {
 	return !*qs.Generated
 }
 
+// GetCondition returns the condition of the given type, or nil
+func (qs QuarksSecretStatus) GetCondition(conditionType QuarksSecretConditionType) *QuarksSecretCondition {
+	for i := range qs.Conditions {
+		if qs.Conditions[i].Type == conditionType {
+			return &qs.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or replaces the condition of the same type. The
+// transition time is kept, unless the status changes.
+func (qs *QuarksSecretStatus) SetCondition(condition QuarksSecretCondition) {
+	if condition.LastTransitionTime == nil {
+		now := metav1.Now()
+		condition.LastTransitionTime = &now
+	}
+
+	existing := qs.GetCondition(condition.Type)
+	if existing == nil {
+		qs.Conditions = append(qs.Conditions, condition)
+		return
+	}
+	if existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	}
+	*existing = condition
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -202,9 +714,10 @@ type QuarksSecret struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec         QuarksSecretSpec   `json:"spec,omitempty"`
-	Status       QuarksSecretStatus `json:"status,omitempty"`
-	SecretLabels map[string]string  `json:"secretLabels,omitempty"`
+	Spec   QuarksSecretSpec   `json:"spec,omitempty"`
+	Status QuarksSecretStatus `json:"status,omitempty"`
+	// Deprecated, use spec.secretLabels
+	SecretLabels map[string]string `json:"secretLabels,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -221,6 +734,298 @@ func (qs *QuarksSecret) GetNamespacedName() string {
 	return fmt.Sprintf("%s/%s", qs.Namespace, qs.Name)
 }
 
+// IsPluginType returns true if the secret type is a custom type, which is
+// generated by an exec plugin
+func IsPluginType(secretType SecretType) bool {
+	return pluginTypeRegexp.MatchString(secretType)
+}
+
+// RotationRequested returns true if the rotation generation or the rotate
+// annotation changed since the secret was generated
+func (qs *QuarksSecret) RotationRequested() bool {
+	generation := int64(0)
+	annotation := ""
+	if qs.Status.Rotation != nil {
+		generation = qs.Status.Rotation.Generation
+		annotation = qs.Status.Rotation.Annotation
+	}
+
+	return qs.Spec.Rotation.Generation != generation || qs.Annotations[AnnotationRotate] != annotation
+}
+
+// RotationDue returns true if the secret is about to be regenerated for a
+// rotation, which was requested by a rotation config or policy, or by
+// changing the rotation generation or the rotate annotation
+func (qs *QuarksSecret) RotationDue() bool {
+	if qs.Status.Rotation != nil && qs.Status.Rotation.Requested {
+		return true
+	}
+	return qs.Status.IsGenerated() && qs.RotationRequested()
+}
+
+// RollbackRequested returns true if spec.rollbackTo names a version, which
+// has not been rolled back to yet, or the rollback annotation changed since
+// the last rollback
+func (qs *QuarksSecret) RollbackRequested() bool {
+	if qs.Spec.RollbackTo == nil {
+		return false
+	}
+	if qs.Status.Rollback == nil {
+		return true
+	}
+	return qs.Status.Rollback.Version != *qs.Spec.RollbackTo || qs.Status.Rollback.Annotation != qs.Annotations[AnnotationRollback]
+}
+
+// MaintenanceWindow is a time range on some or all days of the week, in
+// which scheduled rotations may run
+type MaintenanceWindow struct {
+	// Days of the week the window opens on, e.g. Sunday, every day if empty
+	// +kubebuilder:validation:items:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+	Days []string `json:"days,omitempty"`
+	// Start of the window, e.g. 02:00
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End of the window, e.g. 04:00. The window ends on the next day if
+	// end is before start.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+// QuarksSecretRotationPolicySpec defines which quarks secrets are rotated
+// and when
+type QuarksSecretRotationPolicySpec struct {
+	// Cron schedule of the rotation, e.g. "0 2 * * *"
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// Time zone of the schedule and the maintenance windows, e.g.
+	// Europe/Berlin, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+	// Selects the quarks secrets in the namespace, all if empty
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Only rotate quarks secrets of these types, all types if empty
+	Types []SecretType `json:"types,omitempty"`
+	// Schedules outside of the maintenance windows are skipped, a running
+	// rotation pauses while they are closed
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// The maximum number of secrets regenerated at the same time, unlimited if zero
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+}
+
+// QuarksSecretRotationPolicyStatus defines the observed state of
+// QuarksSecretRotationPolicy
+type QuarksSecretRotationPolicyStatus struct {
+	// Timestamp of the last scheduled rotation, which may have been skipped
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// Timestamp of the end of the last scheduled rotation
+	LastCompletionTime *metav1.Time `json:"lastCompletionTime,omitempty"`
+	// When the next rotation is due
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+	// Names of the quarks secrets waiting for rotation
+	Pending []string `json:"pending,omitempty"`
+	// Names of the quarks secrets being regenerated
+	InProgress []string `json:"inProgress,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecretRotationPolicy rotates quarks secrets on a schedule
+// +k8s:openapi-gen=true
+type QuarksSecretRotationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QuarksSecretRotationPolicySpec   `json:"spec,omitempty"`
+	Status QuarksSecretRotationPolicyStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecretRotationPolicyList contains a list of QuarksSecretRotationPolicy
+type QuarksSecretRotationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuarksSecretRotationPolicy `json:"items"`
+}
+
+// GetNamespacedName returns the resource name with its namespace
+func (p *QuarksSecretRotationPolicy) GetNamespacedName() string {
+	return fmt.Sprintf("%s/%s", p.Namespace, p.Name)
+}
+
+// QuarksSecretCopyPolicySpec defines which namespaces may copy generated
+// secrets into which namespaces
+type QuarksSecretCopyPolicySpec struct {
+	// Namespaces of the quarks secrets, which may write copies
+	SourceNamespaces []string `json:"sourceNamespaces"`
+	// Label selector for the namespaces, which copies may be written to
+	TargetNamespaceSelector *metav1.LabelSelector `json:"targetNamespaceSelector"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecretCopyPolicy allows quarks secrets to copy their generated
+// secrets into other namespaces, without a placeholder in the target
+// namespace
+// +k8s:openapi-gen=true
+type QuarksSecretCopyPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec QuarksSecretCopyPolicySpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecretCopyPolicyList contains a list of QuarksSecretCopyPolicy
+type QuarksSecretCopyPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuarksSecretCopyPolicy `json:"items"`
+}
+
+// NotificationEvent is a lifecycle event of a generated secret, which is
+// sent to notification endpoints
+// +kubebuilder:validation:Enum=generated;rotated;copied;failed;expiring-soon
+type NotificationEvent string
+
+// Valid values for notification events
+const (
+	// NotificationGenerated is sent when a secret is generated for the first time
+	NotificationGenerated NotificationEvent = "generated"
+	// NotificationRotated is sent when a secret is regenerated
+	NotificationRotated NotificationEvent = "rotated"
+	// NotificationCopied is sent when the content of a copy changed
+	NotificationCopied NotificationEvent = "copied"
+	// NotificationFailed is sent for errors of a quarks secret, which are
+	// not resolved by retrying
+	NotificationFailed NotificationEvent = "failed"
+	// NotificationExpiringSoon is sent when a generated certificate expires soon
+	NotificationExpiringSoon NotificationEvent = "expiring-soon"
+)
+
+// NotificationEndpoint is an HTTP endpoint, which receives notifications
+type NotificationEndpoint struct {
+	// URL the notifications are posted to
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+	// Key in a secret of the namespace, used to sign the notifications
+	// with HMAC-SHA256
+	SigningKeyRef *SecretReference `json:"signingKeyRef,omitempty"`
+	// How often a failed delivery is retried, defaults to 5
+	MaxRetries *int `json:"maxRetries,omitempty"`
+}
+
+// QuarksSecretNotificationConfigSpec defines where notifications about the
+// quarks secrets in the namespace are sent
+type QuarksSecretNotificationConfigSpec struct {
+	// Endpoints, which receive the notifications
+	// +kubebuilder:validation:MinItems=1
+	Endpoints []NotificationEndpoint `json:"endpoints"`
+	// Only send these events, all events if empty
+	Events []NotificationEvent `json:"events,omitempty"`
+	// Selects the quarks secrets in the namespace, all if empty
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// How long before a generated certificate expires it is reported as
+	// expiring soon, defaults to 30 days
+	ExpiringSoonBefore *metav1.Duration `json:"expiringSoonBefore,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecretNotificationConfig sends notifications about the lifecycle of
+// generated secrets to HTTP endpoints
+// +k8s:openapi-gen=true
+type QuarksSecretNotificationConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec QuarksSecretNotificationConfigSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecretNotificationConfigList contains a list of QuarksSecretNotificationConfig
+type QuarksSecretNotificationConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuarksSecretNotificationConfig `json:"items"`
+}
+
+// GetNamespacedName returns the resource name with its namespace
+func (c *QuarksSecretNotificationConfig) GetNamespacedName() string {
+	return fmt.Sprintf("%s/%s", c.Namespace, c.Name)
+}
+
+// Sends returns true if the config sends notifications for the event
+func (c *QuarksSecretNotificationConfig) Sends(event NotificationEvent) bool {
+	if len(c.Spec.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Spec.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// PasswordPolicy defines how passwords are generated
+type PasswordPolicy struct {
+	// Number of characters of generated passwords
+	Length int `json:"length,omitempty"`
+	// Characters generated passwords consist of, alphanumeric if empty
+	// +kubebuilder:validation:MinLength=2
+	// +kubebuilder:validation:MaxLength=256
+	Characters string `json:"characters,omitempty"`
+}
+
+// QuarksSecretDefaultsSpec overrides the generator defaults of the operator
+// for the quarks secrets in the namespace
+type QuarksSecretDefaultsSpec struct {
+	// Algorithm of certificate keys, rsa or ecdsa
+	// +kubebuilder:validation:Enum=rsa;ecdsa
+	KeyAlgorithm string `json:"keyAlgorithm,omitempty"`
+	// Size of certificate keys in bits, 2048 to 8192 for rsa, 256, 384 or
+	// 521 for ecdsa
+	KeySize int `json:"keySize,omitempty"`
+	// Validity of generated certificates in days
+	CertificateExpiry int `json:"certificateExpiry,omitempty"`
+	// Policy for generated passwords
+	PasswordPolicy *PasswordPolicy `json:"passwordPolicy,omitempty"`
+}
+
+// +genclient
+// +resourceName=quarkssecretdefaults
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecretDefaults overrides the generator defaults for the quarks
+// secrets in its namespace. There is at most one per namespace.
+// +k8s:openapi-gen=true
+type QuarksSecretDefaults struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec QuarksSecretDefaultsSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecretDefaultsList contains a list of QuarksSecretDefaults
+type QuarksSecretDefaultsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuarksSecretDefaults `json:"items"`
+}
+
+// GetNamespacedName returns the resource name with its namespace
+func (d *QuarksSecretDefaults) GetNamespacedName() string {
+	return fmt.Sprintf("%s/%s", d.Namespace, d.Name)
+}
+
 // IsMonitoredNamespace returns true if the namespace has all the necessary
 // labels and should be included in controller watches.
 func IsMonitoredNamespace(n *corev1.Namespace, id string) bool {
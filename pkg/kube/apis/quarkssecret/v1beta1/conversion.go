@@ -0,0 +1,255 @@
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+)
+
+// ConvertTo converts this QuarksSecret to the hub version (v1alpha1)
+func (qsec *QuarksSecret) ConvertTo(hub conversion.Hub) error {
+	src := qsec
+	dst := hub.(*qsv1a1.QuarksSecret)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.SecretLabels = nil
+
+	dst.Spec = qsv1a1.QuarksSecretSpec{
+		Type:                 src.Spec.Type,
+		SecretName:           src.Spec.SecretName,
+		SecretLabels:         src.Spec.SecretLabels,
+		SecretAnnotations:    src.Spec.SecretAnnotations,
+		Rotation:             qsv1a1.RotationSpec(src.Spec.Rotation),
+		HistoryLimit:         src.Spec.HistoryLimit,
+		RollbackTo:           src.Spec.RollbackTo,
+		DeletionPolicy:       src.Spec.DeletionPolicy,
+		DriftPolicy:          src.Spec.DriftPolicy,
+		ExistingSecretPolicy: src.Spec.ExistingSecretPolicy,
+		UnusedAfter:          src.Spec.UnusedAfter,
+		GeneratorRef:         src.Spec.GeneratorRef,
+		Request: qsv1a1.Request{
+			BasicAuthRequest: qsv1a1.BasicAuthRequest{
+				Username: src.Spec.Request.BasicAuthRequest.Username,
+			},
+			CertificateRequest: qsv1a1.CertificateRequest{
+				CommonName:                  src.Spec.Request.CertificateRequest.CommonName,
+				AlternativeNames:            src.Spec.Request.CertificateRequest.AlternativeNames,
+				IsCA:                        src.Spec.Request.CertificateRequest.IsCA,
+				CARef:                       qsv1a1.SecretReference(src.Spec.Request.CertificateRequest.CARef),
+				CAKeyRef:                    qsv1a1.SecretReference(src.Spec.Request.CertificateRequest.CAKeyRef),
+				SignerType:                  src.Spec.Request.CertificateRequest.SignerType,
+				Usages:                      src.Spec.Request.CertificateRequest.Usages,
+				ActivateEKSWorkaroundForSAN: src.Spec.Request.CertificateRequest.ActivateEKSWorkaroundForSAN,
+			},
+			ImageCredentialsRequest: qsv1a1.ImageCredentialsRequest{
+				Username: qsv1a1.SecretReference(src.Spec.Request.ImageCredentialsRequest.Username),
+				Password: qsv1a1.SecretReference(src.Spec.Request.ImageCredentialsRequest.Password),
+				Registry: src.Spec.Request.ImageCredentialsRequest.Registry,
+				Email:    src.Spec.Request.ImageCredentialsRequest.Email,
+			},
+			TemplatedConfigRequest: qsv1a1.TemplatedConfigRequest{
+				Type:      src.Spec.Request.TemplatedConfigRequest.Type,
+				Templates: src.Spec.Request.TemplatedConfigRequest.Templates,
+			},
+		},
+	}
+
+	for _, ref := range src.Spec.Request.CertificateRequest.ServiceRef {
+		dst.Spec.Request.CertificateRequest.ServiceRef = append(dst.Spec.Request.CertificateRequest.ServiceRef, qsv1a1.ServiceReference(ref))
+	}
+
+	if src.Spec.Request.TemplatedConfigRequest.Values != nil {
+		dst.Spec.Request.TemplatedConfigRequest.Values = map[string]qsv1a1.SecretReference{}
+		for name, ref := range src.Spec.Request.TemplatedConfigRequest.Values {
+			dst.Spec.Request.TemplatedConfigRequest.Values[name] = qsv1a1.SecretReference(ref)
+		}
+	}
+
+	dst.Spec.Request.PluginRequest.Parameters = src.Spec.Request.PluginRequest.Parameters
+	if src.Spec.Request.PluginRequest.Values != nil {
+		dst.Spec.Request.PluginRequest.Values = map[string]qsv1a1.SecretReference{}
+		for name, ref := range src.Spec.Request.PluginRequest.Values {
+			dst.Spec.Request.PluginRequest.Values[name] = qsv1a1.SecretReference(ref)
+		}
+	}
+
+	for _, copy := range src.Spec.Copies {
+		dst.Spec.Copies = append(dst.Spec.Copies, qsv1a1.Copy(copy))
+	}
+	if src.Spec.CopySelector != nil {
+		selector := qsv1a1.CopySelector(*src.Spec.CopySelector)
+		dst.Spec.CopySelector = &selector
+	}
+	if src.Spec.PublicOutput != nil {
+		output := qsv1a1.PublicOutput(*src.Spec.PublicOutput)
+		dst.Spec.PublicOutput = &output
+	}
+	if src.Spec.RestartOnChange != nil {
+		restart := qsv1a1.RestartOnChange(*src.Spec.RestartOnChange)
+		dst.Spec.RestartOnChange = &restart
+	}
+
+	dst.Status = qsv1a1.QuarksSecretStatus{
+		LastReconcile:   src.Status.LastReconcile,
+		Generated:       src.Status.Generated,
+		Copied:          src.Status.Copied,
+		Version:         src.Status.Version,
+		RestartChecksum: src.Status.RestartChecksum,
+		UnusedSince:     src.Status.UnusedSince,
+	}
+	if src.Status.Rotation != nil {
+		rotation := qsv1a1.RotationStatus(*src.Status.Rotation)
+		dst.Status.Rotation = &rotation
+	}
+	if src.Status.Rollback != nil {
+		rollback := qsv1a1.RollbackStatus(*src.Status.Rollback)
+		dst.Status.Rollback = &rollback
+	}
+	for _, copy := range src.Status.Copies {
+		dst.Status.Copies = append(dst.Status.Copies, qsv1a1.CopyStatus(copy))
+	}
+	if src.Status.Drift != nil {
+		drift := qsv1a1.DriftStatus(*src.Status.Drift)
+		dst.Status.Drift = &drift
+	}
+	for _, condition := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, qsv1a1.QuarksSecretCondition(condition))
+	}
+	for _, output := range src.Status.PublicOutputs {
+		dst.Status.PublicOutputs = append(dst.Status.PublicOutputs, qsv1a1.PublicOutputStatus(output))
+	}
+	for _, consumer := range src.Status.Consumers {
+		dst.Status.Consumers = append(dst.Status.Consumers, qsv1a1.ConsumerStatus(consumer))
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version.
+// The deprecated top-level secret labels of v1alpha1 are merged into
+// spec.secretLabels, labels in the spec take precedence.
+func (qsec *QuarksSecret) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*qsv1a1.QuarksSecret)
+	dst := qsec
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = QuarksSecretSpec{
+		Type:                 src.Spec.Type,
+		SecretName:           src.Spec.SecretName,
+		SecretAnnotations:    src.Spec.SecretAnnotations,
+		Rotation:             RotationSpec(src.Spec.Rotation),
+		HistoryLimit:         src.Spec.HistoryLimit,
+		RollbackTo:           src.Spec.RollbackTo,
+		DeletionPolicy:       src.Spec.DeletionPolicy,
+		DriftPolicy:          src.Spec.DriftPolicy,
+		ExistingSecretPolicy: src.Spec.ExistingSecretPolicy,
+		UnusedAfter:          src.Spec.UnusedAfter,
+		GeneratorRef:         src.Spec.GeneratorRef,
+		Request: Request{
+			BasicAuthRequest: BasicAuthRequest{
+				Username: src.Spec.Request.BasicAuthRequest.Username,
+			},
+			CertificateRequest: CertificateRequest{
+				CommonName:                  src.Spec.Request.CertificateRequest.CommonName,
+				AlternativeNames:            src.Spec.Request.CertificateRequest.AlternativeNames,
+				IsCA:                        src.Spec.Request.CertificateRequest.IsCA,
+				CARef:                       SecretReference(src.Spec.Request.CertificateRequest.CARef),
+				CAKeyRef:                    SecretReference(src.Spec.Request.CertificateRequest.CAKeyRef),
+				SignerType:                  src.Spec.Request.CertificateRequest.SignerType,
+				Usages:                      src.Spec.Request.CertificateRequest.Usages,
+				ActivateEKSWorkaroundForSAN: src.Spec.Request.CertificateRequest.ActivateEKSWorkaroundForSAN,
+			},
+			ImageCredentialsRequest: ImageCredentialsRequest{
+				Username: SecretReference(src.Spec.Request.ImageCredentialsRequest.Username),
+				Password: SecretReference(src.Spec.Request.ImageCredentialsRequest.Password),
+				Registry: src.Spec.Request.ImageCredentialsRequest.Registry,
+				Email:    src.Spec.Request.ImageCredentialsRequest.Email,
+			},
+			TemplatedConfigRequest: TemplatedConfigRequest{
+				Type:      src.Spec.Request.TemplatedConfigRequest.Type,
+				Templates: src.Spec.Request.TemplatedConfigRequest.Templates,
+			},
+		},
+	}
+
+	if len(src.SecretLabels) > 0 || src.Spec.SecretLabels != nil {
+		dst.Spec.SecretLabels = map[string]string{}
+		for k, v := range src.SecretLabels {
+			dst.Spec.SecretLabels[k] = v
+		}
+		for k, v := range src.Spec.SecretLabels {
+			dst.Spec.SecretLabels[k] = v
+		}
+	}
+
+	for _, ref := range src.Spec.Request.CertificateRequest.ServiceRef {
+		dst.Spec.Request.CertificateRequest.ServiceRef = append(dst.Spec.Request.CertificateRequest.ServiceRef, ServiceReference(ref))
+	}
+
+	if src.Spec.Request.TemplatedConfigRequest.Values != nil {
+		dst.Spec.Request.TemplatedConfigRequest.Values = map[string]SecretReference{}
+		for name, ref := range src.Spec.Request.TemplatedConfigRequest.Values {
+			dst.Spec.Request.TemplatedConfigRequest.Values[name] = SecretReference(ref)
+		}
+	}
+
+	dst.Spec.Request.PluginRequest.Parameters = src.Spec.Request.PluginRequest.Parameters
+	if src.Spec.Request.PluginRequest.Values != nil {
+		dst.Spec.Request.PluginRequest.Values = map[string]SecretReference{}
+		for name, ref := range src.Spec.Request.PluginRequest.Values {
+			dst.Spec.Request.PluginRequest.Values[name] = SecretReference(ref)
+		}
+	}
+
+	for _, copy := range src.Spec.Copies {
+		dst.Spec.Copies = append(dst.Spec.Copies, Copy(copy))
+	}
+	if src.Spec.CopySelector != nil {
+		selector := CopySelector(*src.Spec.CopySelector)
+		dst.Spec.CopySelector = &selector
+	}
+	if src.Spec.PublicOutput != nil {
+		output := PublicOutput(*src.Spec.PublicOutput)
+		dst.Spec.PublicOutput = &output
+	}
+	if src.Spec.RestartOnChange != nil {
+		restart := RestartOnChange(*src.Spec.RestartOnChange)
+		dst.Spec.RestartOnChange = &restart
+	}
+
+	dst.Status = QuarksSecretStatus{
+		LastReconcile:   src.Status.LastReconcile,
+		Generated:       src.Status.Generated,
+		Copied:          src.Status.Copied,
+		Version:         src.Status.Version,
+		RestartChecksum: src.Status.RestartChecksum,
+		UnusedSince:     src.Status.UnusedSince,
+	}
+	if src.Status.Rotation != nil {
+		rotation := RotationStatus(*src.Status.Rotation)
+		dst.Status.Rotation = &rotation
+	}
+	if src.Status.Rollback != nil {
+		rollback := RollbackStatus(*src.Status.Rollback)
+		dst.Status.Rollback = &rollback
+	}
+	for _, copy := range src.Status.Copies {
+		dst.Status.Copies = append(dst.Status.Copies, CopyStatus(copy))
+	}
+	if src.Status.Drift != nil {
+		drift := DriftStatus(*src.Status.Drift)
+		dst.Status.Drift = &drift
+	}
+	for _, condition := range src.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, QuarksSecretCondition(condition))
+	}
+	for _, output := range src.Status.PublicOutputs {
+		dst.Status.PublicOutputs = append(dst.Status.PublicOutputs, PublicOutputStatus(output))
+	}
+	for _, consumer := range src.Status.Consumers {
+		dst.Status.Consumers = append(dst.Status.Consumers, ConsumerStatus(consumer))
+	}
+
+	return nil
+}
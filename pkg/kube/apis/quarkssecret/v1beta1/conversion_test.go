@@ -0,0 +1,163 @@
+package v1beta1_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	certv1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	qsv1b1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1beta1"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+)
+
+var _ = Describe("Conversion", func() {
+	var qsec *qsv1b1.QuarksSecret
+
+	BeforeEach(func() {
+		qsec = &qsv1b1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Labels:    map[string]string{"label": "value"},
+			},
+			Spec: qsv1b1.QuarksSecretSpec{
+				Type:       qsv1b1.Certificate,
+				SecretName: "generated",
+				Request: qsv1b1.Request{
+					BasicAuthRequest: qsv1b1.BasicAuthRequest{Username: "user"},
+					CertificateRequest: qsv1b1.CertificateRequest{
+						CommonName:                  "example.com",
+						AlternativeNames:            []string{"foo.example.com"},
+						IsCA:                        true,
+						CARef:                       qsv1b1.SecretReference{Name: "ca", Key: "certificate"},
+						CAKeyRef:                    qsv1b1.SecretReference{Name: "ca", Key: "private_key"},
+						SignerType:                  qsv1b1.ClusterSigner,
+						Usages:                      []certv1.KeyUsage{certv1.UsageServerAuth},
+						ServiceRef:                  []qsv1b1.ServiceReference{{Name: "svc"}},
+						ActivateEKSWorkaroundForSAN: true,
+					},
+					ImageCredentialsRequest: qsv1b1.ImageCredentialsRequest{
+						Username: qsv1b1.SecretReference{Name: "user", Key: "username"},
+						Password: qsv1b1.SecretReference{Name: "pass", Key: "password"},
+						Registry: "registry",
+						Email:    "email",
+					},
+					TemplatedConfigRequest: qsv1b1.TemplatedConfigRequest{
+						Type:      "helm",
+						Templates: map[string]string{"config": "{{ .Values.foo }}"},
+						Values:    map[string]qsv1b1.SecretReference{"foo": {Name: "foo", Key: "bar"}},
+					},
+					PluginRequest: qsv1b1.PluginRequest{
+						Parameters: map[string]string{"length": "32"},
+						Values:     map[string]qsv1b1.SecretReference{"seed": {Name: "seed", Key: "value"}},
+					},
+				},
+				Copies: []qsv1b1.Copy{{
+					Name:        "copy",
+					Namespace:   "other",
+					Cluster:     "workload-1",
+					IncludeKeys: []string{"certificate"},
+					ExcludeKeys: []string{"private_key"},
+					RenameKeys:  map[string]string{"certificate": "ca.crt"},
+					Type:        corev1.SecretTypeOpaque,
+					Labels:      map[string]string{"label": "value"},
+					Annotations: map[string]string{"annotation": "value"},
+				}},
+				SecretLabels:         map[string]string{"label": "value"},
+				SecretAnnotations:    map[string]string{"annotation": "value"},
+				Rotation:             qsv1b1.RotationSpec{Generation: 2, KeepPrevious: true, GracePeriod: &metav1.Duration{Duration: time.Hour}},
+				HistoryLimit:         5,
+				RollbackTo:           pointers.Int64(3),
+				DeletionPolicy:       qsv1b1.DeletionPolicyRetain,
+				DriftPolicy:          qsv1b1.DriftPolicyIgnore,
+				ExistingSecretPolicy: qsv1b1.ExistingSecretPolicyValidate,
+				PublicOutput:         &qsv1b1.PublicOutput{Name: "foo-public", Namespaces: []string{"default", "other"}},
+				RestartOnChange: &qsv1b1.RestartOnChange{
+					Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+					AutoDiscover: true,
+				},
+				UnusedAfter:  &metav1.Duration{Duration: 24 * time.Hour},
+				GeneratorRef: "vault",
+			},
+			Status: qsv1b1.QuarksSecretStatus{
+				LastReconcile: &metav1.Time{},
+				Generated:     pointers.Bool(true),
+				Copied:        pointers.Bool(false),
+				Rotation: &qsv1b1.RotationStatus{
+					Generation:   1,
+					Annotation:   "now",
+					LastRotation: &metav1.Time{},
+					Requested:    true,
+				},
+				Version:  4,
+				Rollback: &qsv1b1.RollbackStatus{Version: 3, Annotation: "again", Time: &metav1.Time{}},
+				Copies: []qsv1b1.CopyStatus{{
+					Name:        "copy",
+					Namespace:   "other",
+					Cluster:     "workload-1",
+					State:       qsv1b1.CopyStateSynced,
+					LastSync:    &metav1.Time{},
+					ContentHash: "abc",
+				}},
+				Drift: &qsv1b1.DriftStatus{Reason: qsv1b1.DriftReasonModified, DetectedAt: &metav1.Time{}},
+				Conditions: []qsv1b1.QuarksSecretCondition{{
+					Type:               qsv1b1.ConditionExistingSecret,
+					Status:             corev1.ConditionFalse,
+					Reason:             qsv1b1.ExistingSecretReasonInvalid,
+					Message:            "missing key 'password'",
+					LastTransitionTime: &metav1.Time{},
+				}},
+				PublicOutputs:   []qsv1b1.PublicOutputStatus{{Name: "foo-public", Namespace: "other"}},
+				RestartChecksum: "abc",
+				Consumers:       []qsv1b1.ConsumerStatus{{Kind: "Deployment", Name: "app", Namespace: "other", Secret: "copy"}},
+				UnusedSince:     &metav1.Time{},
+			},
+		}
+	})
+
+	It("is convertible by the conversion webhook", func() {
+		scheme := runtime.NewScheme()
+		Expect(qsv1a1.AddToScheme(scheme)).To(Succeed())
+		Expect(qsv1b1.AddToScheme(scheme)).To(Succeed())
+
+		ok, err := conversion.IsConvertible(scheme, &qsv1a1.QuarksSecret{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	It("converts to v1alpha1 and back without losing fields", func() {
+		hub := &qsv1a1.QuarksSecret{}
+		Expect(qsec.ConvertTo(hub)).To(Succeed())
+
+		Expect(hub.Name).To(Equal("foo"))
+		Expect(hub.Spec.Request.CertificateRequest.CARef).To(Equal(qsv1a1.SecretReference{Name: "ca", Key: "certificate"}))
+		Expect(hub.Spec.Request.CertificateRequest.ServiceRef).To(Equal([]qsv1a1.ServiceReference{{Name: "svc"}}))
+		Expect(hub.Spec.Copies).To(HaveLen(1))
+		Expect(hub.Spec.Copies[0].RenameKeys).To(Equal(map[string]string{"certificate": "ca.crt"}))
+		Expect(hub.Status.IsGenerated()).To(BeTrue())
+
+		result := &qsv1b1.QuarksSecret{}
+		Expect(result.ConvertFrom(hub)).To(Succeed())
+		Expect(result).To(Equal(qsec))
+	})
+
+	It("merges the deprecated top-level secret labels of v1alpha1", func() {
+		hub := &qsv1a1.QuarksSecret{
+			Spec: qsv1a1.QuarksSecretSpec{
+				SecretLabels: map[string]string{"foo": "spec"},
+			},
+			SecretLabels: map[string]string{"foo": "top", "bar": "top"},
+		}
+
+		result := &qsv1b1.QuarksSecret{}
+		Expect(result.ConvertFrom(hub)).To(Succeed())
+		Expect(result.Spec.SecretLabels).To(Equal(map[string]string{"foo": "spec", "bar": "top"}))
+	})
+})
@@ -0,0 +1,55 @@
+package v1beta1_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	qsv1b1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1beta1"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/apis/schemagen"
+)
+
+var _ = Describe("Validation schemas", func() {
+	It("are generated from the go types, run 'make gen-crds' if not", func() {
+		schemas, err := schemagen.Schemas(".")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schemas).To(Equal(map[string]*extv1.JSONSchemaProps{
+			"QuarksSecret": qsv1b1.QuarksSecretValidation.OpenAPIV3Schema,
+		}))
+	})
+
+	It("allows all secret types", func() {
+		types := []string{
+			qsv1b1.Password,
+			qsv1b1.Certificate,
+			qsv1b1.TLS,
+			qsv1b1.SSHKey,
+			qsv1b1.RSAKey,
+			qsv1b1.BasicAuth,
+			qsv1b1.DockerConfigJSON,
+			qsv1b1.SecretCopy,
+			qsv1b1.TemplatedConfig,
+			qsv1b1.PluginTypePattern,
+		}
+		pattern := qsv1b1.QuarksSecretValidation.OpenAPIV3Schema.Properties["spec"].Properties["type"].Pattern
+		Expect(pattern).To(Equal("^(" + strings.Join(types, "|") + ")$"))
+	})
+})
+
+var _ = Describe("QuarksSecretValidation", func() {
+	It("is a structural schema", func() {
+		internal := &apiextensions.JSONSchemaProps{}
+		err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(qsv1b1.QuarksSecretValidation.OpenAPIV3Schema, internal, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		structural, err := structuralschema.NewStructural(internal)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(structuralschema.ValidateStructural(field.NewPath("openAPIV3Schema"), structural)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,568 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	certv1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This file is safe to edit
+// It's used as input for the Kube code generator
+// Run "make generate" after modifying this file
+
+// SecretType defines the type of the generated secret
+type SecretType = string
+
+// Valid values for secret types
+const (
+	Password         SecretType = "password"
+	Certificate      SecretType = "certificate"
+	TLS              SecretType = "tls"
+	SSHKey           SecretType = "ssh"
+	RSAKey           SecretType = "rsa"
+	BasicAuth        SecretType = "basic-auth"
+	DockerConfigJSON SecretType = "dockerconfigjson"
+	SecretCopy       SecretType = "copy"
+	TemplatedConfig  SecretType = "templatedconfig"
+)
+
+// PluginTypePattern matches the custom secret types, which are generated by
+// exec plugins, e.g. example.com/ldap-password
+const PluginTypePattern = `[a-z0-9]([-a-z0-9.]*[a-z0-9])?/[a-z0-9]([-a-z0-9]*[a-z0-9])?`
+
+// SignerType defines the type of the certificate signer
+// +kubebuilder:validation:Enum=local;cluster
+type SignerType = string
+
+// Valid values for signer types
+const (
+	// LocalSigner defines the local as certificate signer
+	LocalSigner SignerType = "local"
+	// ClusterSigner defines the cluster as certificate signer
+	ClusterSigner SignerType = "cluster"
+)
+
+// SecretReference specifies a reference to another secret
+type SecretReference struct {
+	// The name of the referenced secret
+	Name string `json:"name"`
+	// The key in the referenced secret
+	Key string `json:"key"`
+}
+
+// ServiceReference specifies a reference to a service
+type ServiceReference struct {
+	// The name of the service
+	Name string `json:"name"`
+}
+
+// CertificateRequest specifies the details for the certificate generation
+type CertificateRequest struct {
+	// The common name of the certificate
+	CommonName string `json:"commonName"`
+	// Subject alternative names of the certificate
+	AlternativeNames []string `json:"alternativeNames,omitempty"`
+	// Generate a CA certificate
+	IsCA bool `json:"isCA,omitempty"`
+	// Reference to the secret containing the CA certificate
+	// +optional
+	CARef SecretReference `json:"caRef"`
+	// Reference to the secret containing the CA private key
+	// +optional
+	CAKeyRef SecretReference `json:"caKeyRef"`
+	// Who signs the certificate: local or cluster
+	SignerType SignerType `json:"signerType,omitempty"`
+	// Key usages requested from the cluster signer
+	Usages []certv1.KeyUsage `json:"usages,omitempty"`
+	// Services whose cluster DNS names are added to the certificate
+	ServiceRef []ServiceReference `json:"serviceRef,omitempty"`
+	// Pass alternative names as IP addresses to the EKS cluster signer
+	ActivateEKSWorkaroundForSAN bool `json:"activateEKSWorkaroundForSAN,omitempty"`
+}
+
+// BasicAuthRequest specifies the details for generating a basic-auth secret
+type BasicAuthRequest struct {
+	// The username, a random one is generated if empty
+	// +optional
+	Username string `json:"username"`
+}
+
+// ImageCredentialsRequest specifies the details for the image credentials
+type ImageCredentialsRequest struct {
+	// Reference to the secret containing the username
+	// +optional
+	Username SecretReference `json:"username"`
+	// Reference to the secret containing the password
+	// +optional
+	Password SecretReference `json:"password"`
+	// The docker registry
+	Registry string `json:"registry"`
+	// The email of the registry user
+	// +optional
+	Email string `json:"email"`
+}
+
+// TemplatedConfigRequest defines the type of the template engine, a map of templates, one
+// per key and the variables for the templates.
+type TemplatedConfigRequest struct {
+	// Type of template being used (helm)
+	Type string `json:"type,omitempty"`
+	// Templates to render, one per key of the generated secret
+	Templates map[string]string `json:"templates,omitempty"`
+	// Template values to interpolate in the generated secret
+	Values map[string]SecretReference `json:"values,omitempty"`
+}
+
+// PluginRequest defines the parameters and the referenced secret values
+// passed to the exec plugin of a custom secret type
+type PluginRequest struct {
+	// Parameters passed to the plugin
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Values of referenced secret keys passed to the plugin
+	Values map[string]SecretReference `json:"values,omitempty"`
+}
+
+// Request specifies details for the secret generation
+type Request struct {
+	// BasicAuth generates a password for the given username
+	// +optional
+	BasicAuthRequest BasicAuthRequest `json:"basicAuth"`
+	// Certificate generates a certificate and its private key
+	// +optional
+	CertificateRequest CertificateRequest `json:"certificate"`
+	// ImageCredentials generates a docker config json from the referenced
+	// secrets
+	// +optional
+	ImageCredentialsRequest ImageCredentialsRequest `json:"imageCredentials"`
+	// TemplatedConfig renders the template map into the generated secret
+	// +optional
+	TemplatedConfigRequest TemplatedConfigRequest `json:"templatedConfig"`
+	// Plugin passes parameters and secret values to the exec plugin of a
+	// custom type
+	PluginRequest PluginRequest `json:"plugin,omitempty"`
+}
+
+// Copy defines the destination of a copied generated secret and how its
+// keys, type and metadata are transformed
+type Copy struct {
+	// The name of the copied secret
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// The namespace of the copied secret
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Namespace string `json:"namespace"`
+	// Name of a secret in the namespace of the quarks secret, which holds
+	// the kubeconfig of a remote cluster to copy to, in the 'kubeconfig' key
+	Cluster string `json:"cluster,omitempty"`
+
+	// Only these keys of the generated secret are copied, all if empty
+	IncludeKeys []string `json:"includeKeys,omitempty"`
+	// These keys of the generated secret are never copied
+	ExcludeKeys []string `json:"excludeKeys,omitempty"`
+	// Renames keys in the copy, from the key in the generated secret to the
+	// key in the copy
+	RenameKeys map[string]string `json:"renameKeys,omitempty"`
+	// Type of the copied secret, defaults to the type of the generated secret
+	Type corev1.SecretType `json:"type,omitempty"`
+	// Labels added to the copied secret
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations added to the copied secret
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+func (c *Copy) String() string {
+	if c.Cluster != "" {
+		return fmt.Sprintf("%s:%s/%s", c.Cluster, c.Namespace, c.Name)
+	}
+	return fmt.Sprintf("%s/%s", c.Namespace, c.Name)
+}
+
+// CopySelector selects the namespaces, which receive a copy of the generated
+// secret, by label. Copies need a QuarksSecretCopyPolicy or a placeholder in
+// the target namespace.
+type CopySelector struct {
+	// Label selector for the target namespaces
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
+	// Name of the copies, defaults to the secret name
+	Name string `json:"name,omitempty"`
+
+	// Only these keys of the generated secret are copied, all if empty
+	IncludeKeys []string `json:"includeKeys,omitempty"`
+	// These keys of the generated secret are never copied
+	ExcludeKeys []string `json:"excludeKeys,omitempty"`
+	// Renames keys in the copy, from the key in the generated secret to the
+	// key in the copy
+	RenameKeys map[string]string `json:"renameKeys,omitempty"`
+	// Type of the copied secret, defaults to the type of the generated secret
+	Type corev1.SecretType `json:"type,omitempty"`
+	// Labels added to the copied secret
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations added to the copied secret
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// PublicOutput defines the config maps, which receive the public parts of
+// the generated secret, e.g. the CA certificate or the public key
+type PublicOutput struct {
+	// Name of the config maps, defaults to the secret name
+	Name string `json:"name,omitempty"`
+	// Namespaces of the config maps, defaults to the namespace of the
+	// quarks secret
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// RestartOnChange selects the deployments and stateful sets in the namespace
+// of the quarks secret, which are restarted when the generated secret changes
+type RestartOnChange struct {
+	// Label selector for the workloads
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Restarts the workloads, which mount the generated secret or use it
+	// in environment variables
+	AutoDiscover bool `json:"autoDiscover,omitempty"`
+}
+
+// DeletionPolicy defines what happens to the generated secret and its
+// copies, when the quarks secret is deleted
+// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy = string
+
+// Valid values for deletion policies
+const (
+	// DeletionPolicyDelete deletes the generated secret and its copies
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the generated secret and deletes its copies
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan keeps the generated secret and its copies, the
+	// copies are no longer updated
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// DriftPolicy defines what happens, when the generated secret is deleted or
+// its data is changed outside of the operator
+// +kubebuilder:validation:Enum=Restore;Report;Ignore
+type DriftPolicy = string
+
+// Valid values for drift policies
+const (
+	// DriftPolicyRestore restores the last version of the generated secret.
+	// A deleted secret is regenerated, if there is no history, which is
+	// reported by the drift condition and not as a rotation.
+	DriftPolicyRestore DriftPolicy = "Restore"
+	// DriftPolicyReport reports the drift in the status
+	DriftPolicyReport DriftPolicy = "Report"
+	// DriftPolicyIgnore ignores changes to the generated secret
+	DriftPolicyIgnore DriftPolicy = "Ignore"
+)
+
+// Reasons for drift of the generated secret
+const (
+	DriftReasonDeleted  = "Deleted"
+	DriftReasonModified = "Modified"
+)
+
+// ExistingSecretPolicy defines what happens, when a secret with the name of
+// the generated secret exists, but was not generated by the operator
+// +kubebuilder:validation:Enum=Skip;Adopt;Validate
+type ExistingSecretPolicy = string
+
+// Valid values for existing secret policies
+const (
+	// ExistingSecretPolicySkip leaves the existing secret alone
+	ExistingSecretPolicySkip ExistingSecretPolicy = "Skip"
+	// ExistingSecretPolicyAdopt takes ownership of the existing secret, it
+	// is regenerated on the next rotation
+	ExistingSecretPolicyAdopt ExistingSecretPolicy = "Adopt"
+	// ExistingSecretPolicyValidate leaves the existing secret alone, but
+	// reports if it doesn't match the spec
+	ExistingSecretPolicyValidate ExistingSecretPolicy = "Validate"
+)
+
+// QuarksSecretConditionType is the type of a quarks secret condition
+type QuarksSecretConditionType = string
+
+// Valid values for condition types
+const (
+	// ConditionExistingSecret reports how a secret, which exists but was not
+	// generated, is handled
+	ConditionExistingSecret QuarksSecretConditionType = "ExistingSecret"
+	// ConditionUnused reports if the generated secret and its copies have
+	// no consumers for longer than spec.unusedAfter
+	ConditionUnused QuarksSecretConditionType = "Unused"
+	// ConditionExpiringSoon reports if the generated certificate expires
+	// within the window of the notification configs
+	ConditionExpiringSoon QuarksSecretConditionType = "ExpiringSoon"
+	// ConditionFIPSCompliant reports in FIPS mode if the request only uses
+	// FIPS 140 approved algorithms and key sizes
+	ConditionFIPSCompliant QuarksSecretConditionType = "FIPSCompliant"
+	// ConditionDrift reports how the drift policy resolved a generated
+	// secret, which was deleted or changed outside of the operator
+	ConditionDrift QuarksSecretConditionType = "Drift"
+	// ConditionRollback reports if the last spec.rollbackTo version was
+	// restored
+	ConditionRollback QuarksSecretConditionType = "Rollback"
+)
+
+// Reasons for the existing secret condition
+const (
+	ExistingSecretReasonSkipped = "Skipped"
+	ExistingSecretReasonAdopted = "Adopted"
+	ExistingSecretReasonValid   = "Valid"
+	ExistingSecretReasonInvalid = "Invalid"
+)
+
+// Reasons for the unused condition
+const (
+	UnusedReasonNoConsumers = "NoConsumers"
+	UnusedReasonConsumed    = "Consumed"
+)
+
+// Reasons for the expiring soon condition
+const (
+	ExpiryReasonExpiring    = "Expiring"
+	ExpiryReasonNotExpiring = "NotExpiring"
+)
+
+// Reasons for the FIPS compliant condition
+const (
+	FIPSReasonApproved    = "Approved"
+	FIPSReasonNotApproved = "NotApproved"
+)
+
+// Reasons for the drift condition
+const (
+	// DriftConditionRestored means the current version was restored from
+	// the history
+	DriftConditionRestored = "Restored"
+	// DriftConditionRegenerated means the deleted secret was regenerated
+	// with new credentials, because its version is not in the history
+	DriftConditionRegenerated = "Regenerated"
+)
+
+// Reasons for the rollback condition
+const (
+	RollbackReasonRestored        = "Restored"
+	RollbackReasonVersionNotFound = "VersionNotFound"
+	RollbackReasonNotGenerated    = "NotGenerated"
+)
+
+// RotationSpec specifies how the generated secret is rotated
+type RotationSpec struct {
+	// Bumping the generation regenerates the secret
+	Generation int64 `json:"generation,omitempty"`
+	// Keep the previous password, private key and certificate in the
+	// generated secret for a grace period, after it is regenerated
+	KeepPrevious bool `json:"keepPrevious,omitempty"`
+	// How long the previous values are kept, e.g. 30m, defaults to one hour
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// QuarksSecretSpec defines the desired state of QuarksSecret
+type QuarksSecretSpec struct {
+	// What kind of secret to generate: password, certificate, tls, ssh,
+	// rsa, basic-auth, dockerconfigjson, copy, templatedconfig or a
+	// <domain>/<name> type generated by a plugin, see PluginTypePattern
+	// +kubebuilder:validation:Pattern=`^(password|certificate|tls|ssh|rsa|basic-auth|dockerconfigjson|copy|templatedconfig|[a-z0-9]([-a-z0-9.]*[a-z0-9])?/[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`
+	Type SecretType `json:"type"`
+	// Details for the secret generation, depending on the type
+	// +optional
+	Request Request `json:"request"`
+	// Rotation of the generated secret
+	Rotation RotationSpec `json:"rotation,omitempty"`
+	// The name of the generated secret
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+	// A list of namespaced names where to copy generated secrets
+	Copies []Copy `json:"copies,omitempty"`
+	// Copies the generated secret to all namespaces matching the selector
+	CopySelector *CopySelector `json:"copySelector,omitempty"`
+	// Labels added to the generated secret
+	SecretLabels map[string]string `json:"secretLabels,omitempty"`
+	// Annotations added to the generated secret
+	SecretAnnotations map[string]string `json:"secretAnnotations,omitempty"`
+	// Number of generated versions kept in history secrets, none if zero
+	HistoryLimit int `json:"historyLimit,omitempty"`
+	// Restores this version from the history into the generated secret
+	// and its copies. Changing the rollback annotation restores it again.
+	RollbackTo *int64 `json:"rollbackTo,omitempty"`
+	// What happens to the generated secret and its copies, when the quarks
+	// secret is deleted, defaults to Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// What happens, when the generated secret is deleted or changed outside
+	// of the operator, defaults to Restore
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+	// What happens, when a secret with the secret name exists, but was not
+	// generated, defaults to Skip
+	ExistingSecretPolicy ExistingSecretPolicy `json:"existingSecretPolicy,omitempty"`
+	// Writes the public parts of the generated secret to config maps
+	PublicOutput *PublicOutput `json:"publicOutput,omitempty"`
+	// Restarts deployments and stateful sets, when the generated secret
+	// changes
+	RestartOnChange *RestartOnChange `json:"restartOnChange,omitempty"`
+	// How long the generated secret and its copies may have no consumers,
+	// before the quarks secret is flagged as unused, defaults to 30 days
+	UnusedAfter *metav1.Duration `json:"unusedAfter,omitempty"`
+	// Name of the generator backend, which generates the secret, defaults
+	// to the generator of the operator
+	GeneratorRef string `json:"generatorRef,omitempty"`
+}
+
+// QuarksSecretStatus defines the observed state of QuarksSecret
+type QuarksSecretStatus struct {
+	// Timestamp for the last reconcile
+	LastReconcile *metav1.Time `json:"lastReconcile,omitempty"`
+	// Indicates if the secret has already been generated
+	Generated *bool `json:"generated,omitempty"`
+	// Indicates if the copy secrets have been updated
+	Copied *bool `json:"copied,omitempty"`
+	// The last rotation of the generated secret
+	Rotation *RotationStatus `json:"rotation,omitempty"`
+	// The version of the generated secret, counting up with every change
+	Version int64 `json:"version,omitempty"`
+	// The last rollback of the generated secret
+	Rollback *RollbackStatus `json:"rollback,omitempty"`
+	// The copies written by the operator
+	Copies []CopyStatus `json:"copies,omitempty"`
+	// Reports changes to the generated secret outside of the operator
+	Drift *DriftStatus `json:"drift,omitempty"`
+	// Conditions of the quarks secret, e.g. the validation of an existing
+	// secret
+	Conditions []QuarksSecretCondition `json:"conditions,omitempty"`
+	// The config maps written with the public parts of the generated secret
+	PublicOutputs []PublicOutputStatus `json:"publicOutputs,omitempty"`
+	// The content hash of the generated secret, which the workloads were
+	// last restarted for
+	RestartChecksum string `json:"restartChecksum,omitempty"`
+	// The pods, deployments and stateful sets using the generated secret or
+	// its copies
+	Consumers []ConsumerStatus `json:"consumers,omitempty"`
+	// Since when the generated secret and its copies have no consumers
+	UnusedSince *metav1.Time `json:"unusedSince,omitempty"`
+}
+
+// QuarksSecretCondition describes the state of a quarks secret
+type QuarksSecretCondition struct {
+	Type   QuarksSecretConditionType `json:"type"`
+	Status corev1.ConditionStatus    `json:"status"`
+	// Machine readable reason for the condition
+	Reason string `json:"reason,omitempty"`
+	// Human readable details
+	Message string `json:"message,omitempty"`
+	// Timestamp of the last change of the status
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// DriftStatus reports a generated secret, which was deleted or changed
+// outside of the operator
+type DriftStatus struct {
+	// Deleted or Modified
+	Reason string `json:"reason"`
+	// Timestamp of the detection
+	DetectedAt *metav1.Time `json:"detectedAt,omitempty"`
+}
+
+// CopyState is the state of a copy of the generated secret
+// +kubebuilder:validation:Enum=Synced;SkippedNoPlaceholder;SkippedWrongAnnotation;Error
+type CopyState = string
+
+// Valid values for copy states
+const (
+	// CopyStateSynced means the copy was written
+	CopyStateSynced CopyState = "Synced"
+	// CopyStateSkippedNoPlaceholder means the target namespace has no
+	// placeholder and no copy policy allows the copy
+	CopyStateSkippedNoPlaceholder CopyState = "SkippedNoPlaceholder"
+	// CopyStateSkippedWrongAnnotation means the placeholder in the target
+	// namespace is not a copy of the quarks secret
+	CopyStateSkippedWrongAnnotation CopyState = "SkippedWrongAnnotation"
+	// CopyStateError means writing the copy failed
+	CopyStateError CopyState = "Error"
+)
+
+// CopyStatus records a copy of the generated secret
+type CopyStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Name of the kubeconfig secret of the remote cluster, empty for copies
+	// in the same cluster
+	Cluster string `json:"cluster,omitempty"`
+	// State of the copy
+	State CopyState `json:"state,omitempty"`
+	// Why the copy was skipped or failed
+	Message string `json:"message,omitempty"`
+	// The last time the copy was written with new content
+	LastSync *metav1.Time `json:"lastSync,omitempty"`
+	// Hash of the data written to the copy
+	ContentHash string `json:"contentHash,omitempty"`
+}
+
+// PublicOutputStatus records a config map with the public parts of the
+// generated secret
+type PublicOutputStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ConsumerStatus records a pod, deployment or stateful set, which uses the
+// generated secret or one of its copies
+type ConsumerStatus struct {
+	// Pod, Deployment or StatefulSet
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Name of the used secret
+	Secret string `json:"secret"`
+}
+
+// RotationStatus records which rotation request the generated secret reflects
+type RotationStatus struct {
+	// The spec.rotation.generation of the generated secret
+	Generation int64 `json:"generation,omitempty"`
+	// The value of the rotate annotation of the generated secret
+	Annotation string `json:"annotation,omitempty"`
+	// Timestamp of the last rotation
+	LastRotation *metav1.Time `json:"lastRotation,omitempty"`
+	// A rotation config or policy requested the rotation of the generated
+	// secret, which has not been written yet
+	Requested bool `json:"requested,omitempty"`
+}
+
+// RollbackStatus records the last rollback of the generated secret. The
+// rollback condition reports if the version was restored.
+type RollbackStatus struct {
+	// The spec.rollbackTo version of the last rollback
+	Version int64 `json:"version"`
+	// The value of the rollback annotation of the last rollback
+	Annotation string `json:"annotation,omitempty"`
+	// Timestamp of the rollback
+	Time *metav1.Time `json:"time,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecret is the Schema for the QuarksSecrets API
+// +k8s:openapi-gen=true
+type QuarksSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QuarksSecretSpec   `json:"spec,omitempty"`
+	Status QuarksSecretStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// QuarksSecretList contains a list of QuarksSecret
+type QuarksSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuarksSecret `json:"items"`
+}
+
+// GetNamespacedName returns the resource name with its namespace
+func (qs *QuarksSecret) GetNamespacedName() string {
+	return fmt.Sprintf("%s/%s", qs.Namespace, qs.Name)
+}
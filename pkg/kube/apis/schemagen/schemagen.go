@@ -0,0 +1,443 @@
+// Package schemagen generates the CRD validation schemas from the Go types of
+// an API package.
+//
+// Every type marked with +k8s:openapi-gen=true is a root type. Its schema
+// describes all fields by their json names, the doc comment of a field is its
+// description. Fields without omitempty are required, unless they are marked
+// with +optional. The schema is refined by markers in the doc comments of
+// fields and types:
+//
+//	+optional
+//	+nullable
+//	+kubebuilder:validation:Enum=a;b
+//	+kubebuilder:validation:Pattern=`^[a-z]+$`
+//	+kubebuilder:validation:MinLength=1
+//	+kubebuilder:validation:MaxLength=63
+//	+kubebuilder:validation:MinItems=1
+//
+// Validation markers prefixed with items: apply to the items of an array.
+package schemagen
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+const (
+	rootMarker       = "+k8s:openapi-gen=true"
+	optionalMarker   = "+optional"
+	nullableMarker   = "+nullable"
+	validationMarker = "+kubebuilder:validation:"
+	itemsPrefix      = "items:"
+)
+
+var (
+	stringMapSchema = extv1.JSONSchemaProps{
+		Type: "object",
+		AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+			Allows: true,
+			Schema: &extv1.JSONSchemaProps{
+				Type: "string",
+			},
+		},
+	}
+
+	// externalSchemas are the schemas of the types from other packages,
+	// by import path and type name
+	externalSchemas = map[string]extv1.JSONSchemaProps{
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Time":     {Type: "string"},
+		"k8s.io/apimachinery/pkg/apis/meta/v1.Duration": {Type: "string"},
+		"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector": {
+			Type: "object",
+			Properties: map[string]extv1.JSONSchemaProps{
+				"matchLabels": stringMapSchema,
+				"matchExpressions": {
+					Type: "array",
+					Items: &extv1.JSONSchemaPropsOrArray{
+						Schema: &extv1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]extv1.JSONSchemaProps{
+								"key": {
+									Type: "string",
+								},
+								"operator": {
+									Type: "string",
+								},
+								"values": {
+									Type: "array",
+									Items: &extv1.JSONSchemaPropsOrArray{
+										Schema: &extv1.JSONSchemaProps{
+											Type: "string",
+										},
+									},
+								},
+							},
+							Required: []string{
+								"key",
+								"operator",
+							},
+						},
+					},
+				},
+			},
+		},
+		"k8s.io/api/core/v1.SecretType":            {Type: "string"},
+		"k8s.io/api/core/v1.ConditionStatus":       {Type: "string"},
+		"k8s.io/api/certificates/v1beta1.KeyUsage": {Type: "string"},
+	}
+
+	// skippedTypes are validated by the API server, not by the schema
+	skippedTypes = map[string]bool{
+		"k8s.io/apimachinery/pkg/apis/meta/v1.TypeMeta":   true,
+		"k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta": true,
+	}
+
+	basicTypes = map[string]string{
+		"string":  "string",
+		"bool":    "boolean",
+		"int":     "integer",
+		"int32":   "integer",
+		"int64":   "integer",
+		"float64": "number",
+	}
+)
+
+// typeDecl is a type declared in the API package
+type typeDecl struct {
+	spec    *ast.TypeSpec
+	doc     *ast.CommentGroup
+	imports map[string]string
+}
+
+// Schemas parses the Go package in dir and returns the validation schemas
+// of its root types, by type name
+func Schemas(dir string) (map[string]*extv1.JSONSchemaProps, error) {
+	_, schemas, err := generate(dir)
+	return schemas, err
+}
+
+// Generate writes the validation schemas of the Go package in dir to
+// zz_generated.schema.go
+func Generate(dir string) error {
+	pkg, schemas, err := generate(dir)
+	if err != nil {
+		return err
+	}
+
+	src, err := Source(pkg, schemas)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, "zz_generated.schema.go"), src, 0644)
+}
+
+// generate returns the package name and the validation schemas of the Go
+// package in dir
+func generate(dir string) (string, map[string]*extv1.JSONSchemaProps, error) {
+	pkg, types, err := parse(dir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	schemas := map[string]*extv1.JSONSchemaProps{}
+	for name, decl := range types {
+		if !hasMarker(decl.doc, rootMarker) {
+			continue
+		}
+		schema, err := types.schema(decl.imports, decl.spec.Type, nil)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "generating schema of '%s'", name)
+		}
+		schemas[name] = &schema
+	}
+
+	return pkg, schemas, nil
+}
+
+// parse returns the package name and the type declarations of the Go
+// package in dir, generated files and tests are skipped
+func parse(dir string) (string, declarations, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasPrefix(info.Name(), "zz_generated") && !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "parsing '%s'", dir)
+	}
+	if len(pkgs) != 1 {
+		return "", nil, errors.Errorf("expected one package in '%s', found %d", dir, len(pkgs))
+	}
+
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+
+	types := declarations{}
+	for _, file := range pkg.Files {
+		imports := map[string]string{}
+		for _, spec := range file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			name := filepath.Base(path)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			imports[name] = path
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+				if doc == nil {
+					doc = gen.Doc
+				}
+				types[typeSpec.Name.Name] = typeDecl{spec: typeSpec, doc: doc, imports: imports}
+			}
+		}
+	}
+
+	return pkg.Name, types, nil
+}
+
+// declarations are the types of the API package by name
+type declarations map[string]typeDecl
+
+// schema returns the schema of the type expression, refined by the markers
+func (d declarations) schema(imports map[string]string, expr ast.Expr, doc *ast.CommentGroup) (extv1.JSONSchemaProps, error) {
+	var schema extv1.JSONSchemaProps
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if basic, ok := basicTypes[t.Name]; ok {
+			schema = extv1.JSONSchemaProps{Type: basic}
+			break
+		}
+		decl, ok := d[t.Name]
+		if !ok {
+			return schema, errors.Errorf("unknown type '%s'", t.Name)
+		}
+		s, err := d.schema(decl.imports, decl.spec.Type, decl.doc)
+		if err != nil {
+			return schema, errors.Wrapf(err, "type '%s'", t.Name)
+		}
+		schema = s
+	case *ast.StarExpr:
+		return d.schema(imports, t.X, doc)
+	case *ast.ArrayType:
+		items, err := d.schema(imports, t.Elt, nil)
+		if err != nil {
+			return schema, err
+		}
+		schema = extv1.JSONSchemaProps{
+			Type:  "array",
+			Items: &extv1.JSONSchemaPropsOrArray{Schema: &items},
+		}
+	case *ast.MapType:
+		if key, ok := t.Key.(*ast.Ident); !ok || key.Name != "string" {
+			return schema, errors.New("map keys must be strings")
+		}
+		values, err := d.schema(imports, t.Value, nil)
+		if err != nil {
+			return schema, err
+		}
+		schema = extv1.JSONSchemaProps{
+			Type:                 "object",
+			AdditionalProperties: &extv1.JSONSchemaPropsOrBool{Allows: true, Schema: &values},
+		}
+	case *ast.SelectorExpr:
+		name := qualifiedName(imports, t)
+		external, ok := externalSchemas[name]
+		if !ok {
+			return schema, errors.Errorf("no schema for type '%s'", name)
+		}
+		schema = *external.DeepCopy()
+	case *ast.StructType:
+		s, err := d.structSchema(imports, t)
+		if err != nil {
+			return schema, err
+		}
+		schema = s
+	default:
+		return schema, errors.Errorf("unsupported type expression %T", expr)
+	}
+
+	err := applyMarkers(&schema, doc)
+	return schema, err
+}
+
+// structSchema returns the schema of an object with the fields of the struct
+func (d declarations) structSchema(imports map[string]string, structType *ast.StructType) (extv1.JSONSchemaProps, error) {
+	schema := extv1.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]extv1.JSONSchemaProps{},
+	}
+
+	for _, field := range structType.Fields.List {
+		if selector, ok := field.Type.(*ast.SelectorExpr); ok && skippedTypes[qualifiedName(imports, selector)] {
+			continue
+		}
+		if len(field.Names) == 0 {
+			return schema, errors.New("embedded fields are not supported")
+		}
+
+		name, omitEmpty := field.Names[0].Name, false
+		if field.Tag != nil {
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			if jsonTag, ok := reflect.StructTag(tag).Lookup("json"); ok {
+				parts := strings.Split(jsonTag, ",")
+				if parts[0] == "-" {
+					continue
+				}
+				if parts[0] != "" {
+					name = parts[0]
+				}
+				for _, option := range parts[1:] {
+					omitEmpty = omitEmpty || option == "omitempty"
+				}
+			}
+		}
+
+		property, err := d.schema(imports, field.Type, field.Doc)
+		if err != nil {
+			return schema, errors.Wrapf(err, "field '%s'", name)
+		}
+		property.Description = description(field.Doc)
+		schema.Properties[name] = property
+
+		if !omitEmpty && !hasMarker(field.Doc, optionalMarker) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema, nil
+}
+
+// applyMarkers refines the schema with the markers of the doc comment
+func applyMarkers(schema *extv1.JSONSchemaProps, doc *ast.CommentGroup) error {
+	for _, marker := range markers(doc) {
+		if marker == nullableMarker {
+			schema.Nullable = true
+			continue
+		}
+		if !strings.HasPrefix(marker, validationMarker) {
+			continue
+		}
+
+		target := schema
+		validation := strings.TrimPrefix(marker, validationMarker)
+		if strings.HasPrefix(validation, itemsPrefix) {
+			if schema.Items == nil || schema.Items.Schema == nil {
+				return errors.Errorf("marker '%s' needs an array", marker)
+			}
+			target = schema.Items.Schema
+			validation = strings.TrimPrefix(validation, itemsPrefix)
+		}
+
+		parts := strings.SplitN(validation, "=", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("marker '%s' has no value", marker)
+		}
+		key, value := parts[0], strings.Trim(parts[1], "`")
+
+		switch key {
+		case "Enum":
+			target.Enum = nil
+			for _, v := range strings.Split(value, ";") {
+				raw, err := json.Marshal(v)
+				if err != nil {
+					return errors.Wrapf(err, "marker '%s'", marker)
+				}
+				target.Enum = append(target.Enum, extv1.JSON{Raw: raw})
+			}
+		case "Pattern":
+			target.Pattern = value
+		case "MinLength", "MaxLength", "MinItems":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "marker '%s'", marker)
+			}
+			switch key {
+			case "MinLength":
+				target.MinLength = &n
+			case "MaxLength":
+				target.MaxLength = &n
+			case "MinItems":
+				target.MinItems = &n
+			}
+		default:
+			return errors.Errorf("unknown marker '%s'", marker)
+		}
+	}
+
+	return nil
+}
+
+// qualifiedName returns the import path and name of a type from another
+// package
+func qualifiedName(imports map[string]string, selector *ast.SelectorExpr) string {
+	pkg, ok := selector.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return imports[pkg.Name] + "." + selector.Sel.Name
+}
+
+// description returns the doc comment without markers on a single line
+func description(doc *ast.CommentGroup) string {
+	lines := []string{}
+	for _, line := range commentLines(doc) {
+		if line == "" || strings.HasPrefix(line, "+") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " ")
+}
+
+// markers returns the marker lines of the doc comment
+func markers(doc *ast.CommentGroup) []string {
+	markers := []string{}
+	for _, line := range commentLines(doc) {
+		if strings.HasPrefix(line, "+") {
+			markers = append(markers, line)
+		}
+	}
+	return markers
+}
+
+func hasMarker(doc *ast.CommentGroup, marker string) bool {
+	for _, m := range markers(doc) {
+		if m == marker {
+			return true
+		}
+	}
+	return false
+}
+
+func commentLines(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	lines := []string{}
+	for _, comment := range doc.List {
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(comment.Text, "//")))
+	}
+	return lines
+}
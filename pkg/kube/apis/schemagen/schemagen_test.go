@@ -0,0 +1,83 @@
+package schemagen_test
+
+import (
+	"go/parser"
+	"go/token"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/apis/schemagen"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+)
+
+var _ = Describe("Schemas", func() {
+	It("generates the schemas of the root types", func() {
+		schemas, err := schemagen.Schemas("testdata/example")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schemas).To(HaveLen(1))
+
+		spec := schemas["Example"].Properties["spec"]
+		Expect(schemas["Example"].Properties).To(HaveLen(1))
+		Expect(spec.Required).To(Equal([]string{"items", "Zone"}))
+
+		items := spec.Properties["items"]
+		Expect(items.Description).To(Equal("Items of the example"))
+		Expect(items.Type).To(Equal("array"))
+		Expect(items.MinItems).To(Equal(pointers.Int64(1)))
+		Expect(*items.Items.Schema).To(Equal(extv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]extv1.JSONSchemaProps{
+				"name": {
+					Description: "The name of the item",
+					Type:        "string",
+					MinLength:   pointers.Int64(1),
+				},
+				"color": {
+					Description: "The color of the item",
+					Type:        "string",
+					Enum:        []extv1.JSON{{Raw: []byte(`"red"`)}, {Raw: []byte(`"green"`)}},
+				},
+				"created": {
+					Type:     "string",
+					Nullable: true,
+				},
+			},
+			Required: []string{"name"},
+		}))
+
+		Expect(spec.Properties["days"].Enum).To(BeEmpty())
+		Expect(spec.Properties["days"].Items.Schema.Enum).To(Equal([]extv1.JSON{{Raw: []byte(`"Sunday"`)}, {Raw: []byte(`"Monday"`)}}))
+		Expect(spec.Properties["labels"].AdditionalProperties.Schema.Type).To(Equal("string"))
+		Expect(spec.Properties["selector"].Properties).To(HaveKey("matchExpressions"))
+		Expect(spec.Properties["Zone"].Pattern).To(Equal("^[a-z]+$"))
+		Expect(spec.Properties["Zone"].MaxLength).To(Equal(pointers.Int64(10)))
+		Expect(spec.Properties["enabled"].Type).To(Equal("boolean"))
+		Expect(spec.Properties["size"].Type).To(Equal("integer"))
+		Expect(spec.Properties["ratio"].Type).To(Equal("number"))
+	})
+
+	It("fails for unknown markers", func() {
+		_, err := schemagen.Schemas("testdata/unknownmarker")
+		Expect(err).To(MatchError(ContainSubstring("unknown marker '+kubebuilder:validation:Minimum=1'")))
+	})
+})
+
+var _ = Describe("Source", func() {
+	It("declares a validation variable per schema", func() {
+		schemas, err := schemagen.Schemas("testdata/example")
+		Expect(err).ToNot(HaveOccurred())
+
+		src, err := schemagen.Source("example", schemas)
+		Expect(err).ToNot(HaveOccurred())
+
+		file, err := parser.ParseFile(token.NewFileSet(), "zz_generated.schema.go", src, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file.Name.Name).To(Equal("example"))
+		Expect(file.Scope.Lookup("ExampleValidation")).ToNot(BeNil())
+		Expect(string(src)).To(MatchRegexp(`MinLength:\s+pointers.Int64\(1\)`))
+		Expect(string(src)).To(ContainSubstring("`^[a-z]+$`"))
+	})
+})
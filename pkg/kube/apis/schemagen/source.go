@@ -0,0 +1,135 @@
+package schemagen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+const header = `// Code generated by schemagen. DO NOT EDIT.
+// Edit the doc comments and markers in types.go, then run "make generate".
+
+package %s
+
+import (
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+%s)
+`
+
+// Source returns the Go source of a file in package pkg, which declares the
+// <type>Validation variable for each schema
+func Source(pkg string, schemas map[string]*extv1.JSONSchemaProps) ([]byte, error) {
+	names := []string{}
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	body := &bytes.Buffer{}
+	for _, name := range names {
+		fmt.Fprintf(body, "\n// %sValidation is the validation schema for %s\n", name, name)
+		fmt.Fprintf(body, "var %sValidation = extv1.CustomResourceValidation{\nOpenAPIV3Schema: &", name)
+		writeSchema(body, schemas[name], false)
+		body.WriteString(",\n}\n")
+	}
+
+	imports := ""
+	if strings.Contains(body.String(), "pointers.") {
+		imports = "\n\t\"code.cloudfoundry.org/quarks-utils/pkg/pointers\"\n"
+	}
+
+	src := &bytes.Buffer{}
+	fmt.Fprintf(src, header, pkg, imports)
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "formatting generated schemas")
+	}
+	return formatted, nil
+}
+
+// writeSchema writes the schema as composite literal, the type is elided
+// for map values
+func writeSchema(w *bytes.Buffer, schema *extv1.JSONSchemaProps, elideType bool) {
+	if !elideType {
+		w.WriteString("extv1.JSONSchemaProps")
+	}
+	w.WriteString("{\n")
+
+	if schema.Description != "" {
+		fmt.Fprintf(w, "Description: %s,\n", strconv.Quote(schema.Description))
+	}
+	fmt.Fprintf(w, "Type: %s,\n", strconv.Quote(schema.Type))
+	if schema.Nullable {
+		w.WriteString("Nullable: true,\n")
+	}
+	if schema.Pattern != "" {
+		fmt.Fprintf(w, "Pattern: %s,\n", quote(schema.Pattern))
+	}
+	if schema.MinLength != nil {
+		fmt.Fprintf(w, "MinLength: pointers.Int64(%d),\n", *schema.MinLength)
+	}
+	if schema.MaxLength != nil {
+		fmt.Fprintf(w, "MaxLength: pointers.Int64(%d),\n", *schema.MaxLength)
+	}
+	if schema.MinItems != nil {
+		fmt.Fprintf(w, "MinItems: pointers.Int64(%d),\n", *schema.MinItems)
+	}
+	if len(schema.Enum) > 0 {
+		w.WriteString("Enum: []extv1.JSON{\n")
+		for _, value := range schema.Enum {
+			fmt.Fprintf(w, "{Raw: []byte(%s)},\n", quote(string(value.Raw)))
+		}
+		w.WriteString("},\n")
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		w.WriteString("Items: &extv1.JSONSchemaPropsOrArray{\nSchema: &")
+		writeSchema(w, schema.Items.Schema, false)
+		w.WriteString(",\n},\n")
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		w.WriteString("AdditionalProperties: &extv1.JSONSchemaPropsOrBool{\nAllows: true,\nSchema: &")
+		writeSchema(w, schema.AdditionalProperties.Schema, false)
+		w.WriteString(",\n},\n")
+	}
+	if len(schema.Properties) > 0 {
+		names := []string{}
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		w.WriteString("Properties: map[string]extv1.JSONSchemaProps{\n")
+		for _, name := range names {
+			property := schema.Properties[name]
+			fmt.Fprintf(w, "%s: ", strconv.Quote(name))
+			writeSchema(w, &property, true)
+			w.WriteString(",\n")
+		}
+		w.WriteString("},\n")
+	}
+	if len(schema.Required) > 0 {
+		w.WriteString("Required: []string{\n")
+		for _, name := range schema.Required {
+			fmt.Fprintf(w, "%s,\n", strconv.Quote(name))
+		}
+		w.WriteString("},\n")
+	}
+
+	w.WriteString("}")
+}
+
+// quote returns a raw string literal, if the string contains no backquotes
+func quote(s string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
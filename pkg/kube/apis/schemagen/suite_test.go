@@ -0,0 +1,13 @@
+package schemagen_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSchemagen(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Schemagen Suite")
+}
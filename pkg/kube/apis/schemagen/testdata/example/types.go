@@ -0,0 +1,57 @@
+package example
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Color is a color
+// +kubebuilder:validation:Enum=red;green
+type Color = string
+
+// Item is an item of the example
+type Item struct {
+	// The name of the item
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// The color of the item
+	Color Color `json:"color,omitempty"`
+	// +optional
+	// +nullable
+	Created  *metav1.Time `json:"created"`
+	Internal string       `json:"-"`
+}
+
+// ExampleSpec is the spec of the example
+type ExampleSpec struct {
+	// Items of the example
+	// +kubebuilder:validation:MinItems=1
+	Items []Item `json:"items"`
+	// Days of the example
+	// +kubebuilder:validation:items:Enum=Sunday;Monday
+	Days []string `json:"days,omitempty"`
+	// Labels of the example
+	Labels map[string]string `json:"labels,omitempty"`
+	// Selects the items
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// The zone of the example
+	// +kubebuilder:validation:Pattern=`^[a-z]+$`
+	// +kubebuilder:validation:MaxLength=10
+	Zone    string
+	Enabled bool    `json:"enabled,omitempty"`
+	Size    int64   `json:"size,omitempty"`
+	Ratio   float64 `json:"ratio,omitempty"`
+}
+
+// Example is an example root type
+// +k8s:openapi-gen=true
+type Example struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ExampleSpec `json:"spec,omitempty"`
+}
+
+// Other is not a root type
+type Other struct {
+	Name string `json:"name"`
+}
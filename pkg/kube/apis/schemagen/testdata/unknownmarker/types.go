@@ -0,0 +1,8 @@
+package unknownmarker
+
+// Example is an example root type
+// +k8s:openapi-gen=true
+type Example struct {
+	// +kubebuilder:validation:Minimum=1
+	Size int `json:"size"`
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 
 	quarkssecretv1alpha1 "code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/typed/quarkssecret/v1alpha1"
+	quarkssecretv1beta1 "code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/typed/quarkssecret/v1beta1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -19,6 +20,7 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	QuarkssecretV1alpha1() quarkssecretv1alpha1.QuarkssecretV1alpha1Interface
+	QuarkssecretV1beta1() quarkssecretv1beta1.QuarkssecretV1beta1Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -26,6 +28,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	quarkssecretV1alpha1 *quarkssecretv1alpha1.QuarkssecretV1alpha1Client
+	quarkssecretV1beta1  *quarkssecretv1beta1.QuarkssecretV1beta1Client
 }
 
 // QuarkssecretV1alpha1 retrieves the QuarkssecretV1alpha1Client
@@ -33,6 +36,11 @@ func (c *Clientset) QuarkssecretV1alpha1() quarkssecretv1alpha1.QuarkssecretV1al
 	return c.quarkssecretV1alpha1
 }
 
+// QuarkssecretV1beta1 retrieves the QuarkssecretV1beta1Client
+func (c *Clientset) QuarkssecretV1beta1() quarkssecretv1beta1.QuarkssecretV1beta1Interface {
+	return c.quarkssecretV1beta1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -58,6 +66,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.quarkssecretV1beta1, err = quarkssecretv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -71,6 +83,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.quarkssecretV1alpha1 = quarkssecretv1alpha1.NewForConfigOrDie(c)
+	cs.quarkssecretV1beta1 = quarkssecretv1beta1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -80,6 +93,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.quarkssecretV1alpha1 = quarkssecretv1alpha1.New(c)
+	cs.quarkssecretV1beta1 = quarkssecretv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -11,6 +11,8 @@ import (
 	clientset "code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned"
 	quarkssecretv1alpha1 "code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/typed/quarkssecret/v1alpha1"
 	fakequarkssecretv1alpha1 "code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/typed/quarkssecret/v1alpha1/fake"
+	quarkssecretv1beta1 "code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/typed/quarkssecret/v1beta1"
+	fakequarkssecretv1beta1 "code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/typed/quarkssecret/v1beta1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -69,3 +71,8 @@ var _ clientset.Interface = &Clientset{}
 func (c *Clientset) QuarkssecretV1alpha1() quarkssecretv1alpha1.QuarkssecretV1alpha1Interface {
 	return &fakequarkssecretv1alpha1.FakeQuarkssecretV1alpha1{Fake: &c.Fake}
 }
+
+// QuarkssecretV1beta1 retrieves the QuarkssecretV1beta1Client
+func (c *Clientset) QuarkssecretV1beta1() quarkssecretv1beta1.QuarkssecretV1beta1Interface {
+	return &fakequarkssecretv1beta1.FakeQuarkssecretV1beta1{Fake: &c.Fake}
+}
@@ -21,6 +21,22 @@ func (c *FakeQuarkssecretV1alpha1) QuarksSecrets(namespace string) v1alpha1.Quar
 	return &FakeQuarksSecrets{c, namespace}
 }
 
+func (c *FakeQuarkssecretV1alpha1) QuarksSecretCopyPolicies() v1alpha1.QuarksSecretCopyPolicyInterface {
+	return &FakeQuarksSecretCopyPolicies{c}
+}
+
+func (c *FakeQuarkssecretV1alpha1) QuarksSecretDefaultses(namespace string) v1alpha1.QuarksSecretDefaultsInterface {
+	return &FakeQuarksSecretDefaultses{c, namespace}
+}
+
+func (c *FakeQuarkssecretV1alpha1) QuarksSecretNotificationConfigs(namespace string) v1alpha1.QuarksSecretNotificationConfigInterface {
+	return &FakeQuarksSecretNotificationConfigs{c, namespace}
+}
+
+func (c *FakeQuarkssecretV1alpha1) QuarksSecretRotationPolicies(namespace string) v1alpha1.QuarksSecretRotationPolicyInterface {
+	return &FakeQuarksSecretRotationPolicies{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeQuarkssecretV1alpha1) RESTClient() rest.Interface {
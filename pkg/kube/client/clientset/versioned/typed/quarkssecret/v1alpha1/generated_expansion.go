@@ -8,3 +8,11 @@ Don't alter this file, it was generated.
 package v1alpha1
 
 type QuarksSecretExpansion interface{}
+
+type QuarksSecretCopyPolicyExpansion interface{}
+
+type QuarksSecretDefaultsExpansion interface{}
+
+type QuarksSecretNotificationConfigExpansion interface{}
+
+type QuarksSecretRotationPolicyExpansion interface{}
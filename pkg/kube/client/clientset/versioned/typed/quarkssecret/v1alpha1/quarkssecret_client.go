@@ -16,6 +16,10 @@ import (
 type QuarkssecretV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	QuarksSecretsGetter
+	QuarksSecretCopyPoliciesGetter
+	QuarksSecretDefaultsesGetter
+	QuarksSecretNotificationConfigsGetter
+	QuarksSecretRotationPoliciesGetter
 }
 
 // QuarkssecretV1alpha1Client is used to interact with features provided by the quarkssecret group.
@@ -27,6 +31,22 @@ func (c *QuarkssecretV1alpha1Client) QuarksSecrets(namespace string) QuarksSecre
 	return newQuarksSecrets(c, namespace)
 }
 
+func (c *QuarkssecretV1alpha1Client) QuarksSecretCopyPolicies() QuarksSecretCopyPolicyInterface {
+	return newQuarksSecretCopyPolicies(c)
+}
+
+func (c *QuarkssecretV1alpha1Client) QuarksSecretDefaultses(namespace string) QuarksSecretDefaultsInterface {
+	return newQuarksSecretDefaultses(c, namespace)
+}
+
+func (c *QuarkssecretV1alpha1Client) QuarksSecretNotificationConfigs(namespace string) QuarksSecretNotificationConfigInterface {
+	return newQuarksSecretNotificationConfigs(c, namespace)
+}
+
+func (c *QuarkssecretV1alpha1Client) QuarksSecretRotationPolicies(namespace string) QuarksSecretRotationPolicyInterface {
+	return newQuarksSecretRotationPolicies(c, namespace)
+}
+
 // NewForConfig creates a new QuarkssecretV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*QuarkssecretV1alpha1Client, error) {
 	config := *c
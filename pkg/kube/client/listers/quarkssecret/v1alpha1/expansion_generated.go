@@ -14,3 +14,31 @@ type QuarksSecretListerExpansion interface{}
 // QuarksSecretNamespaceListerExpansion allows custom methods to be added to
 // QuarksSecretNamespaceLister.
 type QuarksSecretNamespaceListerExpansion interface{}
+
+// QuarksSecretCopyPolicyListerExpansion allows custom methods to be added to
+// QuarksSecretCopyPolicyLister.
+type QuarksSecretCopyPolicyListerExpansion interface{}
+
+// QuarksSecretDefaultsListerExpansion allows custom methods to be added to
+// QuarksSecretDefaultsLister.
+type QuarksSecretDefaultsListerExpansion interface{}
+
+// QuarksSecretDefaultsNamespaceListerExpansion allows custom methods to be added to
+// QuarksSecretDefaultsNamespaceLister.
+type QuarksSecretDefaultsNamespaceListerExpansion interface{}
+
+// QuarksSecretNotificationConfigListerExpansion allows custom methods to be added to
+// QuarksSecretNotificationConfigLister.
+type QuarksSecretNotificationConfigListerExpansion interface{}
+
+// QuarksSecretNotificationConfigNamespaceListerExpansion allows custom methods to be added to
+// QuarksSecretNotificationConfigNamespaceLister.
+type QuarksSecretNotificationConfigNamespaceListerExpansion interface{}
+
+// QuarksSecretRotationPolicyListerExpansion allows custom methods to be added to
+// QuarksSecretRotationPolicyLister.
+type QuarksSecretRotationPolicyListerExpansion interface{}
+
+// QuarksSecretRotationPolicyNamespaceListerExpansion allows custom methods to be added to
+// QuarksSecretRotationPolicyNamespaceLister.
+type QuarksSecretRotationPolicyNamespaceListerExpansion interface{}
@@ -3,40 +3,73 @@ package controllers
 import (
 	"context"
 
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
 	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	qsv1b1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1beta1"
 	"code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
-	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	wh "code.cloudfoundry.org/quarks-utils/pkg/webhook"
 )
 
-// Theses funcs construct controllers and add them to the controller-runtime
-// manager. The manager will set fields on the controllers and start them, when
-// itself is started.
-var addToManagerFuncs = []func(context.Context, *config.Config, manager.Manager) error{
-	quarkssecret.AddCertificateSigningRequest,
-	quarkssecret.AddCopy,
-	quarkssecret.AddQuarksSecret,
-	quarkssecret.AddSecretRotation,
-	quarkssecret.AddQuarksSecretSecretMeta,
-}
-
 var addToSchemes = runtime.SchemeBuilder{
 	qsv1a1.AddToScheme,
+	qsv1b1.AddToScheme,
 }
 
-// AddToManager adds all Controllers to the Manager
-func AddToManager(ctx context.Context, config *config.Config, m manager.Manager) error {
-	for _, f := range addToManagerFuncs {
-		if err := f(ctx, config, m); err != nil {
-			return err
-		}
-	}
-	return nil
+var validatingHookFuncs = []func(context.Context, quarkssecret.Options) *wh.OperatorWebhook{
+	quarkssecret.NewQuarksSecretValidator,
+}
+
+// AddToManager adds all Controllers configured by the options to the Manager
+func AddToManager(ctx context.Context, options quarkssecret.Options, m manager.Manager) error {
+	return quarkssecret.NewControllers(options).AddToManager(ctx, m)
 }
 
 // AddToScheme adds all Resources to the Scheme
 func AddToScheme(s *runtime.Scheme) error {
 	return addToSchemes.AddToScheme(s)
 }
+
+// AddHooks adds all web hooks to the Manager, using the same options as the
+// controllers
+func AddHooks(ctx context.Context, options quarkssecret.Options, m manager.Manager) error {
+	config := options.Config
+	ctxlog.Infof(ctx, "Setting up webhook server on %s:%d", config.WebhookServerHost, config.WebhookServerPort)
+
+	webhookConfig := wh.NewConfig(m.GetClient(), config, newWebhookGenerator(ctx, options), WebhookConfigPrefix+config.OperatorNamespace)
+
+	hookServer := m.GetWebhookServer()
+	hookServer.CertDir = webhookConfig.CertDir
+
+	validatingWebhooks := []*wh.OperatorWebhook{}
+	for _, f := range validatingHookFuncs {
+		validatingWebhook := f(ctx, options)
+		hookServer.Register(validatingWebhook.Path, validatingWebhook.Webhook)
+		validatingWebhooks = append(validatingWebhooks, validatingWebhook)
+	}
+
+	// The conversion webhook converts between all QuarksSecret API versions,
+	// using the scheme of the manager
+	hookServer.Register(ConversionWebhookPath, &conversion.Webhook{})
+
+	err := webhookConfig.SetupCertificate(ctx, WebhookServiceName)
+	if err != nil {
+		return errors.Wrap(err, "setting up the webhook server certificate")
+	}
+
+	err = createValidationWebhookServerConfig(ctx, m.GetClient(), config, webhookConfig, validatingWebhooks)
+	if err != nil {
+		return errors.Wrap(err, "generating the validating webhook server configuration")
+	}
+
+	err = enableConversionWebhook(ctx, m.GetConfig(), config, webhookConfig.CaCertificate)
+	if err != nil {
+		return errors.Wrap(err, "enabling the conversion webhook")
+	}
+
+	return nil
+}
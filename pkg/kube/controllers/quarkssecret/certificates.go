@@ -55,6 +55,11 @@ func (r *ReconcileQuarksSecret) createCertificateSecret(ctx context.Context, qse
 		return errors.Wrap(err, "generating certificate generation request")
 	}
 
+	generator, err := r.generatorFor(ctx, qsec)
+	if err != nil {
+		return err
+	}
+
 	switch qsec.Spec.Request.CertificateRequest.SignerType {
 	case qsv1a1.ClusterSigner:
 		if qsec.Spec.Type == "tls" {
@@ -72,7 +77,7 @@ func (r *ReconcileQuarksSecret) createCertificateSecret(ctx context.Context, qse
 		}
 
 		ctxlog.Info(ctx, "Generating certificate signing request and its key")
-		csr, key, err := r.generator.GenerateCertificateSigningRequest(generationRequest)
+		csr, key, err := generator.GenerateCertificateSigningRequest(generationRequest)
 		if err != nil {
 			return err
 		}
@@ -91,7 +96,7 @@ func (r *ReconcileQuarksSecret) createCertificateSecret(ctx context.Context, qse
 			},
 		}
 
-		err = r.createSecrets(ctx, qsec, secret)
+		err = r.createSecret(ctx, qsec, secret)
 		if err != nil {
 			return err
 		}
@@ -99,7 +104,7 @@ func (r *ReconcileQuarksSecret) createCertificateSecret(ctx context.Context, qse
 		return r.createCertificateSigningRequest(ctx, qsec, csr)
 	case qsv1a1.LocalSigner:
 		// Generate certificate
-		cert, err := r.generator.GenerateCertificate(qsec.GetName(), generationRequest)
+		cert, err := generator.GenerateCertificate(qsec.GetName(), generationRequest)
 		if err != nil {
 			return err
 		}
@@ -136,9 +141,11 @@ func (r *ReconcileQuarksSecret) createCertificateSecret(ctx context.Context, qse
 
 		if len(generationRequest.CA.Certificate) > 0 {
 			secret.StringData["ca"] = string(generationRequest.CA.Certificate)
+		} else if len(cert.IssuingCA) > 0 {
+			secret.StringData["ca"] = string(cert.IssuingCA)
 		}
 
-		return r.createSecrets(ctx, qsec, secret)
+		return r.createSecret(ctx, qsec, secret)
 	default:
 		return fmt.Errorf("unrecognized signer type: %s", qsec.Spec.Request.CertificateRequest.SignerType)
 	}
@@ -172,7 +179,7 @@ func (r *ReconcileQuarksSecret) generateCertificateGenerationRequest(ctx context
 			err := r.client.Get(ctx, caNamespacedName, caSecret)
 			if err != nil {
 				if apierrors.IsNotFound(err) {
-					return request, newCaNotReadyError("CA secret not found")
+					return request, newNotReadyError("CA secret not found")
 				}
 				return request, errors.Wrap(err, "getting CA secret")
 			}
@@ -188,7 +195,7 @@ func (r *ReconcileQuarksSecret) generateCertificateGenerationRequest(ctx context
 				err = r.client.Get(ctx, caNamespacedName, caSecret)
 				if err != nil {
 					if apierrors.IsNotFound(err) {
-						return request, newCaNotReadyError("CA key secret not found")
+						return request, newNotReadyError("CA key secret not found")
 					}
 					return request, errors.Wrap(err, "getting CA Key secret")
 				}
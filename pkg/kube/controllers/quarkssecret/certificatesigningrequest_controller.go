@@ -15,13 +15,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
-	"code.cloudfoundry.org/quarks-utils/pkg/config"
 	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
 )
 
 // AddCertificateSigningRequest creates a new CertificateSigningRequest controller to watch for new and changed
 // certificate signing request. Reconciliation will approve them and create a secret.
-func AddCertificateSigningRequest(ctx context.Context, config *config.Config, mgr manager.Manager) error {
+func AddCertificateSigningRequest(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
 	ctx = ctxlog.NewContextWithRecorder(ctx, "csr-reconciler", mgr.GetEventRecorderFor("csr-recorder"))
 	certClient, err := certv1client.NewForConfig(mgr.GetConfig())
 	if err != nil {
@@ -30,9 +30,9 @@ func AddCertificateSigningRequest(ctx context.Context, config *config.Config, mg
 	r := NewCertificateSigningRequestReconciler(ctx, config, mgr, certClient, controllerutil.SetControllerReference)
 
 	// Create a new controller
-	c, err := controller.New("certificate-signing-request-controller", mgr, controller.Options{
+	c, err := controller.New(CertificateSigningRequestController, mgr, controller.Options{
 		Reconciler:              r,
-		MaxConcurrentReconciles: config.MaxQuarksSecretWorkers,
+		MaxConcurrentReconciles: options.workers(CertificateSigningRequestController),
 	})
 	if err != nil {
 		return errors.Wrap(err, "Adding certificate signing request controller to manager failed.")
@@ -0,0 +1,84 @@
+package quarkssecret
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+)
+
+// ClusterClientFunc returns a client for the remote cluster of the kubeconfig
+type ClusterClientFunc func(kubeconfig []byte) (crc.Client, error)
+
+// NewClusterClientFunc returns a ClusterClientFunc, which creates clients
+// using the scheme
+func NewClusterClientFunc(scheme *runtime.Scheme) ClusterClientFunc {
+	return func(kubeconfig []byte) (crc.Client, error) {
+		config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid kubeconfig")
+		}
+		return crc.New(config, crc.Options{Scheme: scheme})
+	}
+}
+
+type clusterClient struct {
+	hash   string
+	client crc.Client
+}
+
+// clusterClients caches the clients of remote clusters by kubeconfig
+// secret, a changed kubeconfig creates a new client
+type clusterClients struct {
+	newClient ClusterClientFunc
+	mu        sync.Mutex
+	clients   map[types.NamespacedName]clusterClient
+}
+
+func newClusterClients(ccf ClusterClientFunc) *clusterClients {
+	return &clusterClients{
+		newClient: ccf,
+		clients:   map[types.NamespacedName]clusterClient{},
+	}
+}
+
+// get returns the client for the cluster of the kubeconfig secret, or the
+// local client if the cluster is empty
+func (c *clusterClients) get(ctx context.Context, local crc.Client, namespace string, cluster string) (crc.Client, error) {
+	if cluster == "" {
+		return local, nil
+	}
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: cluster, Namespace: namespace}
+	if err := local.Get(ctx, key, secret); err != nil {
+		return nil, errors.Wrapf(err, "could not get kubeconfig secret '%s'", key)
+	}
+	kubeconfig := secret.Data[qsv1a1.ClusterKubeconfigKey]
+	if len(kubeconfig) == 0 {
+		return nil, errors.Errorf("kubeconfig secret '%s' has no '%s' key", key, qsv1a1.ClusterKubeconfigKey)
+	}
+
+	hash := contentHash(map[string][]byte{qsv1a1.ClusterKubeconfigKey: kubeconfig})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.clients[key]; ok && cached.hash == hash {
+		return cached.client, nil
+	}
+
+	client, err := c.newClient(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create client for cluster '%s'", cluster)
+	}
+	c.clients[key] = clusterClient{hash: hash, client: client}
+	return client, nil
+}
@@ -0,0 +1,122 @@
+package quarkssecret
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// AddConsumers creates a new controller, which records the consumers of
+// generated secrets and their copies
+func AddConsumers(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
+	ctx = ctxlog.NewContextWithRecorder(ctx, "consumers-reconciler", mgr.GetEventRecorderFor("quarks-secret-recorder"))
+	r := NewConsumersReconciler(ctx, config, mgr)
+
+	// Create a new controller
+	c, err := controller.New(ConsumersController, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: options.workers(ConsumersController),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Adding consumers controller to manager failed.")
+	}
+
+	nsPred := newNSPredicate(ctx, mgr.GetClient(), config.MonitoredID)
+
+	// Watch for quarks secrets, the scan is repeated periodically after
+	// the first reconcile
+	p := predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return true },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*qsv1a1.QuarksSecret)
+			o := e.ObjectOld.(*qsv1a1.QuarksSecret)
+
+			if n.Spec.SecretName != o.Spec.SecretName || !reflect.DeepEqual(n.Spec.UnusedAfter, o.Spec.UnusedAfter) ||
+				!reflect.DeepEqual(consumedSecrets(n), consumedSecrets(o)) {
+				ctxlog.Debugf(ctx, "Consumed secrets of QuarksSecret '%s' changed", n.Name)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, options.quarksSecretPredicates(nsPred, p)...)
+	if err != nil {
+		return errors.Wrapf(err, "Watching quarks secrets failed in consumers controller.")
+	}
+
+	// Watch for pods, which start or stop using secrets
+	p = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return len(secretsUsedBy(e.Object.(*corev1.Pod).Spec)) > 0
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return len(secretsUsedBy(e.Object.(*corev1.Pod).Spec)) > 0
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			pod := a.Object.(*corev1.Pod)
+			reconciles := listConsumerReconciles(ctx, mgr.GetClient(), pod)
+			for _, request := range reconciles {
+				ctxlog.NewMappingEvent(a.Object).Debug(ctx, request, "QuarksSecret", a.Meta.GetName(), "pod")
+			}
+			return reconciles
+		}),
+	}, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching pods failed in consumers controller.")
+	}
+
+	return nil
+}
+
+// listConsumerReconciles returns the quarks secrets, which own one of the
+// secrets the pod uses or were copied to one of them
+func listConsumerReconciles(ctx context.Context, client crc.Client, pod *corev1.Pod) []reconcile.Request {
+	result := []reconcile.Request{}
+	seen := map[reconcile.Request]bool{}
+	for _, name := range secretsUsedBy(pod.Spec) {
+		secret := &corev1.Secret{}
+		err := client.Get(ctx, types.NamespacedName{Name: name, Namespace: pod.Namespace}, secret)
+		if err != nil {
+			continue
+		}
+
+		var request reconcile.Request
+		if owner := metav1.GetControllerOf(secret); owner != nil && owner.Kind == "QuarksSecret" {
+			request.NamespacedName = types.NamespacedName{Name: owner.Name, Namespace: secret.Namespace}
+		} else if copyOf := strings.SplitN(secret.Annotations[qsv1a1.AnnotationCopyOf], "/", 2); len(copyOf) == 2 {
+			request.NamespacedName = types.NamespacedName{Name: copyOf[1], Namespace: copyOf[0]}
+		} else {
+			continue
+		}
+
+		if !seen[request] {
+			seen[request] = true
+			result = append(result, request)
+		}
+	}
+	return result
+}
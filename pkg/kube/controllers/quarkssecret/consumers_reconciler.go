@@ -0,0 +1,210 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+const (
+	// consumerScanInterval is the time between two scans for the consumers
+	// of a quarks secret
+	consumerScanInterval = 10 * time.Minute
+	// defaultUnusedAfter is how long a generated secret may have no
+	// consumers, before the quarks secret is flagged as unused
+	defaultUnusedAfter = 30 * 24 * time.Hour
+)
+
+// NewConsumersReconciler returns a new ReconcileConsumers
+func NewConsumersReconciler(ctx context.Context, config *config.Config, mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileConsumers{
+		ctx:    ctx,
+		config: config,
+		client: mgr.GetClient(),
+	}
+}
+
+// ReconcileConsumers records the pods, deployments and stateful sets, which
+// use the generated secret of a quarks secret or its copies
+type ReconcileConsumers struct {
+	ctx    context.Context
+	client client.Client
+	config *config.Config
+}
+
+// Reconcile scans the namespaces of the generated secret and its copies for
+// consumers and flags the quarks secret as unused, if there were none for
+// longer than spec.unusedAfter. The scan is repeated periodically.
+func (r *ReconcileConsumers) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	qsec := &qsv1a1.QuarksSecret{}
+
+	// Set the ctx to be Background, as the top-level context for incoming requests.
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.CtxTimeOut)
+	defer cancel()
+
+	ctxlog.Infof(ctx, "Reconciling consumers of QuarksSecret %s", request.NamespacedName)
+	err := r.client.Get(ctx, request.NamespacedName, qsec)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Info(ctx, "Skip reconcile: quarks secret not found")
+			return reconcile.Result{}, nil
+		}
+		ctxlog.Info(ctx, "Error reading the object")
+		return reconcile.Result{}, errors.Wrap(err, "Error reading quarksSecret")
+	}
+
+	if qsec.GetDeletionTimestamp() != nil || qsec.Spec.Type == qsv1a1.SecretCopy {
+		return reconcile.Result{}, nil
+	}
+
+	consumers := []qsv1a1.ConsumerStatus{}
+	for namespace, names := range consumedSecrets(qsec) {
+		c, err := r.listConsumers(ctx, namespace, names)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		consumers = append(consumers, c...)
+	}
+	sort.Slice(consumers, func(i, j int) bool {
+		return consumerKey(consumers[i]) < consumerKey(consumers[j])
+	})
+
+	status := qsec.Status.DeepCopy()
+	unused := updateConsumers(status, qsec.Spec.UnusedAfter, consumers)
+	if reflect.DeepEqual(status, &qsec.Status) {
+		return reconcile.Result{RequeueAfter: consumerScanInterval}, nil
+	}
+
+	err = patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+		updateConsumers(status, qsec.Spec.UnusedAfter, consumers)
+	})
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+	}
+	if unused {
+		ctxlog.WithEvent(qsec, "Unused").Infof(ctx, "Secret '%s/%s' has no consumers since %s", qsec.Namespace, qsec.Spec.SecretName, qsec.Status.UnusedSince.UTC().Format(time.RFC3339))
+	}
+
+	return reconcile.Result{RequeueAfter: consumerScanInterval}, nil
+}
+
+// updateConsumers sets the consumers and the unused condition in the status.
+// It returns true, if the secret just became unused.
+func updateConsumers(status *qsv1a1.QuarksSecretStatus, unusedAfter *metav1.Duration, consumers []qsv1a1.ConsumerStatus) bool {
+	if len(consumers) > 0 {
+		status.Consumers = consumers
+		status.UnusedSince = nil
+		status.SetCondition(qsv1a1.QuarksSecretCondition{
+			Type:    qsv1a1.ConditionUnused,
+			Status:  corev1.ConditionFalse,
+			Reason:  qsv1a1.UnusedReasonConsumed,
+			Message: fmt.Sprintf("the generated secret has %d consumers", len(consumers)),
+		})
+		return false
+	}
+
+	status.Consumers = nil
+	if status.UnusedSince == nil {
+		now := metav1.Now()
+		status.UnusedSince = &now
+	}
+
+	after := defaultUnusedAfter
+	if unusedAfter != nil {
+		after = unusedAfter.Duration
+	}
+
+	unused := false
+	conditionStatus := corev1.ConditionFalse
+	if time.Since(status.UnusedSince.Time) >= after {
+		conditionStatus = corev1.ConditionTrue
+		if c := status.GetCondition(qsv1a1.ConditionUnused); c == nil || c.Status != corev1.ConditionTrue {
+			unused = true
+		}
+	}
+	status.SetCondition(qsv1a1.QuarksSecretCondition{
+		Type:    qsv1a1.ConditionUnused,
+		Status:  conditionStatus,
+		Reason:  qsv1a1.UnusedReasonNoConsumers,
+		Message: fmt.Sprintf("the generated secret has no consumers since %s", status.UnusedSince.UTC().Format(time.RFC3339)),
+	})
+	return unused
+}
+
+// listConsumers returns the deployments, stateful sets and pods in the
+// namespace, which use one of the secrets. Pods managed by a deployment or
+// stateful set are represented by their workload.
+func (r *ReconcileConsumers) listConsumers(ctx context.Context, namespace string, names []string) ([]qsv1a1.ConsumerStatus, error) {
+	consumers := []qsv1a1.ConsumerStatus{}
+	add := func(kind string, meta metav1.Object, spec corev1.PodSpec) {
+		for _, name := range secretsUsedBy(spec) {
+			if contains(names, name) {
+				consumers = append(consumers, qsv1a1.ConsumerStatus{Kind: kind, Name: meta.GetName(), Namespace: namespace, Secret: name})
+			}
+		}
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.client.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrapf(err, "could not list deployments in namespace '%s'", namespace)
+	}
+	for i := range deployments.Items {
+		add("Deployment", &deployments.Items[i], deployments.Items[i].Spec.Template.Spec)
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.client.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrapf(err, "could not list stateful sets in namespace '%s'", namespace)
+	}
+	for i := range statefulSets.Items {
+		add("StatefulSet", &statefulSets.Items[i], statefulSets.Items[i].Spec.Template.Spec)
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.client.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrapf(err, "could not list pods in namespace '%s'", namespace)
+	}
+	for i := range pods.Items {
+		if owner := metav1.GetControllerOf(&pods.Items[i]); owner != nil && (owner.Kind == "ReplicaSet" || owner.Kind == "StatefulSet") {
+			continue
+		}
+		add("Pod", &pods.Items[i], pods.Items[i].Spec)
+	}
+
+	return consumers, nil
+}
+
+// consumedSecrets returns the names of the generated secret and its copies
+// in the same cluster by namespace
+func consumedSecrets(qsec *qsv1a1.QuarksSecret) map[string][]string {
+	secrets := map[string][]string{qsec.Namespace: {qsec.Spec.SecretName}}
+	for _, copy := range qsec.Status.Copies {
+		if copy.Cluster != "" || copy.State != qsv1a1.CopyStateSynced {
+			continue
+		}
+		if !contains(secrets[copy.Namespace], copy.Name) {
+			secrets[copy.Namespace] = append(secrets[copy.Namespace], copy.Name)
+		}
+	}
+	return secrets
+}
+
+func consumerKey(c qsv1a1.ConsumerStatus) string {
+	return fmt.Sprintf("%s/%s/%s/%s", c.Namespace, c.Kind, c.Name, c.Secret)
+}
@@ -0,0 +1,178 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ReconcileConsumers", func() {
+	var (
+		manager      *cfakes.FakeManager
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		client       *cfakes.FakeClient
+		statusWriter *cfakes.FakeStatusWriter
+		qSecret      *qsv1a1.QuarksSecret
+		deployments  map[string][]appsv1.Deployment
+		pods         map[string][]corev1.Pod
+	)
+
+	envFrom := func(secret string) corev1.PodSpec {
+		return corev1.PodSpec{Containers: []corev1.Container{{
+			Name:    "app",
+			EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: secret}}}},
+		}}}
+	}
+
+	updatedStatus := func() qsv1a1.QuarksSecretStatus {
+		Expect(statusWriter.PatchCallCount()).To(Equal(1))
+		_, object, _, _ := statusWriter.PatchArgsForCall(0)
+		return object.(*qsv1a1.QuarksSecret).Status
+	}
+
+	BeforeEach(func() {
+		manager = &cfakes.FakeManager{}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+		qSecret = &qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: qsv1a1.QuarksSecretSpec{
+				Type:       "password",
+				SecretName: "generated-secret",
+			},
+			Status: qsv1a1.QuarksSecretStatus{
+				Copies: []qsv1a1.CopyStatus{
+					{Name: "copy", Namespace: "team-a", State: qsv1a1.CopyStateSynced},
+					{Name: "copy", Namespace: "team-b", State: qsv1a1.CopyStateSkippedNoPlaceholder},
+				},
+			},
+		}
+		deployments = map[string][]appsv1.Deployment{
+			"default": {
+				{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}, Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: envFrom("generated-secret")}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}, Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: envFrom("other-secret")}}},
+			},
+		}
+		pods = map[string][]corev1.Pod{
+			"default": {{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "app-1234",
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "app-12", Controller: func() *bool { b := true; return &b }()}},
+				},
+				Spec: envFrom("generated-secret"),
+			}},
+			"team-a": {{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "team-a"}, Spec: envFrom("copy")}},
+			"team-b": {{ObjectMeta: metav1.ObjectMeta{Name: "skipped", Namespace: "team-b"}, Spec: envFrom("copy")}},
+		}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			if object, ok := object.(*qsv1a1.QuarksSecret); ok {
+				qSecret.DeepCopyInto(object)
+				return nil
+			}
+			return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+		client.ListCalls(func(context context.Context, object runtime.Object, opts ...crc.ListOption) error {
+			options := &crc.ListOptions{}
+			options.ApplyOptions(opts)
+			switch object := object.(type) {
+			case *appsv1.DeploymentList:
+				object.Items = deployments[options.Namespace]
+			case *corev1.PodList:
+				object.Items = pods[options.Namespace]
+			}
+			return nil
+		})
+		statusWriter = &cfakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		manager.GetClientReturns(client)
+	})
+
+	JustBeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		ctx := ctxlog.NewParentContext(log)
+		reconciler = qscontroller.NewConsumersReconciler(ctx, &cfcfg.Config{CtxTimeOut: 10 * time.Second}, manager)
+	})
+
+	It("records the consumers of the generated secret and its copies", func() {
+		result, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+		status := updatedStatus()
+		Expect(status.Consumers).To(Equal([]qsv1a1.ConsumerStatus{
+			{Kind: "Deployment", Name: "app", Namespace: "default", Secret: "generated-secret"},
+			{Kind: "Pod", Name: "debug", Namespace: "team-a", Secret: "copy"},
+		}))
+		Expect(status.UnusedSince).To(BeNil())
+		condition := status.GetCondition(qsv1a1.ConditionUnused)
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(qsv1a1.UnusedReasonConsumed))
+	})
+
+	It("doesn't update the status, if the consumers didn't change", func() {
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		_, object, _, _ := statusWriter.PatchArgsForCall(0)
+		qSecret = object.(*qsv1a1.QuarksSecret)
+
+		_, err = reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(statusWriter.PatchCallCount()).To(Equal(1))
+	})
+
+	Context("when there are no consumers", func() {
+		BeforeEach(func() {
+			deployments = nil
+			pods = nil
+		})
+
+		It("records since when the secret is unused", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			status := updatedStatus()
+			Expect(status.Consumers).To(BeNil())
+			Expect(status.UnusedSince).ToNot(BeNil())
+			condition := status.GetCondition(qsv1a1.ConditionUnused)
+			Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(qsv1a1.UnusedReasonNoConsumers))
+		})
+
+		It("flags the quarks secret as unused after the unused period", func() {
+			qSecret.Spec.UnusedAfter = &metav1.Duration{Duration: 24 * time.Hour}
+			qSecret.Status.UnusedSince = &metav1.Time{Time: time.Now().Add(-25 * time.Hour)}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			condition := updatedStatus().GetCondition(qsv1a1.ConditionUnused)
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(qsv1a1.UnusedReasonNoConsumers))
+		})
+	})
+})
@@ -0,0 +1,232 @@
+package quarkssecret
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen/backend"
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-secret/pkg/plugin"
+	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// Names of the controllers, which are used to disable them or to set their
+// workers in the Options
+const (
+	CertificateSigningRequestController = "certificate-signing-request-controller"
+	CopyController                      = "copy-controller"
+	QuarksSecretController              = "quarks-secret-controller"
+	SecretRotationController            = "secret-rotation-controller"
+	RotationPolicyController            = "rotation-policy-controller"
+	PreviousCredentialsController       = "previous-credentials-controller"
+	QuarksSecretSecretMetaController    = "quarkssecret-secretmeta-controller"
+	SecretDriftController               = "secret-drift-controller"
+	PublicOutputController              = "public-output-controller"
+	RestartController                   = "restart-controller"
+	ConsumersController                 = "consumers-controller"
+	ExpiryController                    = "expiry-controller"
+	// NotifierController sends the events of quarks secrets to notification endpoints
+	NotifierController = "notifier"
+)
+
+// addToManagerFuncs construct the controllers by name and add them to the
+// controller-runtime manager, in this order
+var addToManagerFuncs = []struct {
+	name string
+	add  func(context.Context, Options, manager.Manager) error
+}{
+	{CertificateSigningRequestController, AddCertificateSigningRequest},
+	{CopyController, AddCopy},
+	{QuarksSecretController, AddQuarksSecret},
+	{SecretRotationController, AddSecretRotation},
+	{RotationPolicyController, AddRotationPolicy},
+	{PreviousCredentialsController, AddPreviousCredentials},
+	{QuarksSecretSecretMetaController, AddQuarksSecretSecretMeta},
+	{SecretDriftController, AddSecretDrift},
+	{PublicOutputController, AddPublicOutput},
+	{RestartController, AddRestart},
+	{ConsumersController, AddConsumers},
+	{ExpiryController, AddExpiry},
+}
+
+// Options configure the quarks secret controllers, e.g. when they are
+// embedded in another operator
+type Options struct {
+	// Config of the operator
+	Config *config.Config
+	// Generator generates the secrets of quarks secrets without a generator
+	// reference, defaults to the default backend of Generators. Without
+	// Generators, it also generates the secrets of quarks secrets with a
+	// generator reference.
+	Generator credsgen.Generator
+	// Generators are the generator backends, which quarks secrets reference
+	// by name, defaults to the in-memory backend with the Defaults
+	Generators *backend.Generators
+	// Defaults of the generators, e.g. the FIPS mode, which the namespace
+	// defaults override, defaults to the built-in defaults
+	Defaults *credsgen.Defaults
+	// PluginRunner generates custom secret types without a handler by exec
+	// plugins, defaults to a runner without plugins
+	PluginRunner *plugin.Runner
+	// Recorder records the events of all controllers, defaults to a
+	// recorder of the manager per controller
+	Recorder record.EventRecorder
+	// Predicates filter the quarks secrets the controllers watch, in
+	// addition to the monitored namespaces
+	Predicates []predicate.Predicate
+	// Workers is the number of concurrent reconciles by controller name,
+	// defaults to the max workers of the config
+	Workers map[string]int
+	// SecretTypeHandlers generate additional secret types, the handlers of
+	// the built-in types can't be replaced. Custom types without a handler
+	// are generated by exec plugins.
+	SecretTypeHandlers map[qsv1a1.SecretType]SecretTypeHandler
+	// Disabled lists the names of controllers, which are not added to the
+	// manager, e.g. the CertificateSigningRequestController, which approves
+	// certificate signing requests
+	Disabled []string
+}
+
+// Controllers adds the quarks secret controllers to a manager
+type Controllers struct {
+	options Options
+}
+
+// NewControllers returns the quarks secret controllers configured by the
+// options
+func NewControllers(options Options) *Controllers {
+	return &Controllers{options: options}
+}
+
+// AddToManager adds the enabled controllers to the manager
+func (c *Controllers) AddToManager(ctx context.Context, mgr manager.Manager) error {
+	if c.options.Config == nil {
+		return errors.New("quarks secret controllers need a config")
+	}
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	if c.options.Recorder != nil {
+		mgr = &recordingManager{Manager: mgr, recorder: c.options.Recorder}
+	}
+
+	if !c.disabled(NotifierController) {
+		// the events of quarks secrets are also sent to notification endpoints
+		m, err := AddNotifications(ctx, mgr)
+		if err != nil {
+			return err
+		}
+		mgr = m
+	}
+
+	for _, f := range addToManagerFuncs {
+		if c.disabled(f.name) {
+			continue
+		}
+		if err := f.add(ctx, c.options, mgr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate returns an error for unknown controller names in the options and
+// for handlers, which would replace built-in secret types
+func (c *Controllers) validate() error {
+	names := []string{NotifierController}
+	for _, f := range addToManagerFuncs {
+		names = append(names, f.name)
+	}
+
+	for _, name := range c.options.Disabled {
+		if !contains(names, name) {
+			return errors.Errorf("can't disable unknown controller '%s'", name)
+		}
+	}
+	for name, workers := range c.options.Workers {
+		if !contains(names, name) || name == NotifierController {
+			return errors.Errorf("can't set workers of unknown controller '%s'", name)
+		}
+		if workers < 1 {
+			return errors.Errorf("workers of controller '%s' must be greater than 0", name)
+		}
+	}
+	return validateSecretTypeHandlers(c.options.SecretTypeHandlers)
+}
+
+func (c *Controllers) disabled(name string) bool {
+	return contains(c.options.Disabled, name)
+}
+
+// generator returns the generator of the options or the default generator
+// backend
+func (o Options) generator(ctx context.Context) credsgen.Generator {
+	if o.Generator != nil {
+		return o.Generator
+	}
+	return o.generators(ctx).Default()
+}
+
+// generators returns the generator backends of the options. It returns nil,
+// if only a generator is set, which then generates all quarks secrets.
+func (o Options) generators(ctx context.Context) *backend.Generators {
+	if o.Generators != nil {
+		return o.Generators
+	}
+	if o.Generator != nil {
+		return nil
+	}
+	// the in-memory backend can't fail
+	generators, _ := backend.New(ctxlog.ExtractLogger(ctx), o.defaults(), backend.Config{})
+	return generators
+}
+
+// defaults returns the generator defaults of the options or the built-in
+// defaults
+func (o Options) defaults() credsgen.Defaults {
+	if o.Defaults != nil {
+		return *o.Defaults
+	}
+	return credsgen.NewDefaults()
+}
+
+// pluginRunner returns the plugin runner of the options or a runner without
+// plugins
+func (o Options) pluginRunner() *plugin.Runner {
+	if o.PluginRunner != nil {
+		return o.PluginRunner
+	}
+	return plugin.NewRunner("")
+}
+
+// workers returns the number of concurrent reconciles of the controller
+func (o Options) workers(name string) int {
+	if workers, ok := o.Workers[name]; ok {
+		return workers
+	}
+	return o.Config.MaxQuarksSecretWorkers
+}
+
+// quarksSecretPredicates appends the predicates of the options to the
+// predicates of a quarks secret watch
+func (o Options) quarksSecretPredicates(predicates ...predicate.Predicate) []predicate.Predicate {
+	return append(predicates, o.Predicates...)
+}
+
+// recordingManager returns the same event recorder for all controllers
+type recordingManager struct {
+	manager.Manager
+	recorder record.EventRecorder
+}
+
+// GetEventRecorderFor returns the recorder of the options
+func (m *recordingManager) GetEventRecorderFor(_ string) record.EventRecorder {
+	return m.recorder
+}
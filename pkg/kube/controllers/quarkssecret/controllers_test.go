@@ -0,0 +1,258 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen/backend"
+	generatorfakes "code.cloudfoundry.org/quarks-secret/pkg/credsgen/fakes"
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/scheme"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	"code.cloudfoundry.org/quarks-secret/pkg/plugin"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("Controllers", func() {
+	var (
+		ctx     context.Context
+		manager *cfakes.FakeManager
+		options qscontroller.Options
+	)
+
+	// controllers returns the workers of the controllers added to the
+	// manager by name
+	controllers := func() map[string]int64 {
+		added := map[string]int64{}
+		for i := 0; i < manager.AddCallCount(); i++ {
+			c := reflect.Indirect(reflect.ValueOf(manager.AddArgsForCall(i)))
+			if name := c.FieldByName("Name"); name.IsValid() {
+				added[name.String()] = c.FieldByName("MaxConcurrentReconciles").Int()
+			}
+		}
+		return added
+	}
+
+	BeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		ctx = ctxlog.NewParentContext(log)
+
+		manager = &cfakes.FakeManager{}
+		manager.GetClientReturns(&cfakes.FakeClient{})
+		manager.GetConfigReturns(&rest.Config{})
+		manager.GetSchemeReturns(scheme.Scheme)
+		manager.GetLoggerReturns(logf.NullLogger{})
+		manager.GetEventRecorderForReturns(record.NewFakeRecorder(10))
+
+		options = qscontroller.Options{
+			Config: &cfcfg.Config{MaxQuarksSecretWorkers: 2},
+		}
+	})
+
+	It("adds all controllers and the notifier to the manager", func() {
+		err := qscontroller.NewControllers(options).AddToManager(ctx, manager)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(manager.AddCallCount()).To(Equal(13))
+		Expect(controllers()).To(HaveLen(12))
+		Expect(controllers()).To(HaveKeyWithValue(qscontroller.QuarksSecretController, int64(2)))
+		Expect(controllers()).To(HaveKeyWithValue(qscontroller.CertificateSigningRequestController, int64(2)))
+	})
+
+	It("doesn't add disabled controllers", func() {
+		options.Disabled = []string{qscontroller.CertificateSigningRequestController, qscontroller.NotifierController}
+
+		err := qscontroller.NewControllers(options).AddToManager(ctx, manager)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(manager.AddCallCount()).To(Equal(11))
+		Expect(controllers()).ToNot(HaveKey(qscontroller.CertificateSigningRequestController))
+	})
+
+	It("sets the workers per controller", func() {
+		options.Workers = map[string]int{qscontroller.CopyController: 5}
+
+		err := qscontroller.NewControllers(options).AddToManager(ctx, manager)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(controllers()).To(HaveKeyWithValue(qscontroller.CopyController, int64(5)))
+		Expect(controllers()).To(HaveKeyWithValue(qscontroller.QuarksSecretController, int64(2)))
+	})
+
+	It("passes the options to single controllers", func() {
+		options.Workers = map[string]int{qscontroller.CopyController: 3}
+
+		err := qscontroller.AddCopy(ctx, options, manager)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(controllers()).To(Equal(map[string]int64{qscontroller.CopyController: 3}))
+	})
+
+	It("uses the recorder of the options", func() {
+		options.Recorder = record.NewFakeRecorder(10)
+
+		err := qscontroller.NewControllers(options).AddToManager(ctx, manager)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(manager.GetEventRecorderForCallCount()).To(Equal(0))
+	})
+
+	It("returns an error for unknown controllers", func() {
+		options.Disabled = []string{"csr-approver"}
+
+		err := qscontroller.NewControllers(options).AddToManager(ctx, manager)
+		Expect(err).To(MatchError("can't disable unknown controller 'csr-approver'"))
+		Expect(manager.AddCallCount()).To(Equal(0))
+	})
+
+	It("returns an error for invalid workers", func() {
+		options.Workers = map[string]int{qscontroller.CopyController: 0}
+
+		err := qscontroller.NewControllers(options).AddToManager(ctx, manager)
+		Expect(err).To(MatchError(ContainSubstring("must be greater than 0")))
+	})
+
+	It("returns an error without a config", func() {
+		options.Config = nil
+
+		err := qscontroller.NewControllers(options).AddToManager(ctx, manager)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("when reconciling with the dependencies of the options", func() {
+		var (
+			client       *cfakes.FakeClient
+			statusWriter *cfakes.FakeStatusWriter
+			generator    *generatorfakes.FakeGenerator
+			qSecret      *qsv1a1.QuarksSecret
+			created      *corev1.Secret
+		)
+
+		// reconcileQuarksSecret adds the controllers and reconciles the
+		// quarks secret with the reconciler of the quarks secret controller
+		reconcileQuarksSecret := func() error {
+			err := qscontroller.NewControllers(options).AddToManager(ctx, manager)
+			Expect(err).ToNot(HaveOccurred())
+
+			for i := 0; i < manager.AddCallCount(); i++ {
+				c := reflect.Indirect(reflect.ValueOf(manager.AddArgsForCall(i)))
+				if name := c.FieldByName("Name"); name.IsValid() && name.String() == qscontroller.QuarksSecretController {
+					r := c.FieldByName("Do").Interface().(reconcile.Reconciler)
+					_, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}})
+					return err
+				}
+			}
+			Fail("quarks secret controller was not added")
+			return nil
+		}
+
+		BeforeEach(func() {
+			options.Config.CtxTimeOut = 10 * time.Second
+			generator = &generatorfakes.FakeGenerator{}
+			generator.GeneratePasswordReturns("securepassword")
+			qSecret = &qsv1a1.QuarksSecret{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Finalizers: []string{qsv1a1.Finalizer}},
+				Spec: qsv1a1.QuarksSecretSpec{
+					Type:       qsv1a1.Password,
+					SecretName: "generated-secret",
+				},
+			}
+			created = nil
+
+			client = &cfakes.FakeClient{}
+			client.GetCalls(func(_ context.Context, nn types.NamespacedName, object runtime.Object) error {
+				if object, ok := object.(*qsv1a1.QuarksSecret); ok {
+					qSecret.DeepCopyInto(object)
+					return nil
+				}
+				return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+			client.CreateCalls(func(_ context.Context, object runtime.Object, _ ...crc.CreateOption) error {
+				if secret, ok := object.(*corev1.Secret); ok && secret.Name == "generated-secret" {
+					created = secret
+				}
+				return nil
+			})
+			statusWriter = &cfakes.FakeStatusWriter{}
+			client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+			manager.GetClientReturns(client)
+		})
+
+		It("uses the generator for quarks secrets with a generator reference, if there are no generator backends", func() {
+			options.Generator = generator
+			qSecret.Spec.GeneratorRef = backend.Vault
+
+			Expect(reconcileQuarksSecret()).To(Succeed())
+			Expect(generator.GeneratePasswordCallCount()).To(Equal(1))
+			Expect(created.StringData).To(HaveKeyWithValue("password", "securepassword"))
+		})
+
+		It("uses the generator backends for quarks secrets with a generator reference", func() {
+			generators, err := backend.New(ctxlog.ExtractLogger(ctx), credsgen.NewDefaults(), backend.Config{})
+			Expect(err).ToNot(HaveOccurred())
+			options.Generator = generator
+			options.Generators = generators
+
+			qSecret.Spec.GeneratorRef = backend.InMemory
+			Expect(reconcileQuarksSecret()).To(Succeed())
+			Expect(generator.GeneratePasswordCallCount()).To(Equal(0))
+			Expect(created.StringData["password"]).To(HaveLen(credsgen.DefaultPasswordLength))
+
+			qSecret.Spec.GeneratorRef = backend.Vault
+			Expect(reconcileQuarksSecret()).To(MatchError(ContainSubstring("generator 'vault' is not configured")))
+		})
+
+		It("uses the defaults", func() {
+			defaults := credsgen.NewDefaults()
+			defaults.FIPS = true
+			options.Defaults = &defaults
+			options.Generator = generator
+
+			Expect(reconcileQuarksSecret()).To(Succeed())
+			Expect(statusWriter.PatchCallCount()).To(BeNumerically(">", 0))
+			_, object, _, _ := statusWriter.PatchArgsForCall(statusWriter.PatchCallCount() - 1)
+			condition := object.(*qsv1a1.QuarksSecret).Status.GetCondition(qsv1a1.ConditionFIPSCompliant)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+		})
+
+		It("uses the plugin runner", func() {
+			dir, err := ioutil.TempDir("", "quarks-secret-plugins")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "example.com", "token")
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(`#!/bin/sh
+echo '{"apiVersion": "quarks.cloudfoundry.org/v1alpha1", "kind": "PluginResponse", "data": {"token": "t0k3n"}}'
+`), 0755)).To(Succeed())
+			options.PluginRunner = plugin.NewRunner(dir)
+			qSecret.Spec.Type = "example.com/token"
+
+			Expect(reconcileQuarksSecret()).To(Succeed())
+			Expect(created.StringData).To(Equal(map[string]string{"token": "t0k3n"}))
+		})
+	})
+})
@@ -17,23 +17,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	credsgen "code.cloudfoundry.org/quarks-secret/pkg/credsgen/in_memory_generator"
 	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
-	"code.cloudfoundry.org/quarks-utils/pkg/config"
 	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
 	"code.cloudfoundry.org/quarks-utils/pkg/skip"
 )
 
 // AddCopy creates a new QuarksSecrets controller to watch for the
 // user defined secrets.
-func AddCopy(ctx context.Context, config *config.Config, mgr manager.Manager) error {
+func AddCopy(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
 	ctx = ctxlog.NewContextWithRecorder(ctx, "copy-reconciler", mgr.GetEventRecorderFor("copy-recorder"))
-	log := ctxlog.ExtractLogger(ctx)
-	r := NewCopyReconciler(ctx, config, mgr, credsgen.NewInMemoryGenerator(log), controllerutil.SetControllerReference)
+	r := NewCopyReconciler(ctx, config, mgr, options.generator(ctx), controllerutil.SetControllerReference, NewClusterClientFunc(mgr.GetScheme()))
 
-	c, err := controller.New("copy-controller", mgr, controller.Options{
+	c, err := controller.New(CopyController, mgr, controller.Options{
 		Reconciler:              r,
-		MaxConcurrentReconciles: config.MaxQuarksSecretWorkers,
+		MaxConcurrentReconciles: options.workers(CopyController),
 	})
 	if err != nil {
 		return errors.Wrap(err, "Adding copy controller to manager failed.")
@@ -48,6 +46,12 @@ func AddCopy(ctx context.Context, config *config.Config, mgr manager.Manager) er
 		GenericFunc: func(e event.GenericEvent) bool { return false },
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			n := e.ObjectNew.(*qsv1a1.QuarksSecret)
+			o := e.ObjectOld.(*qsv1a1.QuarksSecret)
+
+			if !reflect.DeepEqual(n.Spec.Copies, o.Spec.Copies) || !reflect.DeepEqual(n.Spec.CopySelector, o.Spec.CopySelector) {
+				ctxlog.Debugf(ctx, "Copies of QuarksSecret '%s' changed", n.Name)
+				return true
+			}
 
 			if n.Status.Copied != nil {
 				ctxlog.Debugf(ctx, "Skipping QuarksSecret '%s', if copy status '%v' is true", n.Name, *n.Status.Copied)
@@ -57,7 +61,7 @@ func AddCopy(ctx context.Context, config *config.Config, mgr manager.Manager) er
 			return true
 		},
 	}
-	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, nsPred, p)
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, options.quarksSecretPredicates(nsPred, p)...)
 	if err != nil {
 		return errors.Wrapf(err, "Watching quarks secrets failed in copy controller.")
 	}
@@ -103,6 +107,58 @@ func AddCopy(ctx context.Context, config *config.Config, mgr manager.Manager) er
 		return errors.Wrapf(err, "Watching user defined secrets failed in copy controller.")
 	}
 
+	// Watch for namespaces, which are selected by a copy selector
+	p = predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return true },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels())
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Namespace{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			reconciles, err := listCopySelectorReconciles(ctx, mgr.GetClient(), a.Meta)
+			if err != nil {
+				ctxlog.Errorf(ctx, "Failed to calculate reconciles for namespace '%s': %v", a.Meta.GetName(), err)
+			}
+			for _, request := range reconciles {
+				ctxlog.NewMappingEvent(a.Object).Debug(ctx, request, "QuarksSecret", a.Meta.GetName(), "namespace")
+			}
+			return reconciles
+		}),
+	}, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching namespaces failed in copy controller.")
+	}
+
+	// Watch for copy policies, which allow copies without a placeholder
+	p = predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return true },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*qsv1a1.QuarksSecretCopyPolicy)
+			o := e.ObjectOld.(*qsv1a1.QuarksSecretCopyPolicy)
+			return !reflect.DeepEqual(n.Spec, o.Spec)
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecretCopyPolicy{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			reconciles, err := listCopyReconciles(ctx, mgr.GetClient())
+			if err != nil {
+				ctxlog.Errorf(ctx, "Failed to calculate reconciles for copy policy '%s': %v", a.Meta.GetName(), err)
+			}
+			for _, request := range reconciles {
+				ctxlog.NewMappingEvent(a.Object).Debug(ctx, request, "QuarksSecret", a.Meta.GetName(), "copy-policy")
+			}
+			return reconciles
+		}),
+	}, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching copy policies failed in copy controller.")
+	}
+
 	return nil
 }
 
@@ -2,10 +2,14 @@ package quarkssecret
 
 import (
 	"context"
+	"reflect"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -25,7 +29,7 @@ import (
 )
 
 // NewCopyReconciler returns a new ReconcileCopy
-func NewCopyReconciler(ctx context.Context, config *config.Config, mgr manager.Manager, generator credsgen.Generator, srf setReferenceFunc) reconcile.Reconciler {
+func NewCopyReconciler(ctx context.Context, config *config.Config, mgr manager.Manager, generator credsgen.Generator, srf setReferenceFunc, ccf ClusterClientFunc) reconcile.Reconciler {
 	return &ReconcileCopy{
 		ctx:          ctx,
 		config:       config,
@@ -33,6 +37,7 @@ func NewCopyReconciler(ctx context.Context, config *config.Config, mgr manager.M
 		scheme:       mgr.GetScheme(),
 		generator:    generator,
 		setReference: srf,
+		clusters:     newClusterClients(ccf),
 	}
 }
 
@@ -44,10 +49,11 @@ type ReconcileCopy struct {
 	scheme       *runtime.Scheme
 	setReference setReferenceFunc
 	config       *config.Config
+	clusters     *clusterClients
 }
 
-// Reconcile reads sets the copied field in status spec to false and copies the secrets from source namespace
-// to the target namespaces.
+// Reconcile copies the secrets from source namespace to the target namespaces and records the state of
+// each copy in the status. The copied field is only true if all copies are synced.
 func (r *ReconcileCopy) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	qsec := &qsv1a1.QuarksSecret{}
 
@@ -70,65 +76,187 @@ func (r *ReconcileCopy) Reconcile(request reconcile.Request) (reconcile.Result,
 		return reconcile.Result{}, errors.Wrap(err, "Error reading quarksSecret")
 	}
 
-	r.updateCopyStatus(ctx, qsec, false)
+	if qsec.GetDeletionTimestamp() != nil {
+		ctxlog.Info(ctx, "Skip reconcile: quarks secret is being deleted")
+		return reconcile.Result{}, nil
+	}
 
-	err = r.handleQuarksSecretCopies(ctx, qsec)
+	// quarks secrets created before the finalizer was introduced
+	err = addFinalizer(ctx, r.client, qsec)
 	if err != nil {
-		ctxlog.Errorf(ctx, "Error handling quarks secret copies '%s'", qsec.Name, err.Error())
+		return reconcile.Result{}, err
+	}
+
+	copies, err := desiredCopies(ctx, r.client, qsec)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "Error selecting quarksSecret copies")
+	}
+
+	status := qsec.Status.DeepCopy()
+	statuses, err := r.handleQuarksSecretCopies(ctx, qsec, copies)
+	if err != nil {
+		ctxlog.Errorf(ctx, "Error handling quarks secret copies '%s': %s", qsec.Name, err.Error())
+		r.updateCopyStatus(ctx, qsec, status, status.Copies, false)
 		return reconcile.Result{}, errors.Wrap(err, "Error handling quarksSecret copies")
 	}
 
-	r.updateCopyStatus(ctx, qsec, true)
+	err = r.removeDroppedCopies(ctx, qsec, copies)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "Error removing dropped quarksSecret copies")
+	}
+
+	copied := true
+	failed := 0
+	for _, copy := range statuses {
+		if copy.State != qsv1a1.CopyStateSynced {
+			copied = false
+		}
+		if copy.State == qsv1a1.CopyStateError {
+			failed++
+		}
+	}
+	r.updateCopyStatus(ctx, qsec, status, statuses, copied)
+
+	if failed > 0 {
+		return reconcile.Result{}, errors.Errorf("Error writing %d of %d quarksSecret copies", failed, len(statuses))
+	}
 	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileCopy) updateCopyStatus(ctx context.Context, qsec *qsv1a1.QuarksSecret, copyStatus bool) {
-	qsec.Status.Copied = pointers.Bool(copyStatus)
+// updateCopyStatus sets the copies and the copied field and writes the
+// status, if it changed. Copied is only true if all copies are synced.
+func (r *ReconcileCopy) updateCopyStatus(ctx context.Context, qsec *qsv1a1.QuarksSecret, old *qsv1a1.QuarksSecretStatus, copies []qsv1a1.CopyStatus, copied bool) {
+	if reflect.DeepEqual(old.Copies, copies) && reflect.DeepEqual(old.Copied, pointers.Bool(copied)) {
+		return
+	}
 
-	err := r.client.Status().Update(ctx, qsec)
+	err := patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+		status.Copies = copies
+		status.Copied = pointers.Bool(copied)
+	})
 	if err != nil {
 		ctxlog.Errorf(ctx, "could not create or update QuarksSecret status '%s': %v", qsec.GetNamespacedName(), err)
 	}
 }
 
-func (r *ReconcileCopy) handleQuarksSecretCopies(ctx context.Context, sourceQuarksSecret *qsv1a1.QuarksSecret) error {
-	for _, copy := range sourceQuarksSecret.Spec.Copies {
-		sourceSecret, err := GetSourceSecret(ctx, r.client, sourceQuarksSecret)
+// handleQuarksSecretCopies writes the copies and returns their state
+func (r *ReconcileCopy) handleQuarksSecretCopies(ctx context.Context, sourceQuarksSecret *qsv1a1.QuarksSecret, copies []qsv1a1.Copy) ([]qsv1a1.CopyStatus, error) {
+	statuses := []qsv1a1.CopyStatus{}
+	if len(copies) == 0 {
+		return statuses, nil
+	}
+
+	sourceSecret, err := GetSourceSecret(ctx, r.client, sourceQuarksSecret)
+	if err != nil {
+		return statuses, err
+	}
+
+	for _, copy := range copies {
+		status := qsv1a1.CopyStatus{Name: copy.Name, Namespace: copy.Namespace, Cluster: copy.Cluster}
+
+		targetClient, err := r.clusters.get(ctx, r.client, sourceQuarksSecret.Namespace, copy.Cluster)
 		if err != nil {
-			return err
+			statuses = append(statuses, copyError(ctx, sourceQuarksSecret, status, err))
+			continue
 		}
-		ok, targetQuarksSecret, err := r.validateTargetNamespace(ctx, sourceQuarksSecret, copy)
+
+		state, targetQuarksSecret, err := r.validateTargetNamespace(ctx, targetClient, sourceQuarksSecret, copy)
 		if err != nil {
-			return errors.Wrapf(err, "could not validate")
+			statuses = append(statuses, copyError(ctx, sourceQuarksSecret, status, errors.Wrapf(err, "could not validate")))
+			continue
 		}
-
-		targetSecret := &corev1.Secret{}
-		if ok {
-			targetSecret.Name = copy.Name
-			targetSecret.Namespace = copy.Namespace
-			targetSecret.Data = sourceSecret.Data
-			targetSecret.Annotations = sourceSecret.Annotations
-			targetSecret.Labels = sourceSecret.Labels
-
-			annotations := targetSecret.GetAnnotations()
-			if annotations == nil {
-				annotations = map[string]string{}
+		// without a placeholder, a copy policy has to allow copies in the
+		// same cluster
+		if state == qsv1a1.CopyStateSkippedNoPlaceholder && copy.Cluster == "" {
+			allowed, err := copyAllowedByPolicy(ctx, r.client, sourceQuarksSecret, copy)
+			if err != nil {
+				statuses = append(statuses, copyError(ctx, sourceQuarksSecret, status, errors.Wrapf(err, "could not check copy policies")))
+				continue
+			}
+			if allowed {
+				state = qsv1a1.CopyStateSynced
 			}
-			annotations[qsv1a1.AnnotationCopyOf] = sourceQuarksSecret.GetNamespacedName()
-			targetSecret.SetAnnotations(annotations)
-
-			if targetQuarksSecret == nil {
-				if err := r.updateCopySecret(ctx, targetSecret); err != nil {
-					return err
-				}
-				ctxlog.WithEvent(sourceQuarksSecret, "CopyReconcile").Infof(ctx, "Copy secret '%s' has been updated in namespace '%s'", copy.Name, copy.Namespace)
-			} else {
-				if err := r.createUpdateCopySecret(ctx, targetSecret, targetQuarksSecret); err != nil {
-					return err
-				}
+		}
+
+		if state != qsv1a1.CopyStateSynced {
+			ctxlog.WithEvent(sourceQuarksSecret, "CopyReconcile").Infof(ctx, "Skip copy creation: Secret/QSecret '%s' must exist and have the appropriate annotation, or a copy policy must allow the copy", copy.String())
+			status.State = state
+			status.Message = copyStateMessages[state]
+			statuses = append(statuses, status)
+			continue
+		}
+
+		targetSecret := transformCopy(sourceSecret, copy)
+		targetSecret.Annotations[qsv1a1.AnnotationCopyOf] = sourceQuarksSecret.GetNamespacedName()
+
+		if copy.Type != "" {
+			if err := copyTypeChanged(ctx, targetClient, targetSecret); err != nil {
+				statuses = append(statuses, copyError(ctx, sourceQuarksSecret, status, err))
+				continue
 			}
+		}
+
+		if targetQuarksSecret == nil {
+			err = r.writeCopySecret(ctx, targetClient, targetSecret)
 		} else {
-			ctxlog.WithEvent(sourceQuarksSecret, "CopyReconcile").Infof(ctx, "Skip copy creation: Secret/QSecret '%s' must exist and have the appropriate annotation to receive a copy", copy.String())
+			err = r.createUpdateCopySecret(ctx, targetClient, targetSecret, targetQuarksSecret)
+		}
+		if err != nil {
+			statuses = append(statuses, copyError(ctx, sourceQuarksSecret, status, err))
+			continue
+		}
+		status.State = qsv1a1.CopyStateSynced
+		status.ContentHash = contentHash(targetSecret.Data)
+		for _, previous := range sourceQuarksSecret.Status.Copies {
+			if previous.Name == copy.Name && previous.Namespace == copy.Namespace && previous.Cluster == copy.Cluster &&
+				previous.State == qsv1a1.CopyStateSynced && previous.ContentHash == status.ContentHash && previous.LastSync != nil {
+				status.LastSync = previous.LastSync
+			}
+		}
+		// the content of the copy changed
+		if status.LastSync == nil {
+			status.LastSync = &metav1.Time{Time: time.Now()}
+			ctxlog.WithEvent(sourceQuarksSecret, "Copied").Infof(ctx, "Copy secret '%s' has been updated in namespace '%s'", copy.Name, copy.Namespace)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// copyStateMessages explains why a copy was skipped
+var copyStateMessages = map[qsv1a1.CopyState]string{
+	qsv1a1.CopyStateSkippedNoPlaceholder:   "the target namespace has no placeholder and no copy policy allows the copy",
+	qsv1a1.CopyStateSkippedWrongAnnotation: "the secret or quarks secret in the target namespace is not a copy of this quarks secret",
+}
+
+// copyError records a copy, which failed to be written
+func copyError(ctx context.Context, qsec *qsv1a1.QuarksSecret, status qsv1a1.CopyStatus, err error) qsv1a1.CopyStatus {
+	ctxlog.WithEvent(qsec, "CopyError").Errorf(ctx, "Error writing copy '%s/%s': %v", status.Namespace, status.Name, err)
+	status.State = qsv1a1.CopyStateError
+	status.Message = err.Error()
+	return status
+}
+
+// removeDroppedCopies removes the copies, which were written before, but
+// are no longer in the spec or selected. Like on deletion, the copies are
+// kept without their copy-of annotation for the orphan policy.
+func (r *ReconcileCopy) removeDroppedCopies(ctx context.Context, qsec *qsv1a1.QuarksSecret, desired []qsv1a1.Copy) error {
+	for _, copy := range qsec.Status.Copies {
+		if containsSpecCopy(desired, qsv1a1.Copy{Name: copy.Name, Namespace: copy.Namespace, Cluster: copy.Cluster}) {
+			continue
+		}
+
+		policy := qsec.Spec.DeletionPolicy
+		if policy != qsv1a1.DeletionPolicyOrphan {
+			policy = qsv1a1.DeletionPolicyDelete
+		}
+		targetClient, err := r.clusters.get(ctx, r.client, qsec.Namespace, copy.Cluster)
+		if err != nil {
+			return err
+		}
+		if err := removeCopy(ctx, targetClient, qsec, copy, policy); err != nil {
+			return err
 		}
 	}
 
@@ -148,34 +276,36 @@ func GetSourceSecret(ctx context.Context, client client.Client, qsec *qsv1a1.Qua
 }
 
 // validateTargetNamespace checks if there is a valid secret or valid quarks secret in the target namespace.
-func (r *ReconcileCopy) validateTargetNamespace(ctx context.Context, sourceQuarksSecret *qsv1a1.QuarksSecret, copy qsv1a1.Copy) (bool, *qsv1a1.QuarksSecret, error) {
+// It returns the synced state, if the copy can be written, or why it is skipped.
+func (r *ReconcileCopy) validateTargetNamespace(ctx context.Context, targetClient client.Client, sourceQuarksSecret *qsv1a1.QuarksSecret, copy qsv1a1.Copy) (qsv1a1.CopyState, *qsv1a1.QuarksSecret, error) {
 	notFoundQuarksSecret := false
 	notFoundSecret := false
 
 	targetQuarksSecret := &qsv1a1.QuarksSecret{}
-	err := r.client.Get(ctx, types.NamespacedName{Name: sourceQuarksSecret.Name, Namespace: copy.Namespace}, targetQuarksSecret)
+	err := targetClient.Get(ctx, types.NamespacedName{Name: sourceQuarksSecret.Name, Namespace: copy.Namespace}, targetQuarksSecret)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
+		// remote clusters might not have the quarks secret CRD
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
 			notFoundQuarksSecret = true
 		} else {
-			return false, nil, errors.Wrapf(err, "could not get target quarks secret")
+			return qsv1a1.CopyStateError, nil, errors.Wrapf(err, "could not get target quarks secret")
 		}
 	}
 
 	targetSecret := &corev1.Secret{}
-	err = r.client.Get(ctx, types.NamespacedName{Name: copy.Name, Namespace: copy.Namespace}, targetSecret)
+	err = targetClient.Get(ctx, types.NamespacedName{Name: copy.Name, Namespace: copy.Namespace}, targetSecret)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			notFoundSecret = true
 		} else {
-			return false, nil, errors.Wrapf(err, "could not get target secret")
+			return qsv1a1.CopyStateError, nil, errors.Wrapf(err, "could not get target secret")
 		}
 	}
 
 	// If both are absent, we will skip the copying process
 	if notFoundSecret && notFoundQuarksSecret {
 		ctxlog.WithEvent(sourceQuarksSecret, "ValidateTargetNamespace").Infof(ctx, "No Valid Quarks Secret or Secret found in the target namespace '%s'", copy.Namespace)
-		return false, nil, nil
+		return qsv1a1.CopyStateSkippedNoPlaceholder, nil, nil
 	}
 
 	// If both of them are found, give preference to quarks secret
@@ -194,10 +324,13 @@ func (r *ReconcileCopy) validateTargetNamespace(ctx context.Context, sourceQuark
 
 		if targetQuarksSecret.Spec.Type != qsv1a1.SecretCopy {
 			ctxlog.WithEvent(sourceQuarksSecret, "ValidateTargetNamespace").Infof(ctx, "Invalid type for Quarks Secret. It must be 'copy' type.")
-			return false, nil, nil
+			return qsv1a1.CopyStateSkippedWrongAnnotation, nil, nil
 		}
 
-		return validateAnnotation(ctx, sourceQuarksSecret, annotations, sourceQuarksSecret.GetNamespacedName()), targetQuarksSecret, nil
+		if !validateAnnotation(ctx, sourceQuarksSecret, annotations, sourceQuarksSecret.GetNamespacedName()) {
+			return qsv1a1.CopyStateSkippedWrongAnnotation, nil, nil
+		}
+		return qsv1a1.CopyStateSynced, targetQuarksSecret, nil
 	} else if notFoundQuarksSecret {
 		ctxlog.WithEvent(sourceQuarksSecret, "ValidateTargetNamespace").Infof(ctx, "Valid Secret found")
 
@@ -206,10 +339,13 @@ func (r *ReconcileCopy) validateTargetNamespace(ctx context.Context, sourceQuark
 			annotations = map[string]string{}
 		}
 
-		return validateAnnotation(ctx, sourceQuarksSecret, annotations, sourceQuarksSecret.GetNamespacedName()), nil, nil
+		if !validateAnnotation(ctx, sourceQuarksSecret, annotations, sourceQuarksSecret.GetNamespacedName()) {
+			return qsv1a1.CopyStateSkippedWrongAnnotation, nil, nil
+		}
+		return qsv1a1.CopyStateSynced, nil, nil
 	}
 
-	return false, nil, nil
+	return qsv1a1.CopyStateSkippedNoPlaceholder, nil, nil
 }
 
 func validateAnnotation(ctx context.Context, sourceQsec *qsv1a1.QuarksSecret, secretAnnotations map[string]string, copyOf string) bool {
@@ -222,14 +358,14 @@ func validateAnnotation(ctx context.Context, sourceQsec *qsv1a1.QuarksSecret, se
 	return valid
 }
 
-func (r *ReconcileCopy) createUpdateCopySecret(ctx context.Context, targetSecret *corev1.Secret, targetQuarksSecret *qsv1a1.QuarksSecret) error {
+func (r *ReconcileCopy) createUpdateCopySecret(ctx context.Context, targetClient client.Client, targetSecret *corev1.Secret, targetQuarksSecret *qsv1a1.QuarksSecret) error {
 	if targetQuarksSecret != nil {
 		if err := r.setReference(targetQuarksSecret, targetSecret, r.scheme); err != nil {
 			return errors.Wrapf(err, "error setting owner for secret '%s' to QuarksSecret '%s'", targetSecret.GetName(), targetQuarksSecret.GetNamespacedName())
 		}
 	}
 
-	op, err := controllerutil.CreateOrUpdate(ctx, r.client, targetSecret, mutate.SecretMutateFn(targetSecret))
+	op, err := controllerutil.CreateOrUpdate(ctx, targetClient, targetSecret, mutate.SecretCopyMutateFn(targetSecret))
 	if err != nil {
 		return errors.Wrapf(err, "could not create or update target secret '%s/%s'", targetSecret.Namespace, targetSecret.GetName())
 	}
@@ -240,8 +376,22 @@ func (r *ReconcileCopy) createUpdateCopySecret(ctx context.Context, targetSecret
 	return nil
 }
 
+// writeCopySecret updates a copied destination Secret, or creates it if a
+// copy policy allows copies without a placeholder
+func (r *ReconcileCopy) writeCopySecret(ctx context.Context, targetClient client.Client, secret *corev1.Secret) error {
+	err := r.updateCopySecret(ctx, targetClient, secret)
+	if err == nil || !apierrors.IsNotFound(errors.Cause(err)) {
+		return err
+	}
+
+	if err := targetClient.Create(ctx, secret); err != nil {
+		return errors.Wrapf(err, "could not create secret '%s/%s'", secret.Namespace, secret.GetName())
+	}
+	return nil
+}
+
 // updateCopySecret updates a copied destination Secret
-func (r *ReconcileCopy) updateCopySecret(ctx context.Context, secret *corev1.Secret) error {
+func (r *ReconcileCopy) updateCopySecret(ctx context.Context, targetClient client.Client, secret *corev1.Secret) error {
 	// If this is a copy (lives in a different namespace), we only do an update,
 	// since we're not allowed to create, and we don't set a reference, because
 	// cross namespace references are not supported
@@ -256,7 +406,10 @@ func (r *ReconcileCopy) updateCopySecret(ctx context.Context, secret *corev1.Sec
 	uncachedSecret.SetLabels(secret.Labels)
 	uncachedSecret.SetAnnotations(secret.Annotations)
 	uncachedSecret.Object["data"] = secret.Data
-	err := r.client.Update(ctx, uncachedSecret)
+	if secret.Type != "" {
+		uncachedSecret.Object["type"] = string(secret.Type)
+	}
+	err := targetClient.Update(ctx, uncachedSecret)
 
 	if err != nil {
 		return errors.Wrapf(err, "could not update secret '%s/%s'", secret.Namespace, secret.GetName())
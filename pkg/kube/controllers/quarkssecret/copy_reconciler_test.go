@@ -11,7 +11,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -26,6 +28,7 @@ import (
 	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
 	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
 	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
 	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
 )
 
@@ -39,6 +42,8 @@ var _ = Describe("ReconcileCopy", func() {
 		logs                           *observer.ObservedLogs
 		config                         *cfcfg.Config
 		client                         *cfakes.FakeClient
+		remoteClient                   *cfakes.FakeClient
+		clusterClientFunc              qscontroller.ClusterClientFunc
 		generator                      *generatorfakes.FakeGenerator
 		quarksSecret, quarksCopySecret *qsv1a1.QuarksSecret
 		passwordSecret                 *corev1.Secret
@@ -62,6 +67,8 @@ var _ = Describe("ReconcileCopy", func() {
 		ctx = ctxlog.NewParentContext(log)
 		generator = &generatorfakes.FakeGenerator{}
 		client = &cfakes.FakeClient{}
+		remoteClient = &cfakes.FakeClient{}
+		clusterClientFunc = func(kubeconfig []byte) (crc.Client, error) { return remoteClient, nil }
 
 		quarksSecret = &qsv1a1.QuarksSecret{
 			ObjectMeta: metav1.ObjectMeta{
@@ -127,7 +134,7 @@ var _ = Describe("ReconcileCopy", func() {
 	})
 
 	JustBeforeEach(func() {
-		reconciler = qscontroller.NewCopyReconciler(ctx, config, manager, generator, setReferenceFunc)
+		reconciler = qscontroller.NewCopyReconciler(ctx, config, manager, generator, setReferenceFunc, clusterClientFunc)
 	})
 
 	When("the source secret is not found", func() {
@@ -202,4 +209,464 @@ var _ = Describe("ReconcileCopy", func() {
 			Expect(reconcile.Result{}).To(Equal(result))
 		})
 	})
+
+	When("reporting the state of the copies", func() {
+		var statusWriter *cfakes.FakeStatusWriter
+
+		status := func() qsv1a1.QuarksSecretStatus {
+			Expect(statusWriter.PatchCallCount()).To(Equal(1))
+			_, object, _, _ := statusWriter.PatchArgsForCall(0)
+			return object.(*qsv1a1.QuarksSecret).Status
+		}
+
+		BeforeEach(func() {
+			quarksSecret.Finalizers = []string{qsv1a1.Finalizer}
+			quarksSecret.Spec.Copies = append(quarksSecret.Spec.Copies, qsv1a1.Copy{Name: "foreign", Namespace: copyNamespace})
+			statusWriter = &cfakes.FakeStatusWriter{}
+			client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					if nn.Namespace == defaultNamespace {
+						quarksSecret.DeepCopyInto(object)
+						return nil
+					}
+				case *corev1.Secret:
+					switch nn.Name {
+					case secretName:
+						passwordSecret.DeepCopyInto(object)
+						return nil
+					case "generated-secret-copy":
+						object.Name = nn.Name
+						object.Namespace = nn.Namespace
+						object.Annotations = map[string]string{qsv1a1.AnnotationCopyOf: defaultNamespace + "/" + quarksSecretName}
+						return nil
+					case "foreign":
+						object.Name = nn.Name
+						object.Namespace = nn.Namespace
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, "not found")
+			})
+		})
+
+		It("records synced and skipped copies", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			s := status()
+			Expect(s.Copied).To(Equal(pointers.Bool(false)))
+			Expect(s.Copies).To(HaveLen(2))
+			Expect(s.Copies[0].State).To(Equal(qsv1a1.CopyStateSynced))
+			Expect(s.Copies[0].ContentHash).ToNot(BeEmpty())
+			Expect(s.Copies[0].LastSync).ToNot(BeNil())
+			Expect(s.Copies[1].State).To(Equal(qsv1a1.CopyStateSkippedWrongAnnotation))
+			Expect(s.Copies[1].Message).ToNot(BeEmpty())
+		})
+
+		It("records copies without a placeholder", func() {
+			quarksSecret.Spec.Copies[1].Name = "missing"
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status().Copies[1].State).To(Equal(qsv1a1.CopyStateSkippedNoPlaceholder))
+		})
+
+		It("records errors and requeues", func() {
+			client.UpdateReturns(errors.NewForbidden(schema.GroupResource{}, "generated-secret-copy", nil))
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).To(HaveOccurred())
+
+			s := status()
+			Expect(s.Copies[0].State).To(Equal(qsv1a1.CopyStateError))
+			Expect(s.Copies[0].Message).To(ContainSubstring("forbidden"))
+		})
+
+		It("is copied if all copies are synced", func() {
+			quarksSecret.Spec.Copies = quarksSecret.Spec.Copies[:1]
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status().Copied).To(Equal(pointers.Bool(true)))
+		})
+
+		It("doesn't update an unchanged status", func() {
+			quarksSecret.Spec.Copies = quarksSecret.Spec.Copies[:1]
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			quarksSecret.Status = status()
+
+			_, err = reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statusWriter.PatchCallCount()).To(Equal(1))
+		})
+	})
+
+	When("copying to a remote cluster", func() {
+		var (
+			statusWriter *cfakes.FakeStatusWriter
+			kubeconfig   *corev1.Secret
+			placeholder  *corev1.Secret
+		)
+
+		status := func() qsv1a1.QuarksSecretStatus {
+			Expect(statusWriter.PatchCallCount()).To(Equal(1))
+			_, object, _, _ := statusWriter.PatchArgsForCall(0)
+			return object.(*qsv1a1.QuarksSecret).Status
+		}
+
+		BeforeEach(func() {
+			quarksSecret.Finalizers = []string{qsv1a1.Finalizer}
+			quarksSecret.Spec.Copies = []qsv1a1.Copy{{Name: "remote-copy", Namespace: "workload", Cluster: "workload-1"}}
+			kubeconfig = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "workload-1", Namespace: defaultNamespace},
+				Data:       map[string][]byte{qsv1a1.ClusterKubeconfigKey: []byte("kubeconfig")},
+			}
+			placeholder = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "remote-copy",
+					Namespace:   "workload",
+					Annotations: map[string]string{qsv1a1.AnnotationCopyOf: defaultNamespace + "/" + quarksSecretName},
+				},
+			}
+			statusWriter = &cfakes.FakeStatusWriter{}
+			client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					if nn.Namespace == defaultNamespace {
+						quarksSecret.DeepCopyInto(object)
+						return nil
+					}
+				case *corev1.Secret:
+					if nn.Name == secretName {
+						passwordSecret.DeepCopyInto(object)
+						return nil
+					}
+					if kubeconfig != nil && nn.Name == kubeconfig.Name && nn.Namespace == defaultNamespace {
+						kubeconfig.DeepCopyInto(object)
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+			remoteClient.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					return &meta.NoKindMatchError{GroupKind: schema.GroupKind{Kind: "QuarksSecret"}}
+				case *corev1.Secret:
+					if placeholder != nil && nn.Name == placeholder.Name && nn.Namespace == placeholder.Namespace {
+						placeholder.DeepCopyInto(object)
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+		})
+
+		It("writes the copy with the client of the remote cluster", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(remoteClient.UpdateCallCount()).To(Equal(1))
+			_, object, _ := remoteClient.UpdateArgsForCall(0)
+			Expect(object.(*unstructured.Unstructured).GetNamespace()).To(Equal("workload"))
+			Expect(client.UpdateCallCount()).To(Equal(0))
+
+			s := status()
+			Expect(s.Copies).To(HaveLen(1))
+			Expect(s.Copies[0].Cluster).To(Equal("workload-1"))
+			Expect(s.Copies[0].State).To(Equal(qsv1a1.CopyStateSynced))
+		})
+
+		It("skips the copy without a placeholder in the remote cluster", func() {
+			placeholder = nil
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remoteClient.UpdateCallCount()).To(Equal(0))
+			Expect(remoteClient.CreateCallCount()).To(Equal(0))
+			Expect(status().Copies[0].State).To(Equal(qsv1a1.CopyStateSkippedNoPlaceholder))
+		})
+
+		It("reports a missing kubeconfig", func() {
+			kubeconfig = nil
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).To(HaveOccurred())
+
+			s := status()
+			Expect(s.Copies[0].State).To(Equal(qsv1a1.CopyStateError))
+			Expect(s.Copies[0].Message).To(ContainSubstring("kubeconfig secret"))
+		})
+	})
+
+	When("the copy transforms the secret", func() {
+		BeforeEach(func() {
+			quarksSecret.Spec.Copies[0].IncludeKeys = []string{"certificate", "private_key"}
+			quarksSecret.Spec.Copies[0].ExcludeKeys = []string{"private_key"}
+			quarksSecret.Spec.Copies[0].RenameKeys = map[string]string{"certificate": "ca.crt"}
+			quarksSecret.Spec.Copies[0].Labels = map[string]string{"team": "b"}
+			quarksSecret.Spec.Copies[0].Annotations = map[string]string{"owner": "team-a"}
+			passwordSecret.Labels = map[string]string{qsv1a1.LabelKind: qsv1a1.GeneratedSecretKind}
+			passwordSecret.Data = map[string][]byte{
+				"certificate": []byte("cert"),
+				"private_key": []byte("key"),
+				"ca":          []byte("ca"),
+			}
+
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					if nn.Namespace == defaultNamespace {
+						quarksSecret.DeepCopyInto(object)
+					}
+					if nn.Namespace == copyNamespace {
+						quarksCopySecret.DeepCopyInto(object)
+					}
+					return nil
+				case *corev1.Secret:
+					if nn.Name == secretName {
+						passwordSecret.DeepCopyInto(object)
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, "not found")
+			})
+		})
+
+		It("only copies the selected keys with the extra metadata", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.CreateCallCount()).To(Equal(1))
+			_, object, _ := client.CreateArgsForCall(0)
+			copy := object.(*corev1.Secret)
+			Expect(copy.Data).To(Equal(map[string][]byte{"ca.crt": []byte("cert")}))
+			Expect(copy.Labels).To(Equal(map[string]string{"team": "b"}))
+			Expect(copy.Annotations).To(HaveKeyWithValue("owner", "team-a"))
+			Expect(copy.Annotations).To(HaveKeyWithValue(qsv1a1.AnnotationCopyOf, defaultNamespace+"/"+quarksSecretName))
+			Expect(passwordSecret.Labels).ToNot(HaveKey("team"))
+		})
+
+		It("filters and renames the previous credentials like their keys", func() {
+			passwordSecret.Data["previous_certificate"] = []byte("old-cert")
+			passwordSecret.Data["previous_private_key"] = []byte("old-key")
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, object, _ := client.CreateArgsForCall(0)
+			Expect(object.(*corev1.Secret).Data).To(Equal(map[string][]byte{
+				"ca.crt":          []byte("cert"),
+				"previous_ca.crt": []byte("old-cert"),
+			}))
+		})
+
+		It("doesn't copy the metadata of generated secrets", func() {
+			passwordSecret.Annotations = map[string]string{
+				qsv1a1.AnnotationContentHash:     "hash",
+				qsv1a1.AnnotationPreviousExpires: "2020-10-01T10:00:00Z",
+				"description":                    "the ca",
+			}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, object, _ := client.CreateArgsForCall(0)
+			copy := object.(*corev1.Secret)
+			Expect(copy.Labels).ToNot(HaveKey(qsv1a1.LabelKind))
+			Expect(copy.Annotations).ToNot(HaveKey(qsv1a1.AnnotationContentHash))
+			Expect(copy.Annotations).ToNot(HaveKey(qsv1a1.AnnotationPreviousExpires))
+			Expect(copy.Annotations).To(HaveKeyWithValue("description", "the ca"))
+		})
+
+		It("sets the type of the copy", func() {
+			quarksSecret.Spec.Copies[0].Type = corev1.SecretTypeTLS
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.CreateCallCount()).To(Equal(1))
+			_, object, _ := client.CreateArgsForCall(0)
+			Expect(object.(*corev1.Secret).Type).To(Equal(corev1.SecretTypeTLS))
+		})
+	})
+
+	When("a copy was removed from the spec", func() {
+		BeforeEach(func() {
+			quarksSecret.Status.Copies = []qsv1a1.CopyStatus{{Name: "dropped-copy", Namespace: copyNamespace}}
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					if nn.Namespace == defaultNamespace {
+						quarksSecret.DeepCopyInto(object)
+						return nil
+					}
+				case *corev1.Secret:
+					switch nn.Name {
+					case secretName:
+						passwordSecret.DeepCopyInto(object)
+						return nil
+					case "dropped-copy":
+						object.Name = nn.Name
+						object.Namespace = nn.Namespace
+						object.Annotations = map[string]string{qsv1a1.AnnotationCopyOf: defaultNamespace + "/" + quarksSecretName}
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, "not found")
+			})
+		})
+
+		It("deletes the dropped copy", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.DeleteCallCount()).To(Equal(1))
+			_, object, _ := client.DeleteArgsForCall(0)
+			Expect(object.(*corev1.Secret).Name).To(Equal("dropped-copy"))
+		})
+
+		It("removes the copy-of annotation with the orphan policy", func() {
+			quarksSecret.Spec.DeletionPolicy = qsv1a1.DeletionPolicyOrphan
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.DeleteCallCount()).To(Equal(0))
+
+			updated := false
+			for i := 0; i < client.UpdateCallCount(); i++ {
+				_, object, _ := client.UpdateArgsForCall(i)
+				if secret, ok := object.(*corev1.Secret); ok && secret.Name == "dropped-copy" {
+					Expect(secret.Annotations).ToNot(HaveKey(qsv1a1.AnnotationCopyOf))
+					updated = true
+				}
+			}
+			Expect(updated).To(BeTrue())
+		})
+	})
+
+	When("copying to namespaces selected by label", func() {
+		var (
+			policies []qsv1a1.QuarksSecretCopyPolicy
+			existing map[string]*corev1.Secret
+		)
+
+		BeforeEach(func() {
+			quarksSecret.Finalizers = []string{qsv1a1.Finalizer}
+			quarksSecret.Spec.Copies = nil
+			quarksSecret.Spec.CopySelector = &qsv1a1.CopySelector{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			}
+			policies = []qsv1a1.QuarksSecretCopyPolicy{{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+				Spec: qsv1a1.QuarksSecretCopyPolicySpec{
+					SourceNamespaces:        []string{defaultNamespace},
+					TargetNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				},
+			}}
+			existing = map[string]*corev1.Secret{secretName + "/" + defaultNamespace: passwordSecret}
+
+			namespaces := []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: defaultNamespace, Labels: map[string]string{"team": "a"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: copyNamespace, Labels: map[string]string{"team": "a"}}},
+			}
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					if nn.Namespace == defaultNamespace {
+						quarksSecret.DeepCopyInto(object)
+						return nil
+					}
+				case *corev1.Namespace:
+					for _, ns := range namespaces {
+						if ns.Name == nn.Name {
+							ns.DeepCopyInto(object)
+							return nil
+						}
+					}
+				case *corev1.Secret:
+					if secret, ok := existing[nn.Name+"/"+nn.Namespace]; ok {
+						secret.DeepCopyInto(object)
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+			client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+				switch object := object.(type) {
+				case *corev1.NamespaceList:
+					object.Items = namespaces
+				case *qsv1a1.QuarksSecretCopyPolicyList:
+					object.Items = policies
+				}
+				return nil
+			})
+		})
+
+		It("creates the copy without a placeholder, if a policy allows it", func() {
+			client.UpdateCalls(func(context context.Context, object runtime.Object, _ ...crc.UpdateOption) error {
+				if _, ok := object.(*unstructured.Unstructured); ok {
+					return errors.NewNotFound(schema.GroupResource{}, "not found")
+				}
+				return nil
+			})
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.CreateCallCount()).To(Equal(1))
+			_, object, _ := client.CreateArgsForCall(0)
+			copy := object.(*corev1.Secret)
+			Expect(copy.Name).To(Equal(secretName))
+			Expect(copy.Namespace).To(Equal(copyNamespace))
+			Expect(copy.Annotations).To(HaveKeyWithValue(qsv1a1.AnnotationCopyOf, defaultNamespace+"/"+quarksSecretName))
+		})
+
+		It("skips the copy without a policy", func() {
+			policies = nil
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(0))
+			Expect(client.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("doesn't overwrite a secret, which is not a copy", func() {
+			existing[secretName+"/"+copyNamespace] = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: copyNamespace},
+			}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(0))
+			Expect(client.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("removes the copy, if the namespace is no longer selected", func() {
+			quarksSecret.Spec.CopySelector.NamespaceSelector.MatchLabels["team"] = "b"
+			quarksSecret.Status.Copies = []qsv1a1.CopyStatus{{Name: secretName, Namespace: copyNamespace}}
+			existing[secretName+"/"+copyNamespace] = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        secretName,
+					Namespace:   copyNamespace,
+					Annotations: map[string]string{qsv1a1.AnnotationCopyOf: defaultNamespace + "/" + quarksSecretName},
+				},
+			}
+			client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+				return nil
+			})
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.DeleteCallCount()).To(Equal(1))
+			_, object, _ := client.DeleteArgsForCall(0)
+			Expect(object.(*corev1.Secret).Namespace).To(Equal(copyNamespace))
+		})
+	})
 })
@@ -0,0 +1,193 @@
+package quarkssecret
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// desiredCopies returns the copies from the spec and a copy for every
+// namespace matching the copy selector
+func desiredCopies(ctx context.Context, client crc.Client, qsec *qsv1a1.QuarksSecret) ([]qsv1a1.Copy, error) {
+	copies := append([]qsv1a1.Copy{}, qsec.Spec.Copies...)
+	if qsec.Spec.CopySelector == nil {
+		return copies, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(qsec.Spec.CopySelector.NamespaceSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid copy selector of QuarksSecret '%s'", qsec.GetNamespacedName())
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	err = client.List(ctx, namespaces, crc.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list namespaces")
+	}
+
+	name := qsec.Spec.CopySelector.Name
+	if name == "" {
+		name = qsec.Spec.SecretName
+	}
+	for _, ns := range namespaces.Items {
+		copy := qsv1a1.Copy{
+			Name:        name,
+			Namespace:   ns.Name,
+			IncludeKeys: qsec.Spec.CopySelector.IncludeKeys,
+			ExcludeKeys: qsec.Spec.CopySelector.ExcludeKeys,
+			RenameKeys:  qsec.Spec.CopySelector.RenameKeys,
+			Type:        qsec.Spec.CopySelector.Type,
+			Labels:      qsec.Spec.CopySelector.Labels,
+			Annotations: qsec.Spec.CopySelector.Annotations,
+		}
+		if ns.Name == qsec.Namespace || containsSpecCopy(copies, copy) {
+			continue
+		}
+		copies = append(copies, copy)
+	}
+
+	return copies, nil
+}
+
+// copyAllowedByPolicy returns true if a QuarksSecretCopyPolicy allows the
+// namespace of the quarks secret to write into the target namespace.
+// Existing secrets, which are not a copy of the quarks secret, are never
+// overwritten.
+func copyAllowedByPolicy(ctx context.Context, client crc.Client, qsec *qsv1a1.QuarksSecret, copy qsv1a1.Copy) (bool, error) {
+	allowed, err := namespaceAllowedByPolicy(ctx, client, qsec, copy.Namespace)
+	if err != nil || !allowed {
+		return false, err
+	}
+
+	secret := &corev1.Secret{}
+	err = client.Get(ctx, types.NamespacedName{Name: copy.Name, Namespace: copy.Namespace}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, errors.Wrapf(err, "could not get target secret '%s'", copy.String())
+	}
+
+	return validateAnnotation(ctx, qsec, secret.GetAnnotations(), qsec.GetNamespacedName()), nil
+}
+
+// namespaceAllowedByPolicy returns true if a QuarksSecretCopyPolicy allows
+// the namespace of the quarks secret to write into the target namespace
+func namespaceAllowedByPolicy(ctx context.Context, client crc.Client, qsec *qsv1a1.QuarksSecret, namespace string) (bool, error) {
+	target := &corev1.Namespace{}
+	err := client.Get(ctx, types.NamespacedName{Name: namespace}, target)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "could not get target namespace '%s'", namespace)
+	}
+
+	policies := &qsv1a1.QuarksSecretCopyPolicyList{}
+	err = client.List(ctx, policies)
+	if err != nil {
+		return false, errors.Wrap(err, "could not list copy policies")
+	}
+
+	for _, policy := range policies.Items {
+		if policyAllows(ctx, &policy, qsec.Namespace, target) {
+			ctxlog.Debugf(ctx, "Copy policy '%s' allows writes from namespace '%s' to '%s'", policy.Name, qsec.Namespace, namespace)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// policyAllows returns true if the policy allows the source namespace to
+// write into the target namespace
+func policyAllows(ctx context.Context, policy *qsv1a1.QuarksSecretCopyPolicy, source string, target *corev1.Namespace) bool {
+	found := false
+	for _, ns := range policy.Spec.SourceNamespaces {
+		if ns == source {
+			found = true
+			break
+		}
+	}
+	if !found || policy.Spec.TargetNamespaceSelector == nil {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.TargetNamespaceSelector)
+	if err != nil {
+		ctxlog.Errorf(ctx, "Invalid target namespace selector in copy policy '%s': %v", policy.Name, err)
+		return false
+	}
+	return selector.Matches(labels.Set(target.Labels))
+}
+
+// copySelectorMatches returns true if the copy selector of the quarks secret
+// selects the namespace
+func copySelectorMatches(qsec *qsv1a1.QuarksSecret, ns metav1.Object) bool {
+	if qsec.Spec.CopySelector == nil || qsec.Namespace == ns.GetName() {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(qsec.Spec.CopySelector.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(ns.GetLabels()))
+}
+
+// listCopySelectorReconciles lists all quarks secrets, whose copy selector
+// selects one of the namespaces
+func listCopySelectorReconciles(ctx context.Context, client crc.Client, namespaces ...metav1.Object) ([]reconcile.Request, error) {
+	quarksSecretList := &qsv1a1.QuarksSecretList{}
+	err := client.List(ctx, quarksSecretList)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list QuarksSecrets")
+	}
+
+	result := []reconcile.Request{}
+	for i := range quarksSecretList.Items {
+		qsec := &quarksSecretList.Items[i]
+		for _, ns := range namespaces {
+			if copySelectorMatches(qsec, ns) {
+				result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: qsec.Name, Namespace: qsec.Namespace}})
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// listCopyReconciles lists all quarks secrets, which have copies
+func listCopyReconciles(ctx context.Context, client crc.Client) ([]reconcile.Request, error) {
+	quarksSecretList := &qsv1a1.QuarksSecretList{}
+	err := client.List(ctx, quarksSecretList)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list QuarksSecrets")
+	}
+
+	result := []reconcile.Request{}
+	for _, qsec := range quarksSecretList.Items {
+		if len(qsec.Spec.Copies) > 0 || qsec.Spec.CopySelector != nil {
+			result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: qsec.Name, Namespace: qsec.Namespace}})
+		}
+	}
+	return result, nil
+}
+
+func containsSpecCopy(copies []qsv1a1.Copy, copy qsv1a1.Copy) bool {
+	for _, c := range copies {
+		if c.Name == copy.Name && c.Namespace == copy.Namespace && c.Cluster == copy.Cluster {
+			return true
+		}
+	}
+	return false
+}
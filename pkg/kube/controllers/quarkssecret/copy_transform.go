@@ -0,0 +1,123 @@
+package quarkssecret
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// sourceOnlyAnnotations are annotations of the generated secret, which are
+// not copied. They would make the reconcilers of generated secrets, e.g.
+// drift detection and the removal of previous credentials, treat the copies
+// as generated secrets.
+var sourceOnlyAnnotations = []string{
+	qsv1a1.AnnotationContentHash,
+	qsv1a1.AnnotationPreviousExpires,
+	qsv1a1.AnnotationVersion,
+	qsv1a1.AnnotationVersionReason,
+}
+
+// transformCopy returns the copy of the generated secret with the keys
+// filtered and renamed, the type set and the extra labels and annotations
+// added, as defined by the copy. The previous values of credentials are
+// filtered and renamed like their keys.
+func transformCopy(source *corev1.Secret, copy qsv1a1.Copy) *corev1.Secret {
+	// the keys, whose previous values are kept during a grace period
+	currentKeys := map[string]string{}
+	for key, previousKey := range previousKeys {
+		currentKeys[previousKey] = key
+	}
+
+	data := map[string][]byte{}
+	for key, value := range source.Data {
+		currentKey, isPrevious := currentKeys[key]
+		if !isPrevious {
+			currentKey = key
+		}
+
+		if len(copy.IncludeKeys) > 0 && !contains(copy.IncludeKeys, currentKey) && !contains(copy.IncludeKeys, key) {
+			continue
+		}
+		if contains(copy.ExcludeKeys, currentKey) || contains(copy.ExcludeKeys, key) {
+			continue
+		}
+		if target, ok := copy.RenameKeys[currentKey]; ok {
+			key = target
+			if isPrevious {
+				key = "previous_" + target
+			}
+		}
+		data[key] = value
+	}
+
+	secretType := copy.Type
+	if secretType == "" {
+		secretType = source.Type
+	}
+
+	labels := map[string]string{}
+	for key, value := range source.Labels {
+		if key == qsv1a1.LabelKind {
+			continue
+		}
+		labels[key] = value
+	}
+	for key, value := range copy.Labels {
+		labels[key] = value
+	}
+
+	annotations := map[string]string{}
+	for key, value := range source.Annotations {
+		if contains(sourceOnlyAnnotations, key) {
+			continue
+		}
+		annotations[key] = value
+	}
+	for key, value := range copy.Annotations {
+		annotations[key] = value
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        copy.Name,
+			Namespace:   copy.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Type: secretType,
+		Data: data,
+	}
+}
+
+// copyTypeChanged deletes an existing copy, whose type differs from the
+// requested type, so it can be recreated. The type of a secret is
+// immutable.
+func copyTypeChanged(ctx context.Context, c crc.Client, secret *corev1.Secret) error {
+	existing := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, existing)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "could not get copy '%s/%s'", secret.Namespace, secret.Name)
+	}
+
+	if existing.Type == "" || existing.Type == secret.Type {
+		return nil
+	}
+
+	ctxlog.Infof(ctx, "Recreating copy '%s/%s' to change its type from '%s' to '%s'", secret.Namespace, secret.Name, existing.Type, secret.Type)
+	if err := c.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "could not delete copy '%s/%s'", secret.Namespace, secret.Name)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package quarkssecret
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// namespaceDefaults returns the generator defaults of the operator,
+// overridden by the QuarksSecretDefaults of the namespace. The bool is false
+// if the namespace has none.
+func (r *ReconcileQuarksSecret) namespaceDefaults(ctx context.Context, namespace string) (credsgen.Defaults, bool, error) {
+	list := &qsv1a1.QuarksSecretDefaultsList{}
+	err := r.client.List(ctx, list, client.InNamespace(namespace))
+	if err != nil {
+		return r.defaults, false, errors.Wrapf(err, "could not list quarks secret defaults in namespace '%s'", namespace)
+	}
+
+	switch len(list.Items) {
+	case 0:
+		return r.defaults, false, nil
+	case 1:
+	default:
+		return r.defaults, false, errors.Errorf("namespace '%s' has %d quarks secret defaults, only one is allowed", namespace, len(list.Items))
+	}
+
+	qdef := &list.Items[0]
+	override := credsgen.Defaults{
+		KeyAlgorithm:      qdef.Spec.KeyAlgorithm,
+		KeySize:           qdef.Spec.KeySize,
+		CertificateExpiry: qdef.Spec.CertificateExpiry,
+	}
+	if qdef.Spec.PasswordPolicy != nil {
+		override.PasswordLength = qdef.Spec.PasswordPolicy.Length
+		override.PasswordCharacters = qdef.Spec.PasswordPolicy.Characters
+	}
+
+	defaults := r.defaults.Override(override)
+	if err := defaults.Validate(); err != nil {
+		return r.defaults, false, errors.Wrapf(err, "invalid quarks secret defaults '%s'", qdef.GetNamespacedName())
+	}
+	return defaults, true, nil
+}
+
+// generatorFor returns the generator backend referenced by the quarks
+// secret, or the default generator. Without backends, the generator is used
+// for all references. It uses the defaults of the namespace.
+func (r *ReconcileQuarksSecret) generatorFor(ctx context.Context, qsec *qsv1a1.QuarksSecret) (credsgen.Generator, error) {
+	generator := r.generator
+	if qsec.Spec.GeneratorRef != "" && r.generators != nil {
+		g, err := r.generators.Get(qsec.Spec.GeneratorRef)
+		if err != nil {
+			return nil, ctxlog.WithEvent(qsec, "GeneratorError").Errorf(ctx, "Error getting generator for QuarksSecret '%s': %s", qsec.GetNamespacedName(), err)
+		}
+		generator = g
+	}
+
+	defaults, ok, err := r.namespaceDefaults(ctx, qsec.Namespace)
+	if err != nil {
+		return nil, ctxlog.WithEvent(qsec, "DefaultsError").Errorf(ctx, "Error reading generator defaults: %s", err)
+	}
+	if !ok {
+		return generator, nil
+	}
+
+	g, ok := generator.(credsgen.DefaultsGenerator)
+	if !ok {
+		ctxlog.Debugf(ctx, "Generator doesn't support defaults, ignoring quarks secret defaults in namespace '%s'", qsec.Namespace)
+		return generator, nil
+	}
+	return g.WithDefaults(defaults), nil
+}
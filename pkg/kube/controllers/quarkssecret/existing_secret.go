@@ -0,0 +1,244 @@
+package quarkssecret
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// handleExistingSecret applies the existing secret policy, if a secret with
+// the secret name exists, which was not generated by the operator. It
+// returns true if the secret must not be generated.
+func (r *ReconcileQuarksSecret) handleExistingSecret(ctx context.Context, qsec *qsv1a1.QuarksSecret) (bool, error) {
+	secretName := qsec.Spec.SecretName
+	existingSecret := &corev1.Secret{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: qsec.GetNamespace()}, existingSecret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "could not get secret")
+	}
+
+	// generate if the secret was created by the operator
+	if existingSecret.GetLabels()[qsv1a1.LabelKind] == qsv1a1.GeneratedSecretKind {
+		return false, nil
+	}
+	ctxlog.Debugf(ctx, "Existing secret %s/%s doesn't have a label %s=%s",
+		existingSecret.GetNamespace(),
+		existingSecret.GetName(),
+		qsv1a1.LabelKind,
+		qsv1a1.GeneratedSecretKind,
+	)
+
+	switch qsec.Spec.ExistingSecretPolicy {
+	case qsv1a1.ExistingSecretPolicyAdopt:
+		if err := r.adoptSecret(ctx, qsec, existingSecret); err != nil {
+			return true, err
+		}
+		ctxlog.WithEvent(qsec, "AdoptSecret").Infof(ctx, "Adopted existing secret '%s/%s'", qsec.Namespace, secretName)
+		qsec.Status.SetCondition(qsv1a1.QuarksSecretCondition{
+			Type:    qsv1a1.ConditionExistingSecret,
+			Status:  corev1.ConditionTrue,
+			Reason:  qsv1a1.ExistingSecretReasonAdopted,
+			Message: "the existing secret is managed by the operator",
+		})
+	case qsv1a1.ExistingSecretPolicyValidate:
+		problems, err := r.validateExistingSecret(ctx, qsec, existingSecret)
+		if err != nil {
+			return true, err
+		}
+		if len(problems) > 0 {
+			ctxlog.WithEvent(qsec, "InvalidExistingSecret").Errorf(ctx, "Existing secret '%s/%s' doesn't match the spec: %s", qsec.Namespace, secretName, strings.Join(problems, "; "))
+			qsec.Status.SetCondition(qsv1a1.QuarksSecretCondition{
+				Type:    qsv1a1.ConditionExistingSecret,
+				Status:  corev1.ConditionFalse,
+				Reason:  qsv1a1.ExistingSecretReasonInvalid,
+				Message: strings.Join(problems, "; "),
+			})
+			break
+		}
+		ctxlog.WithEvent(qsec, "ValidExistingSecret").Infof(ctx, "Existing secret '%s/%s' matches the spec", qsec.Namespace, secretName)
+		qsec.Status.SetCondition(qsv1a1.QuarksSecretCondition{
+			Type:    qsv1a1.ConditionExistingSecret,
+			Status:  corev1.ConditionTrue,
+			Reason:  qsv1a1.ExistingSecretReasonValid,
+			Message: "the existing secret matches the spec",
+		})
+	default:
+		ctxlog.WithEvent(qsec, "SkipCreation").Infof(ctx, "Skip creation: Secret '%s/%s' already exists and it's not generated", qsec.Namespace, secretName)
+		qsec.Status.SetCondition(qsv1a1.QuarksSecretCondition{
+			Type:    qsv1a1.ConditionExistingSecret,
+			Status:  corev1.ConditionTrue,
+			Reason:  qsv1a1.ExistingSecretReasonSkipped,
+			Message: "the existing secret was not generated and is left alone",
+		})
+	}
+
+	return true, nil
+}
+
+// adoptSecret takes ownership of an existing secret, so it is managed like a
+// generated secret from now on
+func (r *ReconcileQuarksSecret) adoptSecret(ctx context.Context, qsec *qsv1a1.QuarksSecret, secret *corev1.Secret) error {
+	if err := r.setReference(qsec, secret, r.scheme); err != nil {
+		return errors.Wrapf(err, "error setting owner for secret '%s' to QuarksSecret '%s'", secret.GetName(), qsec.GetNamespacedName())
+	}
+
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	secret.Labels[qsv1a1.LabelKind] = qsv1a1.GeneratedSecretKind
+
+	data := versionData(secret)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[qsv1a1.AnnotationContentHash] = contentHash(data)
+
+	if err := r.client.Update(ctx, secret); err != nil {
+		return errors.Wrapf(err, "could not adopt secret '%s/%s'", secret.Namespace, secret.Name)
+	}
+
+	return r.recordVersion(ctx, qsec, secret.Type, data, versionReasonAdoption)
+}
+
+// validateExistingSecret checks that the existing secret has the keys the
+// type needs. Certificates have to match the request and passwords the
+// length of the generator defaults.
+func (r *ReconcileQuarksSecret) validateExistingSecret(ctx context.Context, qsec *qsv1a1.QuarksSecret, secret *corev1.Secret) ([]string, error) {
+	data := versionData(secret)
+
+	keys := []string{}
+	if handler, ok := r.handlers.handlerFor(qsec.Spec.Type); ok {
+		keys = handler.Outputs(qsec)
+	}
+
+	problems := []string{}
+	for _, key := range keys {
+		if len(data[key]) == 0 {
+			problems = append(problems, fmt.Sprintf("missing key '%s'", key))
+		}
+	}
+	if len(problems) > 0 {
+		return problems, nil
+	}
+
+	defaults, _, err := r.namespaceDefaults(ctx, qsec.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	switch qsec.Spec.Type {
+	case qsv1a1.Password:
+		if len(data["password"]) < defaults.PasswordLength {
+			problems = append(problems, fmt.Sprintf("password is shorter than %d characters", defaults.PasswordLength))
+		}
+	case qsv1a1.BasicAuth:
+		username := qsec.Spec.Request.BasicAuthRequest.Username
+		if username != "" && string(data["username"]) != username {
+			problems = append(problems, fmt.Sprintf("username is not '%s'", username))
+		}
+		if len(data["password"]) < defaults.PasswordLength {
+			problems = append(problems, fmt.Sprintf("password is shorter than %d characters", defaults.PasswordLength))
+		}
+	case qsv1a1.Certificate:
+		return r.validateCertificate(ctx, qsec, data["certificate"], data["private_key"])
+	case qsv1a1.TLS:
+		return r.validateCertificate(ctx, qsec, data["tls.crt"], data["tls.key"])
+	}
+
+	return problems, nil
+}
+
+// validateCertificate checks the certificate matches the private key, the
+// requested common name and alternative names, and is signed by the CA
+func (r *ReconcileQuarksSecret) validateCertificate(ctx context.Context, qsec *qsv1a1.QuarksSecret, certPEM []byte, keyPEM []byte) ([]string, error) {
+	request := qsec.Spec.Request.CertificateRequest
+	problems := []string{}
+
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return append(problems, err.Error()), nil
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		problems = append(problems, "private key doesn't match the certificate")
+	}
+
+	if request.CommonName != "" && cert.Subject.CommonName != request.CommonName {
+		problems = append(problems, fmt.Sprintf("common name is '%s', not '%s'", cert.Subject.CommonName, request.CommonName))
+	}
+	for _, name := range request.AlternativeNames {
+		if !hasAlternativeName(cert, name) {
+			problems = append(problems, fmt.Sprintf("missing alternative name '%s'", name))
+		}
+	}
+	if request.IsCA != cert.IsCA {
+		problems = append(problems, fmt.Sprintf("certificate is a CA: %t, requested: %t", cert.IsCA, request.IsCA))
+	}
+
+	if request.SignerType == qsv1a1.ClusterSigner || request.CARef.Name == "" {
+		return problems, nil
+	}
+
+	caSecret := &corev1.Secret{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: request.CARef.Name, Namespace: qsec.Namespace}, caSecret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return append(problems, fmt.Sprintf("CA secret '%s' not found", request.CARef.Name)), nil
+		}
+		return problems, errors.Wrap(err, "getting CA secret")
+	}
+	ca, err := parseCertificate(caSecret.Data[request.CARef.Key])
+	if err != nil {
+		return append(problems, fmt.Sprintf("CA: %s", err)), nil
+	}
+	if err := cert.CheckSignatureFrom(ca); err != nil {
+		problems = append(problems, fmt.Sprintf("certificate is not signed by CA '%s'", request.CARef.Name))
+	}
+
+	return problems, nil
+}
+
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid certificate")
+	}
+	return cert, nil
+}
+
+func hasAlternativeName(cert *x509.Certificate, name string) bool {
+	if ip := net.ParseIP(name); ip != nil {
+		for _, certIP := range cert.IPAddresses {
+			if certIP.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, dnsName := range cert.DNSNames {
+		if strings.EqualFold(dnsName, name) {
+			return true
+		}
+	}
+	return cert.VerifyHostname(name) == nil
+}
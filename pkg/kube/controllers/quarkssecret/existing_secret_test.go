@@ -0,0 +1,212 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	inmemorygenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/in_memory_generator"
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ReconcileQuarksSecret with an existing secret", func() {
+	var (
+		manager      *cfakes.FakeManager
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		client       *cfakes.FakeClient
+		statusWriter *cfakes.FakeStatusWriter
+		generator    credsgen.Generator
+		qSecret      *qsv1a1.QuarksSecret
+		secrets      map[string]*corev1.Secret
+	)
+
+	condition := func() *qsv1a1.QuarksSecretCondition {
+		Expect(statusWriter.PatchCallCount()).To(Equal(1))
+		_, object, _, _ := statusWriter.PatchArgsForCall(0)
+		return object.(*qsv1a1.QuarksSecret).Status.GetCondition(qsv1a1.ConditionExistingSecret)
+	}
+
+	BeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		generator = inmemorygenerator.NewInMemoryGenerator(log)
+		manager = &cfakes.FakeManager{}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+		qSecret = &qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "foo",
+				Namespace:  "default",
+				Finalizers: []string{qsv1a1.Finalizer},
+			},
+			Spec: qsv1a1.QuarksSecretSpec{
+				Type:       qsv1a1.Password,
+				SecretName: "user-provided",
+			},
+		}
+		secrets = map[string]*corev1.Secret{
+			"user-provided": {
+				ObjectMeta: metav1.ObjectMeta{Name: "user-provided", Namespace: "default"},
+				Data:       map[string][]byte{"password": []byte("short")},
+			},
+		}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			switch object := object.(type) {
+			case *qsv1a1.QuarksSecret:
+				qSecret.DeepCopyInto(object)
+				return nil
+			case *corev1.Secret:
+				if secret, ok := secrets[nn.Name]; ok {
+					secret.DeepCopyInto(object)
+					return nil
+				}
+			}
+			return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+		statusWriter = &cfakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		manager.GetClientReturns(client)
+	})
+
+	JustBeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		ctx := ctxlog.NewParentContext(log)
+		setReference := func(owner, object metav1.Object, scheme *runtime.Scheme) error {
+			object.SetOwnerReferences([]metav1.OwnerReference{{Name: owner.GetName()}})
+			return nil
+		}
+		options := qscontroller.Options{Config: &cfcfg.Config{CtxTimeOut: 10 * time.Second}, Generator: generator}
+		reconciler = qscontroller.NewQuarksSecretReconciler(ctx, options, manager, setReference, nil)
+	})
+
+	It("skips the secret by default", func() {
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.CreateCallCount()).To(Equal(0))
+		Expect(client.UpdateCallCount()).To(Equal(0))
+		Expect(condition().Reason).To(Equal(qsv1a1.ExistingSecretReasonSkipped))
+	})
+
+	It("adopts the secret", func() {
+		qSecret.Spec.ExistingSecretPolicy = qsv1a1.ExistingSecretPolicyAdopt
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.UpdateCallCount()).To(Equal(1))
+		_, object, _ := client.UpdateArgsForCall(0)
+		adopted := object.(*corev1.Secret)
+		Expect(adopted.Data).To(Equal(map[string][]byte{"password": []byte("short")}))
+		Expect(adopted.Labels).To(HaveKeyWithValue(qsv1a1.LabelKind, qsv1a1.GeneratedSecretKind))
+		Expect(adopted.Annotations).To(HaveKey(qsv1a1.AnnotationContentHash))
+		Expect(adopted.OwnerReferences).To(HaveLen(1))
+
+		Expect(condition().Reason).To(Equal(qsv1a1.ExistingSecretReasonAdopted))
+	})
+
+	Context("when validating the secret", func() {
+		BeforeEach(func() {
+			qSecret.Spec.ExistingSecretPolicy = qsv1a1.ExistingSecretPolicyValidate
+		})
+
+		It("reports passwords, which are too short", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.UpdateCallCount()).To(Equal(0))
+
+			c := condition()
+			Expect(c.Status).To(Equal(corev1.ConditionFalse))
+			Expect(c.Reason).To(Equal(qsv1a1.ExistingSecretReasonInvalid))
+			Expect(c.Message).To(ContainSubstring("password is shorter than 64 characters"))
+		})
+
+		It("accepts valid passwords", func() {
+			secrets["user-provided"].Data["password"] = []byte(strings.Repeat("x", 64))
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(condition().Reason).To(Equal(qsv1a1.ExistingSecretReasonValid))
+		})
+
+		It("reports missing keys", func() {
+			qSecret.Spec.Type = qsv1a1.TLS
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(condition().Message).To(Equal("missing key 'tls.crt'; missing key 'tls.key'"))
+		})
+
+		Context("with a certificate", func() {
+			newCA := func(name string) *corev1.Secret {
+				ca, err := generator.GenerateCertificate(name, credsgen.CertificateGenerationRequest{CommonName: name, IsCA: true})
+				Expect(err).ToNot(HaveOccurred())
+				return &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Data:       map[string][]byte{"certificate": ca.Certificate, "private_key": ca.PrivateKey},
+				}
+			}
+
+			BeforeEach(func() {
+				secrets["ca"] = newCA("ca")
+				secrets["other-ca"] = newCA("other-ca")
+
+				cert, err := generator.GenerateCertificate("cert", credsgen.CertificateGenerationRequest{
+					CommonName:       "example.com",
+					AlternativeNames: []string{"foo.example.com", "10.0.0.1"},
+					CA: credsgen.Certificate{
+						IsCA:        true,
+						Certificate: secrets["ca"].Data["certificate"],
+						PrivateKey:  secrets["ca"].Data["private_key"],
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				secrets["user-provided"].Data = map[string][]byte{"certificate": cert.Certificate, "private_key": cert.PrivateKey}
+
+				qSecret.Spec.Type = qsv1a1.Certificate
+				qSecret.Spec.Request.CertificateRequest = qsv1a1.CertificateRequest{
+					CommonName:       "example.com",
+					AlternativeNames: []string{"foo.example.com", "10.0.0.1"},
+					CARef:            qsv1a1.SecretReference{Name: "ca", Key: "certificate"},
+					CAKeyRef:         qsv1a1.SecretReference{Name: "ca", Key: "private_key"},
+				}
+			})
+
+			It("accepts a certificate matching the request", func() {
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(condition().Reason).To(Equal(qsv1a1.ExistingSecretReasonValid))
+			})
+
+			It("reports missing alternative names and another CA", func() {
+				qSecret.Spec.Request.CertificateRequest.AlternativeNames = append(qSecret.Spec.Request.CertificateRequest.AlternativeNames, "bar.example.com")
+				qSecret.Spec.Request.CertificateRequest.CARef.Name = "other-ca"
+
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+
+				c := condition()
+				Expect(c.Reason).To(Equal(qsv1a1.ExistingSecretReasonInvalid))
+				Expect(c.Message).To(Equal("missing alternative name 'bar.example.com'; certificate is not signed by CA 'other-ca'"))
+			})
+		})
+	})
+})
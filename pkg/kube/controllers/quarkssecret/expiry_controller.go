@@ -0,0 +1,143 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// AddExpiry creates a new controller, which reports generated certificates
+// expiring soon
+func AddExpiry(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
+	ctx = ctxlog.NewContextWithRecorder(ctx, "expiry-reconciler", mgr.GetEventRecorderFor("quarks-secret-recorder"))
+	r := NewExpiryReconciler(ctx, config, mgr)
+
+	// Create a new controller
+	c, err := controller.New(ExpiryController, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: options.workers(ExpiryController),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Adding expiry controller to manager failed.")
+	}
+
+	nsPred := newNSPredicate(ctx, mgr.GetClient(), config.MonitoredID)
+
+	// Watch for quarks secrets, which generate certificates
+	p := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return hasCertificate(e.Object.(*qsv1a1.QuarksSecret))
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*qsv1a1.QuarksSecret)
+			o := e.ObjectOld.(*qsv1a1.QuarksSecret)
+
+			if hasCertificate(n) && (n.Spec.SecretName != o.Spec.SecretName || !reflect.DeepEqual(n.Labels, o.Labels)) {
+				ctxlog.Debugf(ctx, "Certificate of QuarksSecret '%s' changed", n.Name)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, options.quarksSecretPredicates(nsPred, p)...)
+	if err != nil {
+		return errors.Wrapf(err, "Watching quarks secrets failed in expiry controller.")
+	}
+
+	// Watch for generated secrets, e.g. after a rotation
+	generated := func(o *corev1.Secret) bool {
+		return o.GetLabels()[qsv1a1.LabelKind] == qsv1a1.GeneratedSecretKind
+	}
+	p = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return generated(e.Object.(*corev1.Secret))
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*corev1.Secret)
+			o := e.ObjectOld.(*corev1.Secret)
+			if generated(n) && n.GetAnnotations()[qsv1a1.AnnotationContentHash] != o.GetAnnotations()[qsv1a1.AnnotationContentHash] {
+				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+					ctx, e.MetaNew, "corev1.Secret",
+					fmt.Sprintf("Update predicate passed for '%s/%s': content hash changed", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+				)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &qsv1a1.QuarksSecret{},
+		IsController: true,
+	}, nsPred, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching secrets failed in expiry controller.")
+	}
+
+	// Watch for notification configs, which change the expiry window
+	p = predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return true },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*qsv1a1.QuarksSecretNotificationConfig)
+			o := e.ObjectOld.(*qsv1a1.QuarksSecretNotificationConfig)
+			return !reflect.DeepEqual(n.Spec.ExpiringSoonBefore, o.Spec.ExpiringSoonBefore) || !reflect.DeepEqual(n.Spec.Selector, o.Spec.Selector)
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecretNotificationConfig{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			reconciles, err := listExpiryReconciles(ctx, mgr.GetClient(), a.Meta.GetNamespace())
+			if err != nil {
+				ctxlog.Errorf(ctx, "Failed to calculate reconciles for notification config '%s': %v", a.Meta.GetName(), err)
+			}
+			for _, request := range reconciles {
+				ctxlog.NewMappingEvent(a.Object).Debug(ctx, request, "QuarksSecret", a.Meta.GetName(), "notification-config")
+			}
+			return reconciles
+		}),
+	}, nsPred, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching notification configs failed in expiry controller.")
+	}
+
+	return nil
+}
+
+// listExpiryReconciles returns the quarks secrets in the namespace, which
+// generate certificates
+func listExpiryReconciles(ctx context.Context, client crc.Client, namespace string) ([]reconcile.Request, error) {
+	list := &qsv1a1.QuarksSecretList{}
+	err := client.List(ctx, list, crc.InNamespace(namespace))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list quarks secrets in namespace '%s'", namespace)
+	}
+
+	result := []reconcile.Request{}
+	for _, qsec := range list.Items {
+		if hasCertificate(&qsec) {
+			result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: qsec.Name, Namespace: qsec.Namespace}})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,174 @@
+package quarkssecret
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// defaultExpiringSoonBefore is how long before a generated certificate
+// expires it is reported as expiring soon, if no notification config sets
+// it
+const defaultExpiringSoonBefore = 30 * 24 * time.Hour
+
+// certificateKeys are the keys, which contain the generated certificate
+var certificateKeys = []string{"certificate", "tls.crt"}
+
+// NewExpiryReconciler returns a new ReconcileExpiry
+func NewExpiryReconciler(ctx context.Context, config *config.Config, mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileExpiry{
+		ctx:    ctx,
+		config: config,
+		client: mgr.GetClient(),
+	}
+}
+
+// ReconcileExpiry reports generated certificates, which expire soon
+type ReconcileExpiry struct {
+	ctx    context.Context
+	client client.Client
+	config *config.Config
+}
+
+// Reconcile reads the expiry of the generated certificate and sets the
+// expiring soon condition, once it is within the window of the notification
+// configs. Until then it requeues for the start of the window.
+func (r *ReconcileExpiry) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	qsec := &qsv1a1.QuarksSecret{}
+
+	// Set the ctx to be Background, as the top-level context for incoming requests.
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.CtxTimeOut)
+	defer cancel()
+
+	ctxlog.Infof(ctx, "Reconciling certificate expiry of QuarksSecret %s", request.NamespacedName)
+	err := r.client.Get(ctx, request.NamespacedName, qsec)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Info(ctx, "Skip reconcile: quarks secret not found")
+			return reconcile.Result{}, nil
+		}
+		ctxlog.Info(ctx, "Error reading the object")
+		return reconcile.Result{}, errors.Wrap(err, "Error reading quarksSecret")
+	}
+
+	if qsec.GetDeletionTimestamp() != nil || !hasCertificate(qsec) {
+		return reconcile.Result{}, nil
+	}
+
+	secret := &corev1.Secret{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: qsec.Spec.SecretName, Namespace: qsec.Namespace}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Debugf(ctx, "Skip reconcile: secret '%s/%s' not found", qsec.Namespace, qsec.Spec.SecretName)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "could not get secret '%s/%s'", qsec.Namespace, qsec.Spec.SecretName)
+	}
+
+	notAfter, err := certificateExpiry(secret)
+	if err != nil {
+		ctxlog.Debugf(ctx, "Skip reconcile: %v", err)
+		return reconcile.Result{}, nil
+	}
+
+	before, err := r.expiringSoonBefore(ctx, qsec)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	remaining := time.Until(notAfter) - before
+	expires := notAfter.UTC().Format(time.RFC3339)
+	condition := qsv1a1.QuarksSecretCondition{
+		Type:    qsv1a1.ConditionExpiringSoon,
+		Status:  corev1.ConditionFalse,
+		Reason:  qsv1a1.ExpiryReasonNotExpiring,
+		Message: fmt.Sprintf("the certificate expires at %s", expires),
+	}
+	if remaining <= 0 {
+		if c := qsec.Status.GetCondition(qsv1a1.ConditionExpiringSoon); c == nil || c.Status != corev1.ConditionTrue {
+			ctxlog.WithEvent(qsec, "ExpiringSoon").Infof(ctx, "Certificate in secret '%s/%s' expires at %s", qsec.Namespace, qsec.Spec.SecretName, expires)
+		}
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = qsv1a1.ExpiryReasonExpiring
+	}
+
+	if c := qsec.Status.GetCondition(qsv1a1.ConditionExpiringSoon); c == nil || c.Status != condition.Status || c.Reason != condition.Reason || c.Message != condition.Message {
+		err = patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+			status.SetCondition(condition)
+		})
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+		}
+	}
+
+	if remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// expiringSoonBefore returns the largest window of the notification configs
+// selecting the quarks secret, or the default
+func (r *ReconcileExpiry) expiringSoonBefore(ctx context.Context, qsec *qsv1a1.QuarksSecret) (time.Duration, error) {
+	configs := &qsv1a1.QuarksSecretNotificationConfigList{}
+	err := r.client.List(ctx, configs, client.InNamespace(qsec.Namespace))
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not list notification configs in namespace '%s'", qsec.Namespace)
+	}
+
+	before := time.Duration(0)
+	for i := range configs.Items {
+		config := &configs.Items[i]
+		if config.Spec.ExpiringSoonBefore == nil || !configSelects(ctx, config, qsec.GetLabels()) {
+			continue
+		}
+		if config.Spec.ExpiringSoonBefore.Duration > before {
+			before = config.Spec.ExpiringSoonBefore.Duration
+		}
+	}
+	if before == 0 {
+		before = defaultExpiringSoonBefore
+	}
+	return before, nil
+}
+
+// hasCertificate returns true if the quarks secret generates a certificate
+func hasCertificate(qsec *qsv1a1.QuarksSecret) bool {
+	return qsec.Spec.Type == qsv1a1.Certificate || qsec.Spec.Type == qsv1a1.TLS
+}
+
+// certificateExpiry returns the end of the validity of the certificate in
+// the secret
+func certificateExpiry(secret *corev1.Secret) (time.Time, error) {
+	for _, key := range certificateKeys {
+		data, ok := secret.Data[key]
+		if !ok {
+			continue
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return time.Time{}, errors.Errorf("key '%s' of secret '%s/%s' is not PEM encoded", key, secret.Namespace, secret.Name)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, errors.Wrapf(err, "could not parse certificate in secret '%s/%s'", secret.Namespace, secret.Name)
+		}
+		return cert.NotAfter, nil
+	}
+	return time.Time{}, errors.Errorf("secret '%s/%s' has no certificate", secret.Namespace, secret.Name)
+}
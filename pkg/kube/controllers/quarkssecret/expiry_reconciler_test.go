@@ -0,0 +1,166 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ReconcileExpiry", func() {
+	var (
+		manager      *cfakes.FakeManager
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		client       *cfakes.FakeClient
+		statusWriter *cfakes.FakeStatusWriter
+		qSecret      *qsv1a1.QuarksSecret
+		secret       *corev1.Secret
+		configs      []qsv1a1.QuarksSecretNotificationConfig
+	)
+
+	certificateValidFor := func(validity time.Duration) []byte {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "example.com"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(validity),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).ToNot(HaveOccurred())
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+
+	updatedCondition := func() *qsv1a1.QuarksSecretCondition {
+		Expect(statusWriter.PatchCallCount()).To(Equal(1))
+		_, object, _, _ := statusWriter.PatchArgsForCall(0)
+		return object.(*qsv1a1.QuarksSecret).Status.GetCondition(qsv1a1.ConditionExpiringSoon)
+	}
+
+	BeforeEach(func() {
+		manager = &cfakes.FakeManager{}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+		qSecret = &qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: qsv1a1.QuarksSecretSpec{
+				Type:       qsv1a1.Certificate,
+				SecretName: "generated-cert",
+			},
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "generated-cert", Namespace: "default"},
+			Data:       map[string][]byte{"certificate": certificateValidFor(90 * 24 * time.Hour)},
+		}
+		configs = nil
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			switch object := object.(type) {
+			case *qsv1a1.QuarksSecret:
+				qSecret.DeepCopyInto(object)
+				return nil
+			case *corev1.Secret:
+				if nn.Name == secret.Name {
+					secret.DeepCopyInto(object)
+					return nil
+				}
+			}
+			return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+		client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+			if object, ok := object.(*qsv1a1.QuarksSecretNotificationConfigList); ok {
+				object.Items = configs
+			}
+			return nil
+		})
+		statusWriter = &cfakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		manager.GetClientReturns(client)
+	})
+
+	JustBeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		ctx := ctxlog.NewParentContext(log)
+		reconciler = qscontroller.NewExpiryReconciler(ctx, &cfcfg.Config{CtxTimeOut: 10 * time.Second}, manager)
+	})
+
+	It("requeues until the certificate expires soon", func() {
+		result, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically("~", 60*24*time.Hour, time.Minute))
+
+		condition := updatedCondition()
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(qsv1a1.ExpiryReasonNotExpiring))
+	})
+
+	It("reports certificates, which expire within the default window", func() {
+		secret.Data["certificate"] = certificateValidFor(10 * 24 * time.Hour)
+
+		result, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		condition := updatedCondition()
+		Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(qsv1a1.ExpiryReasonExpiring))
+	})
+
+	It("uses the window of the notification configs", func() {
+		configs = []qsv1a1.QuarksSecretNotificationConfig{{
+			Spec: qsv1a1.QuarksSecretNotificationConfigSpec{
+				ExpiringSoonBefore: &metav1.Duration{Duration: 100 * 24 * time.Hour},
+			},
+		}}
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updatedCondition().Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("reads the certificate of tls secrets", func() {
+		qSecret.Spec.Type = qsv1a1.TLS
+		secret.Data = map[string][]byte{"tls.crt": certificateValidFor(24 * time.Hour)}
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updatedCondition().Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("skips quarks secrets without certificates", func() {
+		qSecret.Spec.Type = qsv1a1.Password
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(statusWriter.PatchCallCount()).To(Equal(0))
+	})
+})
@@ -0,0 +1,175 @@
+package quarkssecret
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// addFinalizer adds the finalizer to the quarks secret, so its copies are
+// cleaned up on deletion
+func addFinalizer(ctx context.Context, client crc.Client, qsec *qsv1a1.QuarksSecret) error {
+	if controllerutil.ContainsFinalizer(qsec, qsv1a1.Finalizer) {
+		return nil
+	}
+
+	controllerutil.AddFinalizer(qsec, qsv1a1.Finalizer)
+	if err := client.Update(ctx, qsec); err != nil {
+		return errors.Wrapf(err, "could not add finalizer to QuarksSecret '%s'", qsec.GetNamespacedName())
+	}
+	return nil
+}
+
+// finalize applies the deletion policy to the generated secret, the copies
+// and the public outputs, before removing the finalizer
+func (r *ReconcileQuarksSecret) finalize(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
+	if !controllerutil.ContainsFinalizer(qsec, qsv1a1.Finalizer) {
+		return nil
+	}
+
+	policy := qsec.Spec.DeletionPolicy
+	if policy == "" {
+		policy = qsv1a1.DeletionPolicyDelete
+	}
+	ctxlog.Infof(ctx, "Finalizing QuarksSecret '%s' with deletion policy '%s'", qsec.GetNamespacedName(), policy)
+
+	for _, copy := range copiesOf(qsec) {
+		client, err := r.clusters.get(ctx, r.client, qsec.Namespace, copy.Cluster)
+		if err != nil {
+			// don't block the deletion, if the remote cluster is gone
+			ctxlog.WithEvent(qsec, "RemoveCopy").Errorf(ctx, "Skipping removal of copy '%s/%s' in cluster '%s': %v", copy.Namespace, copy.Name, copy.Cluster, err)
+			continue
+		}
+		if err := removeCopy(ctx, client, qsec, copy, policy); err != nil {
+			return err
+		}
+	}
+
+	if policy != qsv1a1.DeletionPolicyOrphan {
+		outputs := qsec.Status.PublicOutputs
+		for _, output := range publicOutputsOf(qsec) {
+			if !containsPublicOutput(outputs, output) {
+				outputs = append(outputs, output)
+			}
+		}
+		for _, output := range outputs {
+			if err := removePublicOutput(ctx, r.client, qsec, output); err != nil {
+				return err
+			}
+		}
+	}
+
+	if policy != qsv1a1.DeletionPolicyDelete {
+		if err := r.retainSecret(ctx, qsec); err != nil {
+			return err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(qsec, qsv1a1.Finalizer)
+	if err := r.client.Update(ctx, qsec); err != nil {
+		return errors.Wrapf(err, "could not remove finalizer from QuarksSecret '%s'", qsec.GetNamespacedName())
+	}
+
+	return nil
+}
+
+// retainSecret removes the owner reference from the generated secret, so
+// it is not garbage collected
+func (r *ReconcileQuarksSecret) retainSecret(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
+	secret := &corev1.Secret{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: qsec.Spec.SecretName, Namespace: qsec.Namespace}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "could not get secret '%s/%s'", qsec.Namespace, qsec.Spec.SecretName)
+	}
+
+	refs := []metav1.OwnerReference{}
+	for _, ref := range secret.OwnerReferences {
+		if ref.UID != qsec.UID {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == len(secret.OwnerReferences) {
+		return nil
+	}
+	secret.OwnerReferences = refs
+
+	if err := r.client.Update(ctx, secret); err != nil {
+		return errors.Wrapf(err, "could not retain secret '%s/%s'", secret.Namespace, secret.Name)
+	}
+	ctxlog.WithEvent(qsec, "RetainSecret").Infof(ctx, "Retained secret '%s/%s'", secret.Namespace, secret.Name)
+
+	return nil
+}
+
+// copiesOf returns the copies from the spec and the copies written before,
+// which might have been removed from the spec
+func copiesOf(qsec *qsv1a1.QuarksSecret) []qsv1a1.CopyStatus {
+	copies := []qsv1a1.CopyStatus{}
+	for _, copy := range qsec.Spec.Copies {
+		copies = append(copies, qsv1a1.CopyStatus{Name: copy.Name, Namespace: copy.Namespace, Cluster: copy.Cluster})
+	}
+	for _, copy := range qsec.Status.Copies {
+		if !specifiesCopy(qsec, copy) {
+			copies = append(copies, copy)
+		}
+	}
+	return copies
+}
+
+// removeCopy deletes a copy of the generated secret, or removes its copy-of
+// annotation for the orphan policy. Secrets, which are not a copy of the
+// quarks secret, are left alone.
+func removeCopy(ctx context.Context, client crc.Client, qsec *qsv1a1.QuarksSecret, copy qsv1a1.CopyStatus, policy qsv1a1.DeletionPolicy) error {
+	secret := &corev1.Secret{}
+	err := client.Get(ctx, types.NamespacedName{Name: copy.Name, Namespace: copy.Namespace}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "could not get copy '%s/%s'", copy.Namespace, copy.Name)
+	}
+
+	if secret.Annotations[qsv1a1.AnnotationCopyOf] != qsec.GetNamespacedName() {
+		ctxlog.Debugf(ctx, "Secret '%s/%s' is not a copy of QuarksSecret '%s', skipping removal", copy.Namespace, copy.Name, qsec.GetNamespacedName())
+		return nil
+	}
+
+	if policy == qsv1a1.DeletionPolicyOrphan {
+		delete(secret.Annotations, qsv1a1.AnnotationCopyOf)
+		if err := client.Update(ctx, secret); err != nil {
+			return errors.Wrapf(err, "could not orphan copy '%s/%s'", copy.Namespace, copy.Name)
+		}
+		ctxlog.WithEvent(qsec, "RemoveCopy").Infof(ctx, "Orphaned copy '%s/%s'", copy.Namespace, copy.Name)
+		return nil
+	}
+
+	if err := client.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "could not delete copy '%s/%s'", copy.Namespace, copy.Name)
+	}
+	ctxlog.WithEvent(qsec, "RemoveCopy").Infof(ctx, "Deleted copy '%s/%s'", copy.Namespace, copy.Name)
+
+	return nil
+}
+
+// specifiesCopy returns true if the copy is in the spec of the quarks secret
+func specifiesCopy(qsec *qsv1a1.QuarksSecret, copy qsv1a1.CopyStatus) bool {
+	for _, c := range qsec.Spec.Copies {
+		if c.Name == copy.Name && c.Namespace == copy.Namespace && c.Cluster == copy.Cluster {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package quarkssecret
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// validateFIPS checks in FIPS mode, that the quarks secret only asks for
+// FIPS approved parameters and sets the FIPS compliant condition. It returns
+// false, if the quarks secret must not be generated.
+func (r *ReconcileQuarksSecret) validateFIPS(ctx context.Context, qsec *qsv1a1.QuarksSecret) (bool, error) {
+	if !r.defaults.FIPS {
+		return true, nil
+	}
+
+	err := r.notApproved(ctx, qsec)
+	if err != nil && !credsgen.IsNotApproved(err) {
+		return false, err
+	}
+
+	if err != nil {
+		_ = ctxlog.WithEvent(qsec, "NotFIPSApproved").Errorf(ctx, "QuarksSecret '%s' is not generated in FIPS mode: %s", qsec.GetNamespacedName(), err)
+		condition := qsv1a1.QuarksSecretCondition{
+			Type:    qsv1a1.ConditionFIPSCompliant,
+			Status:  corev1.ConditionFalse,
+			Reason:  qsv1a1.FIPSReasonNotApproved,
+			Message: err.Error(),
+		}
+		err := patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+			status.SetCondition(condition)
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+		}
+		return false, nil
+	}
+
+	qsec.Status.SetCondition(qsv1a1.QuarksSecretCondition{
+		Type:    qsv1a1.ConditionFIPSCompliant,
+		Status:  corev1.ConditionTrue,
+		Reason:  qsv1a1.FIPSReasonApproved,
+		Message: "only FIPS approved algorithms are used",
+	})
+	return true, nil
+}
+
+// notApproved returns a NotApprovedError, if the defaults of the namespace or
+// the CA of a certificate are not FIPS approved
+func (r *ReconcileQuarksSecret) notApproved(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
+	if _, _, err := r.namespaceDefaults(ctx, qsec.Namespace); err != nil {
+		return err
+	}
+
+	request := qsec.Spec.Request.CertificateRequest
+	if !hasCertificate(qsec) || request.SignerType == qsv1a1.ClusterSigner || request.CARef.Name == "" {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: request.CARef.Name, Namespace: qsec.Namespace}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// checked once the CA exists
+			return nil
+		}
+		return errors.Wrap(err, "getting CA secret")
+	}
+	if err := credsgen.ValidateFIPSCertificate(secret.Data[request.CARef.Key]); err != nil {
+		return errors.Wrapf(err, "CA '%s'", request.CARef.Name)
+	}
+	return nil
+}
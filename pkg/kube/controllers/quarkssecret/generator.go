@@ -16,8 +16,15 @@ import (
 )
 
 func (r *ReconcileQuarksSecret) createPasswordSecret(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
-	request := credsgen.PasswordGenerationRequest{}
-	password := r.generator.GeneratePassword(qsec.GetName(), request)
+	generator, err := r.generatorFor(ctx, qsec)
+	if err != nil {
+		return err
+	}
+
+	password, err := generatePassword(generator, qsec.GetName())
+	if err != nil {
+		return err
+	}
 
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -31,11 +38,16 @@ func (r *ReconcileQuarksSecret) createPasswordSecret(ctx context.Context, qsec *
 		},
 	}
 
-	return r.createSecrets(ctx, qsec, secret)
+	return r.createSecret(ctx, qsec, secret)
 }
 
 func (r *ReconcileQuarksSecret) createRSASecret(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
-	key, err := r.generator.GenerateRSAKey(qsec.GetName())
+	generator, err := r.generatorFor(ctx, qsec)
+	if err != nil {
+		return err
+	}
+
+	key, err := generator.GenerateRSAKey(qsec.GetName())
 	if err != nil {
 		return err
 	}
@@ -53,11 +65,16 @@ func (r *ReconcileQuarksSecret) createRSASecret(ctx context.Context, qsec *qsv1a
 		},
 	}
 
-	return r.createSecrets(ctx, qsec, secret)
+	return r.createSecret(ctx, qsec, secret)
 }
 
 func (r *ReconcileQuarksSecret) createSSHSecret(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
-	key, err := r.generator.GenerateSSHKey(qsec.GetName())
+	generator, err := r.generatorFor(ctx, qsec)
+	if err != nil {
+		return err
+	}
+
+	key, err := generator.GenerateSSHKey(qsec.GetName())
 	if err != nil {
 		return err
 	}
@@ -76,15 +93,26 @@ func (r *ReconcileQuarksSecret) createSSHSecret(ctx context.Context, qsec *qsv1a
 		},
 	}
 
-	return r.createSecrets(ctx, qsec, secret)
+	return r.createSecret(ctx, qsec, secret)
 }
 
 func (r *ReconcileQuarksSecret) createBasicAuthSecret(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
+	generator, err := r.generatorFor(ctx, qsec)
+	if err != nil {
+		return err
+	}
+
 	username := qsec.Spec.Request.BasicAuthRequest.Username
 	if username == "" {
-		username = r.generator.GeneratePassword(fmt.Sprintf("%s/username", qsec.Name), credsgen.PasswordGenerationRequest{})
+		username, err = generatePassword(generator, fmt.Sprintf("%s/username", qsec.Name))
+		if err != nil {
+			return err
+		}
+	}
+	password, err := generatePassword(generator, fmt.Sprintf("%s/password", qsec.Name))
+	if err != nil {
+		return err
 	}
-	password := r.generator.GeneratePassword(fmt.Sprintf("%s/password", qsec.Name), credsgen.PasswordGenerationRequest{})
 
 	secret := &corev1.Secret{
 		Type: corev1.SecretTypeBasicAuth,
@@ -98,10 +126,15 @@ func (r *ReconcileQuarksSecret) createBasicAuthSecret(ctx context.Context, qsec
 		},
 	}
 
-	return r.createSecrets(ctx, qsec, secret)
+	return r.createSecret(ctx, qsec, secret)
 }
 
 func (r *ReconcileQuarksSecret) createDockerConfigJSON(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
+	generator, err := r.generatorFor(ctx, qsec)
+	if err != nil {
+		return err
+	}
+
 	// Fetch username and password.
 	username := ""
 	if len(qsec.Spec.Request.ImageCredentialsRequest.Username.Name) > 0 {
@@ -113,7 +146,7 @@ func (r *ReconcileQuarksSecret) createDockerConfigJSON(ctx context.Context, qsec
 		err := r.client.Get(ctx, userNamespacedName, userSecret)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				return newSecNotReadyError("username secret not found")
+				return newNotReadyError("username secret not found")
 			}
 			return errors.Wrap(err, "getting username secret")
 		}
@@ -124,7 +157,10 @@ func (r *ReconcileQuarksSecret) createDockerConfigJSON(ctx context.Context, qsec
 		username = string(data)
 	}
 	if username == "" {
-		username = r.generator.GeneratePassword(fmt.Sprintf("%s/username", qsec.Name), credsgen.PasswordGenerationRequest{})
+		username, err = generatePassword(generator, fmt.Sprintf("%s/username", qsec.Name))
+		if err != nil {
+			return err
+		}
 	}
 
 	password := ""
@@ -137,7 +173,7 @@ func (r *ReconcileQuarksSecret) createDockerConfigJSON(ctx context.Context, qsec
 		err := r.client.Get(ctx, passNamespacedName, passSecret)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				return newSecNotReadyError("password secret not found")
+				return newNotReadyError("password secret not found")
 			}
 			return errors.Wrap(err, "getting password secret")
 		}
@@ -148,7 +184,10 @@ func (r *ReconcileQuarksSecret) createDockerConfigJSON(ctx context.Context, qsec
 		password = string(data)
 	}
 	if password == "" {
-		password = r.generator.GeneratePassword(fmt.Sprintf("%s/password", qsec.Name), credsgen.PasswordGenerationRequest{})
+		password, err = generatePassword(generator, fmt.Sprintf("%s/password", qsec.Name))
+		if err != nil {
+			return err
+		}
 	}
 
 	authEncode := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password)))
@@ -171,5 +210,15 @@ func (r *ReconcileQuarksSecret) createDockerConfigJSON(ctx context.Context, qsec
 		},
 	}
 
-	return r.createSecrets(ctx, qsec, secret)
+	return r.createSecret(ctx, qsec, secret)
+}
+
+// generatePassword returns an error instead of an empty password, which
+// generators return if their backend fails
+func generatePassword(generator credsgen.Generator, name string) (string, error) {
+	password := generator.GeneratePassword(name, credsgen.PasswordGenerationRequest{})
+	if password == "" {
+		return "", errors.Errorf("generator returned an empty password for '%s'", name)
+	}
+	return password, nil
 }
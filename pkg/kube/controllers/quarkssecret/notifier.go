@@ -0,0 +1,374 @@
+package quarkssecret
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+const (
+	// notificationWorkers is the number of notifications delivered at the
+	// same time
+	notificationWorkers = 4
+	// notificationQueueSize is the number of notifications waiting for
+	// delivery, before new ones are dropped
+	notificationQueueSize = 1000
+	// defaultNotificationRetries is how often a failed delivery is retried
+	defaultNotificationRetries = 5
+	// defaultNotificationRetryInterval is the time before the first retry,
+	// it doubles with every retry. Retries wait in the queue, not in the
+	// workers.
+	defaultNotificationRetryInterval = 2 * time.Second
+
+	// SignatureHeader contains the HMAC-SHA256 signature of the body, if
+	// the endpoint has a signing key
+	SignatureHeader = "X-Quarks-Signature"
+	// EventHeader contains the notification event
+	EventHeader = "X-Quarks-Event"
+)
+
+// notificationReasons maps the reasons of events on quarks secrets to
+// notification events. Only errors, which are not resolved by requeueing the
+// quarks secret, are sent as failed.
+var notificationReasons = map[string]qsv1a1.NotificationEvent{
+	"Generated":             qsv1a1.NotificationGenerated,
+	"Rotated":               qsv1a1.NotificationRotated,
+	"Copied":                qsv1a1.NotificationCopied,
+	"ExpiringSoon":          qsv1a1.NotificationExpiringSoon,
+	"InvalidTypeError":      qsv1a1.NotificationFailed,
+	"InvalidExistingSecret": qsv1a1.NotificationFailed,
+	"NotFIPSApproved":       qsv1a1.NotificationFailed,
+	"RollbackError":         qsv1a1.NotificationFailed,
+}
+
+// Notification is the JSON body posted to notification endpoints
+type Notification struct {
+	Event        qsv1a1.NotificationEvent `json:"event"`
+	Reason       string                   `json:"reason"`
+	Message      string                   `json:"message"`
+	Namespace    string                   `json:"namespace"`
+	QuarksSecret string                   `json:"quarksSecret"`
+	Secret       string                   `json:"secret"`
+	Type         qsv1a1.SecretType        `json:"type"`
+	Time         time.Time                `json:"time"`
+}
+
+// notificationRequest is a notification waiting for delivery, the labels
+// of the quarks secret are matched against the selectors of the configs
+type notificationRequest struct {
+	notification Notification
+	labels       map[string]string
+}
+
+// delivery is a notification for a single endpoint. Failed deliveries are
+// queued again with a delay.
+type delivery struct {
+	config    *qsv1a1.QuarksSecretNotificationConfig
+	endpoint  qsv1a1.NotificationEndpoint
+	event     qsv1a1.NotificationEvent
+	qsecName  string
+	body      []byte
+	signature string
+	attempt   int
+}
+
+// Notifier sends notifications about quarks secrets to the endpoints of
+// the QuarksSecretNotificationConfigs in their namespace
+type Notifier struct {
+	ctx           context.Context
+	client        crc.Client
+	httpClient    *http.Client
+	retryInterval time.Duration
+	queue         workqueue.DelayingInterface
+}
+
+// NewNotifier returns a new Notifier, which delivers notifications once
+// it is started
+func NewNotifier(ctx context.Context, client crc.Client, httpClient *http.Client, retryInterval time.Duration) *Notifier {
+	return &Notifier{
+		ctx:           ctx,
+		client:        client,
+		httpClient:    httpClient,
+		retryInterval: retryInterval,
+		queue:         workqueue.NewNamedDelayingQueue("notifications"),
+	}
+}
+
+// AddNotifications adds a notifier to the manager and returns a manager,
+// whose event recorders also send the events of quarks secrets to the
+// notifier
+func AddNotifications(ctx context.Context, mgr manager.Manager) (manager.Manager, error) {
+	ctx = ctxlog.NewContextWithRecorder(ctx, "notifier", mgr.GetEventRecorderFor("quarks-secret-recorder"))
+	n := NewNotifier(ctx, mgr.GetClient(), &http.Client{Timeout: 10 * time.Second}, defaultNotificationRetryInterval)
+
+	if err := mgr.Add(n); err != nil {
+		return nil, errors.Wrap(err, "Adding notifier to manager failed.")
+	}
+
+	return &notifyingManager{Manager: mgr, notifier: n}, nil
+}
+
+// Notify queues a notification about the quarks secret, it doesn't block
+// if the queue is full
+func (n *Notifier) Notify(qsec *qsv1a1.QuarksSecret, event qsv1a1.NotificationEvent, reason string, message string) {
+	request := notificationRequest{
+		notification: Notification{
+			Event:        event,
+			Reason:       reason,
+			Message:      message,
+			Namespace:    qsec.Namespace,
+			QuarksSecret: qsec.Name,
+			Secret:       qsec.Spec.SecretName,
+			Type:         qsec.Spec.Type,
+			Time:         time.Now().UTC(),
+		},
+		labels: qsec.GetLabels(),
+	}
+
+	if n.queue.Len() >= notificationQueueSize {
+		ctxlog.Errorf(n.ctx, "Dropping %s notification for QuarksSecret '%s', the queue is full", event, qsec.GetNamespacedName())
+		return
+	}
+	n.queue.Add(&request)
+}
+
+// Start delivers the queued notifications until the stop channel is closed
+func (n *Notifier) Start(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(n.ctx)
+	defer cancel()
+
+	for i := 0; i < notificationWorkers; i++ {
+		go func() {
+			for {
+				item, shutdown := n.queue.Get()
+				if shutdown {
+					return
+				}
+				switch item := item.(type) {
+				case *notificationRequest:
+					n.send(ctx, item)
+				case *delivery:
+					n.deliver(ctx, item)
+				}
+				n.queue.Done(item)
+			}
+		}()
+	}
+
+	<-stop
+	n.queue.ShutDown()
+	return nil
+}
+
+// send posts the notification to the endpoints of all matching configs
+func (n *Notifier) send(ctx context.Context, request *notificationRequest) {
+	notification := request.notification
+
+	configs := &qsv1a1.QuarksSecretNotificationConfigList{}
+	err := n.client.List(ctx, configs, crc.InNamespace(notification.Namespace))
+	if err != nil {
+		ctxlog.Errorf(ctx, "Could not list notification configs in namespace '%s': %v", notification.Namespace, err)
+		return
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		ctxlog.Errorf(ctx, "Could not marshal %s notification for QuarksSecret '%s/%s': %v", notification.Event, notification.Namespace, notification.QuarksSecret, err)
+		return
+	}
+
+	for i := range configs.Items {
+		config := &configs.Items[i]
+		if !config.Sends(notification.Event) || !configSelects(ctx, config, request.labels) {
+			continue
+		}
+
+		for _, endpoint := range config.Spec.Endpoints {
+			d := &delivery{
+				config:   config,
+				endpoint: endpoint,
+				event:    notification.Event,
+				qsecName: fmt.Sprintf("%s/%s", notification.Namespace, notification.QuarksSecret),
+				body:     body,
+			}
+			if endpoint.SigningKeyRef != nil {
+				key, err := n.signingKey(ctx, config.Namespace, endpoint.SigningKeyRef)
+				if err != nil {
+					n.failed(ctx, d, err)
+					continue
+				}
+				d.signature = sign(key, body)
+			}
+			n.deliver(ctx, d)
+		}
+	}
+}
+
+// deliver posts the body to the endpoint. Failed deliveries are queued
+// again with an exponential backoff, so a slow or failing endpoint doesn't
+// hold up the workers.
+func (n *Notifier) deliver(ctx context.Context, d *delivery) {
+	retry, err := n.post(ctx, d.endpoint.URL, d.event, d.signature, d.body)
+	if err == nil {
+		ctxlog.Debugf(ctx, "Sent %s notification for QuarksSecret '%s' to '%s'", d.event, d.qsecName, d.endpoint.URL)
+		return
+	}
+
+	retries := defaultNotificationRetries
+	if d.endpoint.MaxRetries != nil {
+		retries = *d.endpoint.MaxRetries
+	}
+	if !retry || d.attempt >= retries {
+		n.failed(ctx, d, err)
+		return
+	}
+
+	interval := n.retryInterval << uint(d.attempt)
+	d.attempt++
+	ctxlog.Debugf(ctx, "Retrying %s notification to '%s' in %s: %v", d.event, d.endpoint.URL, interval, err)
+	n.queue.AddAfter(d, interval)
+}
+
+// failed reports a delivery, which is not retried
+func (n *Notifier) failed(ctx context.Context, d *delivery, err error) {
+	ctxlog.WarningEvent(ctx, d.config, "NotificationError", fmt.Sprintf("Could not send %s notification for QuarksSecret '%s' to '%s': %v",
+		d.event, d.qsecName, d.endpoint.URL, err))
+}
+
+// post sends a single request and returns if a failure may be retried
+func (n *Notifier) post(ctx context.Context, url string, event qsv1a1.NotificationEvent, signature string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "invalid request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(event))
+	if signature != "" {
+		req.Header.Set(SignatureHeader, signature)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, errors.Errorf("endpoint responded with status %d", resp.StatusCode)
+}
+
+// signingKey reads the signing key of an endpoint from a secret
+func (n *Notifier) signingKey(ctx context.Context, namespace string, ref *qsv1a1.SecretReference) ([]byte, error) {
+	secret := &corev1.Secret{}
+	err := n.client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read signing key secret '%s/%s'", namespace, ref.Name)
+	}
+	key, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, errors.Errorf("signing key secret '%s/%s' has no key '%s'", namespace, ref.Name, ref.Key)
+	}
+	return key, nil
+}
+
+// sign returns the HMAC-SHA256 signature of the body
+func sign(key []byte, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// configSelects returns true if the selector of the config matches the
+// labels of the quarks secret
+func configSelects(ctx context.Context, config *qsv1a1.QuarksSecretNotificationConfig, qsecLabels map[string]string) bool {
+	if config.Spec.Selector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(config.Spec.Selector)
+	if err != nil {
+		ctxlog.Errorf(ctx, "Invalid selector in notification config '%s': %v", config.GetNamespacedName(), err)
+		return false
+	}
+	return selector.Matches(labels.Set(qsecLabels))
+}
+
+// notifyingManager returns event recorders, which send the events of quarks
+// secrets to the notifier
+type notifyingManager struct {
+	manager.Manager
+	notifier *Notifier
+}
+
+// GetEventRecorderFor returns a notifying event recorder
+func (m *notifyingManager) GetEventRecorderFor(name string) record.EventRecorder {
+	return NewNotifyingRecorder(m.Manager.GetEventRecorderFor(name), m.notifier)
+}
+
+// NewNotifyingRecorder returns an event recorder, which records the events
+// and sends the lifecycle events of quarks secrets to the notifier
+func NewNotifyingRecorder(recorder record.EventRecorder, notifier *Notifier) record.EventRecorder {
+	return &notifyingRecorder{EventRecorder: recorder, notifier: notifier}
+}
+
+type notifyingRecorder struct {
+	record.EventRecorder
+	notifier *Notifier
+}
+
+// Event records the event and sends a notification for it
+func (r *notifyingRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.EventRecorder.Event(object, eventtype, reason, message)
+	r.notify(object, eventtype, reason, message)
+}
+
+// Eventf records the event and sends a notification for it
+func (r *notifyingRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	r.notify(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf records the event and sends a notification for it
+func (r *notifyingRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	r.notify(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *notifyingRecorder) notify(object runtime.Object, eventtype, reason, message string) {
+	qsec, ok := object.(*qsv1a1.QuarksSecret)
+	if !ok {
+		return
+	}
+
+	event, ok := notificationReasons[reason]
+	if !ok {
+		return
+	}
+	if event == qsv1a1.NotificationFailed && eventtype != corev1.EventTypeWarning {
+		return
+	}
+	r.notifier.Notify(qsec, event, reason, message)
+}
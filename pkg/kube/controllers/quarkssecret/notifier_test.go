@@ -0,0 +1,228 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("Notifier", func() {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		client   *cfakes.FakeClient
+		stop     chan struct{}
+		qSecret  *qsv1a1.QuarksSecret
+		config   *qsv1a1.QuarksSecretNotificationConfig
+		mutex    sync.Mutex
+		requests []received
+		statuses []int
+		interval time.Duration
+	)
+
+	receivedRequests := func() []received {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]received{}, requests...)
+	}
+
+	BeforeEach(func() {
+		requests = nil
+		statuses = nil
+		interval = 10 * time.Millisecond
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			mutex.Lock()
+			defer mutex.Unlock()
+			requests = append(requests, received{header: r.Header, body: body})
+			if len(statuses) > 0 {
+				w.WriteHeader(statuses[0])
+				statuses = statuses[1:]
+			}
+		}))
+
+		qSecret = &qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+				Labels:    map[string]string{"team": "a"},
+			},
+			Spec: qsv1a1.QuarksSecretSpec{
+				Type:       "password",
+				SecretName: "generated-secret",
+			},
+		}
+		config = &qsv1a1.QuarksSecretNotificationConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "cmdb", Namespace: "default"},
+			Spec: qsv1a1.QuarksSecretNotificationConfigSpec{
+				Endpoints: []qsv1a1.NotificationEndpoint{{
+					URL:           server.URL,
+					SigningKeyRef: &qsv1a1.SecretReference{Name: "cmdb-hook", Key: "key"},
+				}},
+			},
+		}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			if object, ok := object.(*corev1.Secret); ok && nn.Name == "cmdb-hook" {
+				object.Data = map[string][]byte{"key": []byte("s3cr3t")}
+				return nil
+			}
+			return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+		client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+			if object, ok := object.(*qsv1a1.QuarksSecretNotificationConfigList); ok {
+				object.Items = []qsv1a1.QuarksSecretNotificationConfig{*config}
+			}
+			return nil
+		})
+	})
+
+	JustBeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		notifier := qscontroller.NewNotifier(ctxlog.NewParentContext(log), client, server.Client(), interval)
+		ctx = ctxlog.NewContextWithRecorder(ctxlog.NewParentContext(log), "test", qscontroller.NewNotifyingRecorder(record.NewFakeRecorder(10), notifier))
+
+		stop = make(chan struct{})
+		go func(stop chan struct{}) {
+			defer GinkgoRecover()
+			Expect(notifier.Start(stop)).To(Succeed())
+		}(stop)
+	})
+
+	AfterEach(func() {
+		close(stop)
+		server.Close()
+	})
+
+	It("sends signed notifications for lifecycle events", func() {
+		ctxlog.WithEvent(qSecret, "Rotated").Infof(ctx, "Rotated secret '%s'", "generated-secret")
+
+		Eventually(receivedRequests).Should(HaveLen(1))
+		r := receivedRequests()[0]
+		Expect(r.header.Get(qscontroller.EventHeader)).To(Equal("rotated"))
+
+		mac := hmac.New(sha256.New, []byte("s3cr3t"))
+		mac.Write(r.body)
+		Expect(r.header.Get(qscontroller.SignatureHeader)).To(Equal("sha256=" + hex.EncodeToString(mac.Sum(nil))))
+
+		notification := qscontroller.Notification{}
+		Expect(json.Unmarshal(r.body, &notification)).To(Succeed())
+		Expect(notification.Event).To(Equal(qsv1a1.NotificationRotated))
+		Expect(notification.Reason).To(Equal("Rotated"))
+		Expect(notification.Message).To(Equal("Rotated secret 'generated-secret'"))
+		Expect(notification.Namespace).To(Equal("default"))
+		Expect(notification.QuarksSecret).To(Equal("foo"))
+		Expect(notification.Secret).To(Equal("generated-secret"))
+	})
+
+	It("sends errors, which are not resolved by retrying, as failed", func() {
+		_ = ctxlog.WithEvent(qSecret, "InvalidTypeError").Errorf(ctx, "Invalid type")
+
+		Eventually(receivedRequests).Should(HaveLen(1))
+		Expect(receivedRequests()[0].header.Get(qscontroller.EventHeader)).To(Equal("failed"))
+	})
+
+	It("doesn't send errors, which are retried", func() {
+		_ = ctxlog.WithEvent(qSecret, "CopyError").Errorf(ctx, "Error writing copy")
+		_ = ctxlog.WithEvent(qSecret, "DefaultsError").Errorf(ctx, "Error reading generator defaults")
+
+		Consistently(receivedRequests, "100ms").Should(BeEmpty())
+	})
+
+	It("doesn't send other events", func() {
+		ctxlog.WithEvent(qSecret, "CopyReconcile").Infof(ctx, "Skip copy creation")
+
+		Consistently(receivedRequests, "100ms").Should(BeEmpty())
+	})
+
+	It("retries failed deliveries", func() {
+		statuses = []int{http.StatusServiceUnavailable, http.StatusInternalServerError}
+		ctxlog.WithEvent(qSecret, "Generated").Infof(ctx, "Generated secret")
+
+		Eventually(receivedRequests).Should(HaveLen(3))
+		Consistently(receivedRequests, "100ms").Should(HaveLen(3))
+	})
+
+	Context("when deliveries wait for a retry", func() {
+		BeforeEach(func() {
+			interval = time.Hour
+		})
+
+		It("keeps delivering other notifications", func() {
+			statuses = []int{
+				http.StatusServiceUnavailable, http.StatusServiceUnavailable,
+				http.StatusServiceUnavailable, http.StatusServiceUnavailable,
+			}
+			for i := 0; i < 6; i++ {
+				ctxlog.WithEvent(qSecret, "Generated").Infof(ctx, "Generated secret")
+			}
+
+			Eventually(receivedRequests).Should(HaveLen(6))
+		})
+	})
+
+	It("doesn't retry rejected deliveries", func() {
+		statuses = []int{http.StatusBadRequest}
+		ctxlog.WithEvent(qSecret, "Generated").Infof(ctx, "Generated secret")
+
+		Eventually(receivedRequests).Should(HaveLen(1))
+		Consistently(receivedRequests, "100ms").Should(HaveLen(1))
+	})
+
+	It("stops retrying after the maximum retries", func() {
+		config.Spec.Endpoints[0].MaxRetries = pointers.Int(1)
+		statuses = []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}
+		ctxlog.WithEvent(qSecret, "Generated").Infof(ctx, "Generated secret")
+
+		Eventually(receivedRequests).Should(HaveLen(2))
+		Consistently(receivedRequests, "100ms").Should(HaveLen(2))
+	})
+
+	It("only sends the configured events", func() {
+		config.Spec.Events = []qsv1a1.NotificationEvent{qsv1a1.NotificationExpiringSoon}
+		ctxlog.WithEvent(qSecret, "Rotated").Infof(ctx, "Rotated secret")
+		ctxlog.WithEvent(qSecret, "ExpiringSoon").Infof(ctx, "Certificate expires")
+
+		Eventually(receivedRequests).Should(HaveLen(1))
+		Expect(receivedRequests()[0].header.Get(qscontroller.EventHeader)).To(Equal("expiring-soon"))
+	})
+
+	It("only sends notifications about the selected quarks secrets", func() {
+		config.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}}
+		ctxlog.WithEvent(qSecret, "Rotated").Infof(ctx, "Rotated secret")
+
+		Consistently(receivedRequests, "100ms").Should(BeEmpty())
+	})
+})
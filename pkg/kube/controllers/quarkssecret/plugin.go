@@ -0,0 +1,47 @@
+package quarkssecret
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-secret/pkg/plugin"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// createPluginSecret generates the secret of a custom type with its exec
+// plugin, passing the parameters and referenced secret values of the plugin
+// request
+func (r *ReconcileQuarksSecret) createPluginSecret(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
+	values, err := r.readValues(ctx, qsec.Namespace, qsec.Spec.Request.PluginRequest.Values)
+	if err != nil {
+		return err
+	}
+
+	response, err := r.plugins.Run(ctx, plugin.Request{
+		Type:       qsec.Spec.Type,
+		Name:       qsec.Name,
+		Namespace:  qsec.Namespace,
+		SecretName: qsec.Spec.SecretName,
+		Parameters: qsec.Spec.Request.PluginRequest.Parameters,
+		Values:     values,
+	})
+	if err != nil {
+		return ctxlog.WithEvent(qsec, "PluginError").Errorf(ctx, "Error generating '%s' secret for QuarksSecret '%s': %s", qsec.Spec.Type, qsec.GetNamespacedName(), err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        qsec.Spec.SecretName,
+			Namespace:   qsec.GetNamespace(),
+			Labels:      qsec.Spec.SecretLabels,
+			Annotations: qsec.Spec.SecretAnnotations,
+		},
+		Type:       response.SecretType,
+		StringData: response.Data,
+	}
+
+	return r.createSecret(ctx, qsec, secret)
+}
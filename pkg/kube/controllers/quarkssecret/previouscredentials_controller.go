@@ -0,0 +1,76 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// AddPreviousCredentials creates a new controller, which removes the
+// previous credentials from generated secrets after their grace period
+func AddPreviousCredentials(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
+	ctx = ctxlog.NewContextWithRecorder(ctx, "previous-credentials-reconciler", mgr.GetEventRecorderFor("quarks-secret-recorder"))
+	r := NewPreviousCredentialsReconciler(ctx, config, mgr)
+
+	// Create a new controller
+	c, err := controller.New(PreviousCredentialsController, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: options.workers(PreviousCredentialsController),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Adding previous credentials controller to manager failed.")
+	}
+
+	nsPred := newNSPredicate(ctx, mgr.GetClient(), config.MonitoredID)
+
+	// Watch for secrets with previous credentials
+	hasPrevious := func(o *corev1.Secret) bool {
+		_, found := o.GetAnnotations()[qsv1a1.AnnotationPreviousExpires]
+		return found
+	}
+	p := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			if hasPrevious(e.Object.(*corev1.Secret)) {
+				ctxlog.NewPredicateEvent(e.Object).Debug(
+					ctx, e.Meta, "corev1.Secret",
+					fmt.Sprintf("Create predicate passed for '%s/%s'", e.Meta.GetNamespace(), e.Meta.GetName()),
+				)
+				return true
+			}
+			return false
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*corev1.Secret)
+			o := e.ObjectOld.(*corev1.Secret)
+			if hasPrevious(n) && n.GetAnnotations()[qsv1a1.AnnotationPreviousExpires] != o.GetAnnotations()[qsv1a1.AnnotationPreviousExpires] {
+				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+					ctx, e.MetaNew, "corev1.Secret",
+					fmt.Sprintf("Update predicate passed for '%s/%s'", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+				)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{}, nsPred, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching secrets failed in previous credentials controller.")
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package quarkssecret
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// DefaultPreviousGracePeriod is how long previous credentials are kept, if
+// rotation.gracePeriod is not set
+const DefaultPreviousGracePeriod = time.Hour
+
+// previousKeys maps the keys of generated secrets to the keys, which hold
+// their previous values if rotation.keepPrevious is set
+var previousKeys = map[string]string{
+	"password":    "previous_password",
+	"private_key": "previous_private_key",
+	"certificate": "previous_certificate",
+	"tls.crt":     "previous_tls.crt",
+	"tls.key":     "previous_tls.key",
+}
+
+// NewPreviousCredentialsReconciler returns a new ReconcilePreviousCredentials
+func NewPreviousCredentialsReconciler(ctx context.Context, config *config.Config, mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcilePreviousCredentials{
+		ctx:    ctx,
+		config: config,
+		client: mgr.GetClient(),
+		scheme: mgr.GetScheme(),
+	}
+}
+
+// ReconcilePreviousCredentials removes expired previous credentials from
+// generated secrets
+type ReconcilePreviousCredentials struct {
+	ctx    context.Context
+	client client.Client
+	scheme *runtime.Scheme
+	config *config.Config
+}
+
+// Reconcile removes the previous credentials from the secret once they
+// expired, or requeues until then
+func (r *ReconcilePreviousCredentials) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	secret := &corev1.Secret{}
+
+	// Set the ctx to be Background, as the top-level context for incoming requests.
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.CtxTimeOut)
+	defer cancel()
+
+	ctxlog.Infof(ctx, "Reconciling previous credentials of secret '%s'", request.NamespacedName)
+	err := r.client.Get(ctx, request.NamespacedName, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Info(ctx, "Skip reconcile: secret not found")
+			return reconcile.Result{}, nil
+		}
+		ctxlog.Info(ctx, "Error reading the object")
+		return reconcile.Result{}, errors.Wrap(err, "Error reading secret")
+	}
+
+	value, ok := secret.Annotations[qsv1a1.AnnotationPreviousExpires]
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+
+	expires, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		ctxlog.Errorf(ctx, "Invalid annotation '%s' on secret '%s', removing previous credentials: %s", qsv1a1.AnnotationPreviousExpires, request.NamespacedName, err)
+	} else if remaining := time.Until(expires); remaining > 0 {
+		ctxlog.Debugf(ctx, "Previous credentials of secret '%s' expire in %s", request.NamespacedName, remaining)
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	for _, key := range previousKeys {
+		delete(secret.Data, key)
+	}
+	delete(secret.Annotations, qsv1a1.AnnotationPreviousExpires)
+
+	err = r.client.Update(ctx, secret)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "could not remove previous credentials from secret '%s'", request.NamespacedName)
+	}
+	ctxlog.Infof(ctx, "Removed expired previous credentials from secret '%s'", request.NamespacedName)
+
+	return reconcile.Result{}, nil
+}
+
+// keepPreviousMutateFn wraps the mutate func of a generated secret. If keep
+// is set, it stores the values of regenerated credentials under their
+// previous keys, until the grace period ends. The expiry of previously kept
+// values is preserved, so they are still removed.
+func keepPreviousMutateFn(s *corev1.Secret, rotation qsv1a1.RotationSpec, fn controllerutil.MutateFn) controllerutil.MutateFn {
+	desired := s.DeepCopy()
+	return func() error {
+		existing := s.Data
+		expires, expiring := s.Annotations[qsv1a1.AnnotationPreviousExpires]
+
+		if err := fn(); err != nil {
+			return err
+		}
+
+		kept := false
+		if rotation.KeepPrevious {
+			for key, previousKey := range previousKeys {
+				old, ok := existing[key]
+				if !ok {
+					continue
+				}
+				if value, ok := desired.StringData[key]; ok && value != string(old) {
+					if s.StringData == nil {
+						s.StringData = map[string]string{}
+					}
+					s.StringData[previousKey] = string(old)
+					kept = true
+				}
+			}
+		}
+
+		// the mutate func prunes keys, which are not generated, but
+		// previous values are kept until they expire
+		if expiring {
+			for _, previousKey := range previousKeys {
+				old, ok := existing[previousKey]
+				if _, replaced := s.StringData[previousKey]; !ok || replaced {
+					continue
+				}
+				if s.Data == nil {
+					s.Data = map[string][]byte{}
+				}
+				s.Data[previousKey] = old
+			}
+		}
+
+		if kept {
+			gracePeriod := DefaultPreviousGracePeriod
+			if rotation.GracePeriod != nil {
+				gracePeriod = rotation.GracePeriod.Duration
+			}
+			expires = time.Now().Add(gracePeriod).UTC().Format(time.RFC3339)
+			expiring = true
+		}
+		if expiring {
+			if s.Annotations == nil {
+				s.Annotations = map[string]string{}
+			}
+			s.Annotations[qsv1a1.AnnotationPreviousExpires] = expires
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ReconcilePreviousCredentials", func() {
+	var (
+		manager    *cfakes.FakeManager
+		reconciler reconcile.Reconciler
+		request    reconcile.Request
+		client     *cfakes.FakeClient
+		secret     *corev1.Secret
+	)
+
+	BeforeEach(func() {
+		manager = &cfakes.FakeManager{}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "generated-secret", Namespace: "default"}}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "generated-secret",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"foo": "bar",
+				},
+			},
+			Data: map[string][]byte{
+				"password":          []byte("new-password"),
+				"previous_password": []byte("old-password"),
+			},
+		}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			secret.DeepCopyInto(object.(*corev1.Secret))
+			return nil
+		})
+		manager.GetClientReturns(client)
+	})
+
+	JustBeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		ctx := ctxlog.NewParentContext(log)
+		reconciler = qscontroller.NewPreviousCredentialsReconciler(ctx, &cfcfg.Config{CtxTimeOut: 10 * time.Second}, manager)
+	})
+
+	When("the previous credentials expired", func() {
+		BeforeEach(func() {
+			secret.Annotations[qsv1a1.AnnotationPreviousExpires] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+		})
+
+		It("removes them from the secret", func() {
+			result, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+
+			Expect(client.UpdateCallCount()).To(Equal(1))
+			_, object, _ := client.UpdateArgsForCall(0)
+			updated := object.(*corev1.Secret)
+			Expect(updated.Data).To(Equal(map[string][]byte{"password": []byte("new-password")}))
+			Expect(updated.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+		})
+	})
+
+	When("the previous credentials did not expire yet", func() {
+		BeforeEach(func() {
+			secret.Annotations[qsv1a1.AnnotationPreviousExpires] = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		})
+
+		It("requeues until they expire", func() {
+			result, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+			Expect(client.UpdateCallCount()).To(Equal(0))
+		})
+	})
+})
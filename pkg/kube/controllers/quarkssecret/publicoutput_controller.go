@@ -0,0 +1,95 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// AddPublicOutput creates a new controller, which writes the public parts of
+// generated secrets to config maps
+func AddPublicOutput(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
+	ctx = ctxlog.NewContextWithRecorder(ctx, "public-output-reconciler", mgr.GetEventRecorderFor("quarks-secret-recorder"))
+	r := NewPublicOutputReconciler(ctx, config, mgr)
+
+	// Create a new controller
+	c, err := controller.New(PublicOutputController, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: options.workers(PublicOutputController),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Adding public output controller to manager failed.")
+	}
+
+	nsPred := newNSPredicate(ctx, mgr.GetClient(), config.MonitoredID)
+
+	// Watch for changes to the public output of QuarksSecrets
+	p := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.(*qsv1a1.QuarksSecret).Spec.PublicOutput != nil
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*qsv1a1.QuarksSecret)
+			o := e.ObjectOld.(*qsv1a1.QuarksSecret)
+
+			if !reflect.DeepEqual(n.Spec.PublicOutput, o.Spec.PublicOutput) || n.Spec.SecretName != o.Spec.SecretName {
+				ctxlog.Debugf(ctx, "Public output of QuarksSecret '%s' changed", n.Name)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, options.quarksSecretPredicates(nsPred, p)...)
+	if err != nil {
+		return errors.Wrapf(err, "Watching quarks secrets failed in public output controller.")
+	}
+
+	// Watch for generated secrets, e.g. after a rotation
+	generated := func(o *corev1.Secret) bool {
+		return o.GetLabels()[qsv1a1.LabelKind] == qsv1a1.GeneratedSecretKind
+	}
+	p = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return generated(e.Object.(*corev1.Secret))
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*corev1.Secret)
+			o := e.ObjectOld.(*corev1.Secret)
+			if generated(n) && !reflect.DeepEqual(publicData(n), publicData(o)) {
+				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+					ctx, e.MetaNew, "corev1.Secret",
+					fmt.Sprintf("Update predicate passed for '%s/%s': public data changed", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+				)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &qsv1a1.QuarksSecret{},
+		IsController: true,
+	}, nsPred, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching secrets failed in public output controller.")
+	}
+
+	return nil
+}
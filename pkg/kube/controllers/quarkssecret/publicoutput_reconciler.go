@@ -0,0 +1,269 @@
+package quarkssecret
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// publicKeys lists the keys of a generated secret, which are not secret and
+// are written to the public output
+var publicKeys = []string{"ca", "certificate", "tls.crt", "public_key", "public_key_fingerprint"}
+
+// NewPublicOutputReconciler returns a new ReconcilePublicOutput
+func NewPublicOutputReconciler(ctx context.Context, config *config.Config, mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcilePublicOutput{
+		ctx:    ctx,
+		config: config,
+		client: mgr.GetClient(),
+	}
+}
+
+// ReconcilePublicOutput writes the public parts of generated secrets to
+// config maps
+type ReconcilePublicOutput struct {
+	ctx    context.Context
+	client client.Client
+	config *config.Config
+}
+
+// Reconcile writes the public keys of the generated secret to the config
+// maps of the public output and removes config maps, which are no longer
+// part of it
+func (r *ReconcilePublicOutput) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	qsec := &qsv1a1.QuarksSecret{}
+
+	// Set the ctx to be Background, as the top-level context for incoming requests.
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.CtxTimeOut)
+	defer cancel()
+
+	ctxlog.Infof(ctx, "Reconciling public output of QuarksSecret %s", request.NamespacedName)
+	err := r.client.Get(ctx, request.NamespacedName, qsec)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Info(ctx, "Skip reconcile: quarks secret not found")
+			return reconcile.Result{}, nil
+		}
+		ctxlog.Info(ctx, "Error reading the object")
+		return reconcile.Result{}, errors.Wrap(err, "Error reading quarksSecret")
+	}
+
+	if qsec.GetDeletionTimestamp() != nil || qsec.Spec.Type == qsv1a1.SecretCopy {
+		return reconcile.Result{}, nil
+	}
+
+	data := map[string]string{}
+	if qsec.Spec.PublicOutput != nil {
+		secret := &corev1.Secret{}
+		err = r.client.Get(ctx, types.NamespacedName{Name: qsec.Spec.SecretName, Namespace: qsec.Namespace}, secret)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				ctxlog.Debugf(ctx, "Skip reconcile: secret '%s/%s' not found", qsec.Namespace, qsec.Spec.SecretName)
+				return reconcile.Result{}, nil
+			}
+			return reconcile.Result{}, errors.Wrapf(err, "could not get secret '%s/%s'", qsec.Namespace, qsec.Spec.SecretName)
+		}
+		data = publicData(secret)
+	}
+
+	outputs := []qsv1a1.PublicOutputStatus{}
+	if len(data) > 0 {
+		outputs = publicOutputsOf(qsec)
+	}
+
+	written := []qsv1a1.PublicOutputStatus{}
+	for _, output := range outputs {
+		allowed, err := r.publicOutputAllowed(ctx, qsec, output)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !allowed {
+			ctxlog.WithEvent(qsec, "PublicOutputError").Infof(ctx, "Skipping public output '%s/%s', namespace has no placeholder and no copy policy allows it", output.Namespace, output.Name)
+			continue
+		}
+
+		ok, err := r.writePublicOutput(ctx, qsec, output, data)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if ok {
+			written = append(written, output)
+		}
+	}
+
+	for _, output := range qsec.Status.PublicOutputs {
+		if !containsPublicOutput(written, output) {
+			if err := removePublicOutput(ctx, r.client, qsec, output); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
+	if len(written) == 0 {
+		written = nil
+	}
+	if reflect.DeepEqual(written, qsec.Status.PublicOutputs) {
+		return reconcile.Result{}, nil
+	}
+	err = patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+		status.PublicOutputs = written
+	})
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// publicOutputAllowed returns true if the quarks secret may write the public
+// output. Like copies, writing into another namespace needs a placeholder
+// quarks secret of type copy in the target namespace or a
+// QuarksSecretCopyPolicy.
+func (r *ReconcilePublicOutput) publicOutputAllowed(ctx context.Context, qsec *qsv1a1.QuarksSecret, output qsv1a1.PublicOutputStatus) (bool, error) {
+	if output.Namespace == qsec.Namespace {
+		return true, nil
+	}
+
+	placeholder := &qsv1a1.QuarksSecret{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: qsec.Name, Namespace: output.Namespace}, placeholder)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "could not get placeholder quarks secret '%s/%s'", output.Namespace, qsec.Name)
+	}
+	if err == nil && placeholder.Spec.Type == qsv1a1.SecretCopy &&
+		placeholder.GetAnnotations()[qsv1a1.AnnotationCopyOf] == qsec.GetNamespacedName() {
+		return true, nil
+	}
+
+	return namespaceAllowedByPolicy(ctx, r.client, qsec, output.Namespace)
+}
+
+// writePublicOutput creates or updates the config map of the public output.
+// It returns false if a config map exists, which is not the public output
+// of the quarks secret.
+func (r *ReconcilePublicOutput) writePublicOutput(ctx context.Context, qsec *qsv1a1.QuarksSecret, output qsv1a1.PublicOutputStatus, data map[string]string) (bool, error) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      output.Name,
+			Namespace: output.Namespace,
+		},
+	}
+
+	err := r.client.Get(ctx, types.NamespacedName{Name: output.Name, Namespace: output.Namespace}, configMap)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "could not get config map '%s/%s'", output.Namespace, output.Name)
+	}
+	if err == nil && !isPublicOutputOf(qsec, configMap) {
+		ctxlog.WithEvent(qsec, "PublicOutputError").Infof(ctx, "Skipping public output, config map '%s/%s' exists and is not a public output of QuarksSecret '%s'", output.Namespace, output.Name, qsec.GetNamespacedName())
+		return false, nil
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.client, configMap, func() error {
+		if configMap.Labels == nil {
+			configMap.Labels = map[string]string{}
+		}
+		configMap.Labels[qsv1a1.LabelKind] = qsv1a1.PublicOutputKind
+		if configMap.Annotations == nil {
+			configMap.Annotations = map[string]string{}
+		}
+		configMap.Annotations[qsv1a1.AnnotationPublicOutputOf] = qsec.GetNamespacedName()
+		configMap.Data = data
+		return nil
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "could not create or update config map '%s/%s'", output.Namespace, output.Name)
+	}
+
+	if op != controllerutil.OperationResultNone {
+		ctxlog.WithEvent(qsec, "PublicOutput").Infof(ctx, "Public output '%s/%s' has been %s", output.Namespace, output.Name, op)
+	}
+	return true, nil
+}
+
+// removePublicOutput deletes the config map of a public output. Config
+// maps, which are not a public output of the quarks secret, are left alone.
+func removePublicOutput(ctx context.Context, client client.Client, qsec *qsv1a1.QuarksSecret, output qsv1a1.PublicOutputStatus) error {
+	configMap := &corev1.ConfigMap{}
+	err := client.Get(ctx, types.NamespacedName{Name: output.Name, Namespace: output.Namespace}, configMap)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "could not get config map '%s/%s'", output.Namespace, output.Name)
+	}
+
+	if !isPublicOutputOf(qsec, configMap) {
+		ctxlog.Debugf(ctx, "Config map '%s/%s' is not a public output of QuarksSecret '%s', skipping removal", output.Namespace, output.Name, qsec.GetNamespacedName())
+		return nil
+	}
+
+	if err := client.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "could not delete config map '%s/%s'", output.Namespace, output.Name)
+	}
+	ctxlog.WithEvent(qsec, "PublicOutput").Infof(ctx, "Deleted public output '%s/%s'", output.Namespace, output.Name)
+
+	return nil
+}
+
+// isPublicOutputOf returns true if the config map was written as public
+// output of the quarks secret
+func isPublicOutputOf(qsec *qsv1a1.QuarksSecret, configMap *corev1.ConfigMap) bool {
+	return configMap.Labels[qsv1a1.LabelKind] == qsv1a1.PublicOutputKind &&
+		configMap.Annotations[qsv1a1.AnnotationPublicOutputOf] == qsec.GetNamespacedName()
+}
+
+// publicOutputsOf returns the config maps of the public output in the spec
+func publicOutputsOf(qsec *qsv1a1.QuarksSecret) []qsv1a1.PublicOutputStatus {
+	output := qsec.Spec.PublicOutput
+	if output == nil {
+		return []qsv1a1.PublicOutputStatus{}
+	}
+
+	name := output.Name
+	if name == "" {
+		name = qsec.Spec.SecretName
+	}
+	namespaces := output.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{qsec.Namespace}
+	}
+
+	outputs := []qsv1a1.PublicOutputStatus{}
+	for _, namespace := range namespaces {
+		outputs = append(outputs, qsv1a1.PublicOutputStatus{Name: name, Namespace: namespace})
+	}
+	return outputs
+}
+
+// publicData returns the public keys of the secret
+func publicData(secret *corev1.Secret) map[string]string {
+	data := map[string]string{}
+	for _, key := range publicKeys {
+		if value, ok := secret.Data[key]; ok {
+			data[key] = string(value)
+		}
+	}
+	return data
+}
+
+func containsPublicOutput(outputs []qsv1a1.PublicOutputStatus, output qsv1a1.PublicOutputStatus) bool {
+	for _, o := range outputs {
+		if o == output {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ReconcilePublicOutput", func() {
+	var (
+		manager      *cfakes.FakeManager
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		client       *cfakes.FakeClient
+		statusWriter *cfakes.FakeStatusWriter
+		qSecret      *qsv1a1.QuarksSecret
+		secret       *corev1.Secret
+		configMaps   map[types.NamespacedName]*corev1.ConfigMap
+		placeholder  *qsv1a1.QuarksSecret
+		namespace    *corev1.Namespace
+		policies     []qsv1a1.QuarksSecretCopyPolicy
+	)
+
+	createdConfigMaps := func() []*corev1.ConfigMap {
+		result := []*corev1.ConfigMap{}
+		for i := 0; i < client.CreateCallCount(); i++ {
+			_, object, _ := client.CreateArgsForCall(i)
+			result = append(result, object.(*corev1.ConfigMap))
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		manager = &cfakes.FakeManager{}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+		qSecret = &qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: qsv1a1.QuarksSecretSpec{
+				Type:         "certificate",
+				SecretName:   "generated-secret",
+				PublicOutput: &qsv1a1.PublicOutput{Namespaces: []string{"default", "team-a"}},
+			},
+			Status: qsv1a1.QuarksSecretStatus{
+				Generated: pointers.Bool(true),
+			},
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "generated-secret",
+				Namespace: "default",
+				Labels:    map[string]string{qsv1a1.LabelKind: qsv1a1.GeneratedSecretKind},
+			},
+			Data: map[string][]byte{
+				"certificate": []byte("the-cert"),
+				"ca":          []byte("the-ca"),
+				"private_key": []byte("the-key"),
+			},
+		}
+		configMaps = map[types.NamespacedName]*corev1.ConfigMap{}
+		placeholder = nil
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}},
+		}
+		policies = []qsv1a1.QuarksSecretCopyPolicy{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "default-to-teams"},
+				Spec: qsv1a1.QuarksSecretCopyPolicySpec{
+					SourceNamespaces:        []string{"default"},
+					TargetNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				},
+			},
+		}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			switch object := object.(type) {
+			case *qsv1a1.QuarksSecret:
+				if nn.Namespace == qSecret.Namespace {
+					qSecret.DeepCopyInto(object)
+					return nil
+				}
+				if placeholder != nil && nn.Namespace == placeholder.Namespace {
+					placeholder.DeepCopyInto(object)
+					return nil
+				}
+			case *corev1.Namespace:
+				if nn.Name == namespace.Name {
+					namespace.DeepCopyInto(object)
+					return nil
+				}
+			case *corev1.Secret:
+				if nn.Name == secret.Name {
+					secret.DeepCopyInto(object)
+					return nil
+				}
+			case *corev1.ConfigMap:
+				if configMap, ok := configMaps[nn]; ok {
+					configMap.DeepCopyInto(object)
+					return nil
+				}
+			}
+			return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+		client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+			if list, ok := object.(*qsv1a1.QuarksSecretCopyPolicyList); ok {
+				list.Items = policies
+			}
+			return nil
+		})
+		statusWriter = &cfakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		manager.GetClientReturns(client)
+	})
+
+	JustBeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		ctx := ctxlog.NewParentContext(log)
+		reconciler = qscontroller.NewPublicOutputReconciler(ctx, &cfcfg.Config{CtxTimeOut: 10 * time.Second}, manager)
+	})
+
+	It("writes the public keys to a config map in every namespace", func() {
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		created := createdConfigMaps()
+		Expect(created).To(HaveLen(2))
+		for i, namespace := range []string{"default", "team-a"} {
+			Expect(created[i].Name).To(Equal("generated-secret"))
+			Expect(created[i].Namespace).To(Equal(namespace))
+			Expect(created[i].Data).To(Equal(map[string]string{"certificate": "the-cert", "ca": "the-ca"}))
+			Expect(created[i].Labels[qsv1a1.LabelKind]).To(Equal(qsv1a1.PublicOutputKind))
+			Expect(created[i].Annotations[qsv1a1.AnnotationPublicOutputOf]).To(Equal("default/foo"))
+		}
+
+		Expect(statusWriter.PatchCallCount()).To(Equal(1))
+		_, object, _, _ := statusWriter.PatchArgsForCall(0)
+		Expect(object.(*qsv1a1.QuarksSecret).Status.PublicOutputs).To(Equal([]qsv1a1.PublicOutputStatus{
+			{Name: "generated-secret", Namespace: "default"},
+			{Name: "generated-secret", Namespace: "team-a"},
+		}))
+	})
+
+	It("updates the config maps after a rotation", func() {
+		qSecret.Status.PublicOutputs = []qsv1a1.PublicOutputStatus{{Name: "generated-secret", Namespace: "default"}}
+		qSecret.Spec.PublicOutput.Namespaces = []string{"default"}
+		configMaps[types.NamespacedName{Name: "generated-secret", Namespace: "default"}] = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "generated-secret",
+				Namespace:   "default",
+				Labels:      map[string]string{qsv1a1.LabelKind: qsv1a1.PublicOutputKind},
+				Annotations: map[string]string{qsv1a1.AnnotationPublicOutputOf: "default/foo"},
+			},
+			Data: map[string]string{"certificate": "old-cert", "ca": "the-ca"},
+		}
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.UpdateCallCount()).To(Equal(1))
+		_, object, _ := client.UpdateArgsForCall(0)
+		Expect(object.(*corev1.ConfigMap).Data["certificate"]).To(Equal("the-cert"))
+		Expect(statusWriter.PatchCallCount()).To(Equal(0))
+	})
+
+	It("doesn't overwrite config maps, which are not a public output of the quarks secret", func() {
+		configMaps[types.NamespacedName{Name: "generated-secret", Namespace: "team-a"}] = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "generated-secret", Namespace: "team-a"},
+		}
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(createdConfigMaps()).To(HaveLen(1))
+		Expect(client.UpdateCallCount()).To(Equal(0))
+		_, object, _, _ := statusWriter.PatchArgsForCall(0)
+		Expect(object.(*qsv1a1.QuarksSecret).Status.PublicOutputs).To(Equal([]qsv1a1.PublicOutputStatus{
+			{Name: "generated-secret", Namespace: "default"},
+		}))
+	})
+
+	It("doesn't overwrite config maps, which only carry the public output annotation", func() {
+		configMaps[types.NamespacedName{Name: "generated-secret", Namespace: "team-a"}] = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "generated-secret",
+				Namespace:   "team-a",
+				Annotations: map[string]string{qsv1a1.AnnotationPublicOutputOf: "default/foo"},
+			},
+		}
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(createdConfigMaps()).To(HaveLen(1))
+		Expect(client.UpdateCallCount()).To(Equal(0))
+	})
+
+	Context("when writing into another namespace", func() {
+		BeforeEach(func() {
+			policies = nil
+		})
+
+		It("skips the namespace without a placeholder or copy policy", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			created := createdConfigMaps()
+			Expect(created).To(HaveLen(1))
+			Expect(created[0].Namespace).To(Equal("default"))
+			Expect(client.UpdateCallCount()).To(Equal(0))
+			_, object, _, _ := statusWriter.PatchArgsForCall(0)
+			Expect(object.(*qsv1a1.QuarksSecret).Status.PublicOutputs).To(Equal([]qsv1a1.PublicOutputStatus{
+				{Name: "generated-secret", Namespace: "default"},
+			}))
+		})
+
+		It("skips the namespace if the copy policy doesn't select it", func() {
+			policies = []qsv1a1.QuarksSecretCopyPolicy{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "other-teams"},
+					Spec: qsv1a1.QuarksSecretCopyPolicySpec{
+						SourceNamespaces:        []string{"default"},
+						TargetNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}},
+					},
+				},
+			}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(createdConfigMaps()).To(HaveLen(1))
+		})
+
+		It("skips the namespace if the placeholder is a copy of another quarks secret", func() {
+			placeholder = &qsv1a1.QuarksSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   "team-a",
+					Annotations: map[string]string{qsv1a1.AnnotationCopyOf: "other/foo"},
+				},
+				Spec: qsv1a1.QuarksSecretSpec{Type: qsv1a1.SecretCopy},
+			}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(createdConfigMaps()).To(HaveLen(1))
+		})
+
+		It("writes the public output if there is a placeholder", func() {
+			placeholder = &qsv1a1.QuarksSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   "team-a",
+					Annotations: map[string]string{qsv1a1.AnnotationCopyOf: "default/foo"},
+				},
+				Spec: qsv1a1.QuarksSecretSpec{Type: qsv1a1.SecretCopy},
+			}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			created := createdConfigMaps()
+			Expect(created).To(HaveLen(2))
+			Expect(created[1].Namespace).To(Equal("team-a"))
+		})
+	})
+
+	It("removes config maps, which are no longer part of the public output", func() {
+		qSecret.Spec.PublicOutput = nil
+		qSecret.Status.PublicOutputs = []qsv1a1.PublicOutputStatus{{Name: "generated-secret", Namespace: "team-a"}}
+		configMaps[types.NamespacedName{Name: "generated-secret", Namespace: "team-a"}] = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "generated-secret",
+				Namespace:   "team-a",
+				Labels:      map[string]string{qsv1a1.LabelKind: qsv1a1.PublicOutputKind},
+				Annotations: map[string]string{qsv1a1.AnnotationPublicOutputOf: "default/foo"},
+			},
+		}
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.DeleteCallCount()).To(Equal(1))
+		_, object, _ := client.DeleteArgsForCall(0)
+		Expect(object.(*corev1.ConfigMap).Namespace).To(Equal("team-a"))
+		_, object, _, _ = statusWriter.PatchArgsForCall(0)
+		Expect(object.(*qsv1a1.QuarksSecret).Status.PublicOutputs).To(BeNil())
+	})
+
+	It("writes nothing for secrets without public keys", func() {
+		secret.Data = map[string][]byte{"password": []byte("secret")}
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.CreateCallCount()).To(Equal(0))
+		Expect(statusWriter.PatchCallCount()).To(Equal(0))
+	})
+})
@@ -17,23 +17,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	credsgen "code.cloudfoundry.org/quarks-secret/pkg/credsgen/in_memory_generator"
 	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
-	"code.cloudfoundry.org/quarks-utils/pkg/config"
 	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
 )
 
 // AddQuarksSecret creates a new QuarksSecrets controller to watch for the
 // custom resource and reconcile it into k8s secrets.
-func AddQuarksSecret(ctx context.Context, config *config.Config, mgr manager.Manager) error {
+func AddQuarksSecret(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
 	ctx = ctxlog.NewContextWithRecorder(ctx, "quarks-secret-reconciler", mgr.GetEventRecorderFor("quarks-secret-recorder"))
-	log := ctxlog.ExtractLogger(ctx)
-	r := NewQuarksSecretReconciler(ctx, config, mgr, credsgen.NewInMemoryGenerator(log), controllerutil.SetControllerReference)
+	r := NewQuarksSecretReconciler(ctx, options, mgr, controllerutil.SetControllerReference, NewClusterClientFunc(mgr.GetScheme()))
 
 	// Create a new controller
-	c, err := controller.New("quarks-secret-controller", mgr, controller.Options{
+	c, err := controller.New(QuarksSecretController, mgr, controller.Options{
 		Reconciler:              r,
-		MaxConcurrentReconciles: config.MaxQuarksSecretWorkers,
+		MaxConcurrentReconciles: options.workers(QuarksSecretController),
 	})
 	if err != nil {
 		return errors.Wrap(err, "Adding quarks secret controller to manager failed.")
@@ -56,6 +54,33 @@ func AddQuarksSecret(ctx context.Context, config *config.Config, mgr manager.Man
 				)
 				return true
 			}
+
+			// reconcile if a rotation was requested while the operator was not running
+			if o.Status.IsGenerated() && o.RotationRequested() {
+				ctxlog.NewPredicateEvent(e.Object).Debug(
+					ctx, e.Meta, "qsv1a1.QuarksSecret",
+					fmt.Sprintf("Create predicate passed for '%s/%s': rotation requested", e.Meta.GetNamespace(), e.Meta.GetName()),
+				)
+				return true
+			}
+
+			// finalize if it was deleted while the operator was not running
+			if o.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(o, qsv1a1.Finalizer) {
+				ctxlog.NewPredicateEvent(e.Object).Debug(
+					ctx, e.Meta, "qsv1a1.QuarksSecret",
+					fmt.Sprintf("Create predicate passed for '%s/%s': deleted", e.Meta.GetNamespace(), e.Meta.GetName()),
+				)
+				return true
+			}
+
+			// reconcile if a rollback was requested while the operator was not running
+			if o.RollbackRequested() {
+				ctxlog.NewPredicateEvent(e.Object).Debug(
+					ctx, e.Meta, "qsv1a1.QuarksSecret",
+					fmt.Sprintf("Create predicate passed for '%s/%s': rollback requested", e.Meta.GetNamespace(), e.Meta.GetName()),
+				)
+				return true
+			}
 			return false
 		},
 		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
@@ -64,9 +89,21 @@ func AddQuarksSecret(ctx context.Context, config *config.Config, mgr manager.Man
 			n := e.ObjectNew.(*qsv1a1.QuarksSecret)
 			o := e.ObjectOld.(*qsv1a1.QuarksSecret)
 
+			// finalize when it is deleted
+			if n.GetDeletionTimestamp() != nil {
+				if controllerutil.ContainsFinalizer(n, qsv1a1.Finalizer) {
+					ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+						ctx, e.MetaNew, "qsv1a1.QuarksSecret",
+						fmt.Sprintf("Update predicate passed for '%s/%s': deleted", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+					)
+					return true
+				}
+				return false
+			}
+
 			// reconcile if it was already generated and the spec changed except for `SecretLabels` & `SecretAnnotations`
 			if o.Status.IsGenerated() {
-				for _, key := range []string{"Type", "Request", "SecretName", "Copies"} {
+				for _, key := range []string{"Type", "Request", "SecretName", "Copies", "ExistingSecretPolicy"} {
 					old := reflect.ValueOf(o.Spec).FieldByName(key)
 					new := reflect.ValueOf(n.Spec).FieldByName(key)
 
@@ -80,6 +117,24 @@ func AddQuarksSecret(ctx context.Context, config *config.Config, mgr manager.Man
 				}
 			}
 
+			// reconcile if it was already generated and the rotation generation or annotation changed
+			if n.Status.IsGenerated() && n.RotationRequested() {
+				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+					ctx, e.MetaNew, "qsv1a1.QuarksSecret",
+					fmt.Sprintf("Update predicate passed for '%s/%s': rotation requested", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+				)
+				return true
+			}
+
+			// reconcile if a rollback to another version was requested
+			if n.RollbackRequested() {
+				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+					ctx, e.MetaNew, "qsv1a1.QuarksSecret",
+					fmt.Sprintf("Update predicate passed for '%s/%s': rollback requested", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+				)
+				return true
+			}
+
 			// reconcile if it was already generated and controller requested update
 			if n.Status.NotGenerated() {
 				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
@@ -91,7 +146,7 @@ func AddQuarksSecret(ctx context.Context, config *config.Config, mgr manager.Man
 			return false
 		},
 	}
-	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, nsPred, p)
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, options.quarksSecretPredicates(nsPred, p)...)
 	if err != nil {
 		return errors.Wrapf(err, "Watching quarks secrets failed in quarksSecret controller.")
 	}
@@ -2,7 +2,6 @@ package quarkssecret
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/pkg/errors"
@@ -10,7 +9,6 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -18,8 +16,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen/backend"
 	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
 	"code.cloudfoundry.org/quarks-secret/pkg/kube/util/mutate"
+	"code.cloudfoundry.org/quarks-secret/pkg/plugin"
 	"code.cloudfoundry.org/quarks-utils/pkg/config"
 	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
 	"code.cloudfoundry.org/quarks-utils/pkg/meltdown"
@@ -31,15 +31,22 @@ const HelmTemplate = "helm"
 
 type setReferenceFunc func(owner, object metav1.Object, scheme *runtime.Scheme) error
 
-// NewQuarksSecretReconciler returns a new ReconcileQuarksSecret
-func NewQuarksSecretReconciler(ctx context.Context, config *config.Config, mgr manager.Manager, generator credsgen.Generator, srf setReferenceFunc) reconcile.Reconciler {
+// NewQuarksSecretReconciler returns a new ReconcileQuarksSecret, which
+// generates the built-in secret types and the types of the handlers of the
+// options
+func NewQuarksSecretReconciler(ctx context.Context, options Options, mgr manager.Manager, srf setReferenceFunc, ccf ClusterClientFunc) reconcile.Reconciler {
 	return &ReconcileQuarksSecret{
 		ctx:          ctx,
-		config:       config,
+		config:       options.Config,
 		client:       mgr.GetClient(),
 		scheme:       mgr.GetScheme(),
-		generator:    generator,
+		generator:    options.generator(ctx),
+		generators:   options.generators(ctx),
+		handlers:     newSecretTypeHandlers(options.SecretTypeHandlers, nil),
+		defaults:     options.defaults(),
+		plugins:      options.pluginRunner(),
 		setReference: srf,
+		clusters:     newClusterClients(ccf),
 	}
 }
 
@@ -48,49 +55,14 @@ type ReconcileQuarksSecret struct {
 	ctx          context.Context
 	client       client.Client
 	generator    credsgen.Generator
+	generators   *backend.Generators
+	handlers     secretTypeHandlers
+	defaults     credsgen.Defaults
+	plugins      *plugin.Runner
 	scheme       *runtime.Scheme
 	setReference setReferenceFunc
 	config       *config.Config
-}
-
-type caNotReadyError struct {
-	message string
-}
-
-func newCaNotReadyError(message string) *caNotReadyError {
-	return &caNotReadyError{message: message}
-}
-
-// Error returns the error message
-func (e *caNotReadyError) Error() string {
-	return e.message
-}
-
-func isCaNotReady(o interface{}) bool {
-	err := o.(error)
-	err = errors.Cause(err)
-	_, ok := err.(*caNotReadyError)
-	return ok
-}
-
-type secNotReadyError struct {
-	message string
-}
-
-func newSecNotReadyError(message string) *secNotReadyError {
-	return &secNotReadyError{message: message}
-}
-
-// Error returns the error message
-func (e *secNotReadyError) Error() string {
-	return e.message
-}
-
-func isSecNotReady(o interface{}) bool {
-	err := o.(error)
-	err = errors.Cause(err)
-	_, ok := err.(*secNotReadyError)
-	return ok
+	clusters     *clusterClients
 }
 
 // Reconcile reads that state of the cluster for a QuarksSecret object and makes changes based on the state read
@@ -119,142 +91,133 @@ func (r *ReconcileQuarksSecret) Reconcile(request reconcile.Request) (reconcile.
 		ctxlog.Info(ctx, "Error reading the object")
 		return reconcile.Result{}, errors.Wrap(err, "Error reading quarksSecret")
 	}
+	if qsec.GetDeletionTimestamp() != nil {
+		ctxlog.Info(ctx, "Finalizing QuarksSecret")
+		err = r.finalize(ctx, qsec)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "finalizing quarksSecret failed.")
+		}
+		return reconcile.Result{}, nil
+	}
+
 	if meltdown.NewWindow(r.config.MeltdownDuration, qsec.Status.LastReconcile).Contains(time.Now()) {
 		ctxlog.WithEvent(qsec, "Meltdown").Debugf(ctx, "Resource '%s' is in meltdown, requeue reconcile after %s", qsec.GetNamespacedName(), r.config.MeltdownRequeueAfter)
 		return reconcile.Result{RequeueAfter: r.config.MeltdownRequeueAfter}, nil
 	}
 
-	// Create secret
-	switch qsec.Spec.Type {
-	case qsv1a1.Password:
-		ctxlog.Info(ctx, "Generating password")
-		err = r.createPasswordSecret(ctx, qsec)
+	if qsec.Spec.Type != qsv1a1.SecretCopy {
+		err = addFinalizer(ctx, r.client, qsec)
 		if err != nil {
-			ctxlog.Infof(ctx, "Error generating password secret: %s", err.Error())
-			return reconcile.Result{}, errors.Wrap(err, "generating password secret failed.")
+			return reconcile.Result{}, err
 		}
-	case qsv1a1.RSAKey:
-		ctxlog.Info(ctx, "Generating RSA Key")
-		err = r.createRSASecret(ctx, qsec)
-		if err != nil {
-			ctxlog.Infof(ctx, "Error generating RSA key secret: %s", err.Error())
-			return reconcile.Result{}, errors.Wrap(err, "generating RSA key secret failed.")
-		}
-	case qsv1a1.SSHKey:
-		ctxlog.Info(ctx, "Generating SSH Key")
-		err = r.createSSHSecret(ctx, qsec)
+
+		// Check if allowed to generate secret, could be created manually
+		// by a user
+		skipCreation, err := r.handleExistingSecret(ctx, qsec)
 		if err != nil {
-			ctxlog.Infof(ctx, "Error generating SSH key secret: %s", err.Error())
-			return reconcile.Result{}, errors.Wrap(err, "generating SSH key secret failed.")
+			return reconcile.Result{}, errors.Wrap(err, "handling existing secret failed.")
 		}
-	case qsv1a1.Certificate, qsv1a1.TLS:
-		ctxlog.Info(ctx, "Generating certificate")
-		err = r.createCertificateSecret(ctx, qsec)
-		if err != nil {
-			if isCaNotReady(err) {
-				ctxlog.Info(ctx, fmt.Sprintf("CA for secret '%s' is not ready yet: %s", request.NamespacedName, err))
-				return reconcile.Result{RequeueAfter: time.Second * 5}, nil
-			}
-			ctxlog.Info(ctx, "Error generating certificate secret: "+err.Error())
-			return reconcile.Result{}, errors.Wrap(err, "generating certificate secret.")
+		if skipCreation {
+			err = r.updateStatus(ctx, qsec, false)
+			return reconcile.Result{}, err
 		}
-	case qsv1a1.BasicAuth:
-		err = r.createBasicAuthSecret(ctx, qsec)
+	}
+
+	if qsec.RollbackRequested() {
+		ctxlog.Infof(ctx, "Rolling back to version %d", *qsec.Spec.RollbackTo)
+		restored, err := r.rollback(ctx, qsec)
 		if err != nil {
-			return reconcile.Result{}, errors.Wrap(err, "generating basic-auth secret")
+			return reconcile.Result{}, errors.Wrap(err, "rolling back secret failed.")
 		}
-	case qsv1a1.TemplatedConfig:
-		if err := r.createTemplatedConfigSecret(ctx, qsec); err != nil {
-			if isSecNotReady(err) {
-				ctxlog.Info(ctx, fmt.Sprintf("Secrets '%s' is not ready yet: %s", request.NamespacedName, err))
-				return reconcile.Result{RequeueAfter: time.Second * 5}, nil
-			}
-			ctxlog.Info(ctx, "Error generating templatedConfig secret: "+err.Error())
-			return reconcile.Result{}, errors.Wrap(err, "generating templatedConfig secret.")
+		if restored {
+			return reconcile.Result{}, nil
 		}
-	case qsv1a1.SecretCopy:
+	}
+
+	approved, err := r.validateFIPS(ctx, qsec)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "validating FIPS compliance failed.")
+	}
+	if !approved {
+		return reconcile.Result{}, nil
+	}
+
+	if qsec.Spec.Type == qsv1a1.SecretCopy {
 		// noop
 		return reconcile.Result{}, nil
-	case qsv1a1.DockerConfigJSON:
-		ctxlog.Info(ctx, "Generating dockerConfigJson")
-		err = r.createDockerConfigJSON(ctx, qsec)
-		if err != nil {
-			if isSecNotReady(err) {
-				ctxlog.Info(ctx, fmt.Sprintf("Secrets '%s' is not ready yet: %s", request.NamespacedName, err))
-				return reconcile.Result{RequeueAfter: time.Second * 5}, nil
-			}
-			ctxlog.Info(ctx, "Error generating dockerConfigJson secret: "+err.Error())
-			return reconcile.Result{}, errors.Wrap(err, "generating dockerConfigJson secret.")
-		}
-	default:
+	}
+
+	handler, ok := r.handlers.handlerFor(qsec.Spec.Type)
+	if !ok {
 		err = ctxlog.WithEvent(qsec, "InvalidTypeError").Errorf(ctx, "Invalid type: %s", qsec.Spec.Type)
 		return reconcile.Result{}, err
 	}
-	r.updateStatus(ctx, qsec)
-	return reconcile.Result{}, nil
-}
-
-func (r *ReconcileQuarksSecret) updateStatus(ctx context.Context, qsec *qsv1a1.QuarksSecret) {
-	qsec.Status.Generated = pointers.Bool(true)
-	qsec.Status.Copied = pointers.Bool(false)
 
-	now := metav1.Now()
-	qsec.Status.LastReconcile = &now
-	err := r.client.Status().Update(ctx, qsec)
+	// Create secret
+	ctxlog.Infof(ctx, "Generating %s secret", qsec.Spec.Type)
+	err = r.generate(ctx, handler, qsec)
 	if err != nil {
-		ctxlog.Errorf(ctx, "could not create or update QuarksSecret status '%s': %v", qsec.GetNamespacedName(), err)
+		if isNotReady(err) {
+			ctxlog.Infof(ctx, "Dependencies of QuarksSecret '%s' are not ready yet: %s", request.NamespacedName, err)
+			return reconcile.Result{RequeueAfter: dependencyRequeueAfter}, nil
+		}
+		ctxlog.Infof(ctx, "Error generating %s secret: %s", qsec.Spec.Type, err)
+		return reconcile.Result{}, errors.Wrapf(err, "generating %s secret", qsec.Spec.Type)
 	}
+	err = r.updateStatus(ctx, qsec, true)
+	return reconcile.Result{}, err
 }
 
-// Skip creation when
-// * secret is already generated according to qsecs status field
-// * secret exists, but was not generated (user created secret)
-func (r *ReconcileQuarksSecret) skipCreation(ctx context.Context, qsec *qsv1a1.QuarksSecret) (bool, error) {
-	secretName := qsec.Spec.SecretName
-	existingSecret := &corev1.Secret{}
-	err := r.client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: qsec.GetNamespace()}, existingSecret)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return false, nil
+// updateStatus marks the secret as generated. It records a rotation only if
+// one was requested and new credentials have been written.
+func (r *ReconcileQuarksSecret) updateStatus(ctx context.Context, qsec *qsv1a1.QuarksSecret, written bool) error {
+	now := metav1.Now()
+	generated := qsec.Status.Generated == nil && written
+	rotated := written && qsec.RotationDue()
+	version := qsec.Status.Version
+	// conditions set while reconciling, they are applied again if the
+	// status has to be read again
+	conditions := []qsv1a1.QuarksSecretCondition{}
+	for _, conditionType := range []qsv1a1.QuarksSecretConditionType{qsv1a1.ConditionExistingSecret, qsv1a1.ConditionFIPSCompliant} {
+		if condition := qsec.Status.GetCondition(conditionType); condition != nil {
+			conditions = append(conditions, *condition)
 		}
-		return false, errors.Wrapf(err, "could not get secret")
 	}
 
-	secretLabels := existingSecret.GetLabels()
-	if secretLabels == nil {
-		secretLabels = map[string]string{}
-	}
+	err := patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+		if rotated || qsec.RotationRequested() || status.Rotation != nil {
+			rotation := &qsv1a1.RotationStatus{}
+			if status.Rotation != nil {
+				rotation = status.Rotation
+			}
+			rotation.Generation = qsec.Spec.Rotation.Generation
+			rotation.Annotation = qsec.Annotations[qsv1a1.AnnotationRotate]
+			rotation.Requested = false
+			if rotated {
+				rotation.LastRotation = &now
+			}
+			status.Rotation = rotation
+		}
 
-	// skip if the secret was not created by the operator
-	if secretLabels[qsv1a1.LabelKind] != qsv1a1.GeneratedSecretKind {
-		ctxlog.Debugf(ctx, "Existing secret %s/%s doesn't have a label %s=%s",
-			existingSecret.GetNamespace(),
-			existingSecret.GetName(),
-			qsv1a1.LabelKind,
-			qsv1a1.GeneratedSecretKind,
-		)
-		return true, nil
+		for _, condition := range conditions {
+			status.SetCondition(condition)
+		}
+		status.Version = version
+		status.Generated = pointers.Bool(true)
+		status.Copied = pointers.Bool(false)
+		status.Drift = nil
+		status.LastReconcile = &now
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
 	}
 
-	return false, nil
-}
-
-func (r *ReconcileQuarksSecret) createSecrets(ctx context.Context, qsec *qsv1a1.QuarksSecret, secret *corev1.Secret) error {
-	// Create the main secret
-	// Check if allowed to generate secret, could be already done or
-	// created manually by a user
-	skipCreation, err := r.skipCreation(ctx, qsec)
-	if err != nil {
-		ctxlog.Errorf(ctx, "Error reading the secret: %v", err.Error())
+	if generated {
+		ctxlog.WithEvent(qsec, "Generated").Infof(ctx, "Generated secret '%s/%s'", qsec.Namespace, qsec.Spec.SecretName)
 	}
-	if skipCreation {
-		ctxlog.WithEvent(qsec, "SkipCreation").Infof(ctx, "Skip creation: Secret '%s/%s' already exists and it's not generated", qsec.Namespace, qsec.Spec.SecretName)
-	} else {
-		if err := r.createSecret(ctx, qsec, secret); err != nil {
-			return err
-		}
+	if rotated {
+		ctxlog.WithEvent(qsec, "Rotated").Infof(ctx, "Rotated secret '%s/%s' at rotation generation %d", qsec.Namespace, qsec.Spec.SecretName, qsec.Status.Rotation.Generation)
 	}
-
 	return nil
 }
 
@@ -275,13 +238,40 @@ func (r *ReconcileQuarksSecret) createSecret(ctx context.Context, qsec *qsv1a1.Q
 		return errors.Wrapf(err, "error setting owner for secret '%s' to QuarksSecret '%s'", secret.GetName(), qsec.GetNamespacedName())
 	}
 
-	op, err := controllerutil.CreateOrUpdate(ctx, r.client, secret, mutate.SecretMutateFn(secret))
+	mutateFn := mutate.SecretMutateFn(secret)
+	// cluster signed certificates are assembled by the CSR reconciler from a
+	// separate private key secret
+	isMain := secret.Name == qsec.Spec.SecretName
+	var data map[string][]byte
+	if isMain {
+		keepPrevious := keepPreviousMutateFn(secret, qsec.Spec.Rotation, mutateFn)
+		mutateFn = func() error {
+			if err := keepPrevious(); err != nil {
+				return err
+			}
+			// the data only changes if the mutation sets string data
+			if len(secret.StringData) > 0 {
+				data = versionData(secret)
+			}
+			if secret.Annotations == nil {
+				secret.Annotations = map[string]string{}
+			}
+			secret.Annotations[qsv1a1.AnnotationContentHash] = contentHash(versionData(secret))
+			return nil
+		}
+	}
+
+	reason := versionReason(qsec)
+	op, err := controllerutil.CreateOrUpdate(ctx, r.client, secret, mutateFn)
 	if err != nil {
 		return errors.Wrapf(err, "could not create or update secret '%s/%s'", secret.Namespace, secret.GetName())
 	}
 
 	if op != "unchanged" {
 		ctxlog.Debugf(ctx, "Secret '%s' has been %s", secret.Name, op)
+		if data != nil {
+			return r.recordVersion(ctx, qsec, secret.Type, data, reason)
+		}
 	}
 
 	return nil
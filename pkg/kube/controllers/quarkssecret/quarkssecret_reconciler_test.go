@@ -2,7 +2,19 @@ package quarkssecret_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/dchest/uniuri"
@@ -21,14 +33,19 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen/backend"
 	generatorfakes "code.cloudfoundry.org/quarks-secret/pkg/credsgen/fakes"
+	inmemorygenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/in_memory_generator"
+	vaultgenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/vault_generator"
 	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
 	"code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/scheme"
 	"code.cloudfoundry.org/quarks-secret/pkg/kube/controllers"
 	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
 	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	"code.cloudfoundry.org/quarks-secret/pkg/plugin"
 	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
 	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
 	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
 )
 
@@ -42,6 +59,7 @@ var _ = Describe("ReconcileQuarksSecret", func() {
 		config           *cfcfg.Config
 		client           *cfakes.FakeClient
 		generator        *generatorfakes.FakeGenerator
+		options          qscontroller.Options
 		qSecret          *qsv1a1.QuarksSecret
 		setReferenceFunc func(owner, object metav1.Object, scheme *runtime.Scheme) error = func(owner, object metav1.Object, scheme *runtime.Scheme) error { return nil }
 	)
@@ -56,8 +74,9 @@ var _ = Describe("ReconcileQuarksSecret", func() {
 		ctx = ctxlog.NewParentContext(log)
 		qSecret = &qsv1a1.QuarksSecret{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      "foo",
-				Namespace: "default",
+				Name:       "foo",
+				Namespace:  "default",
+				Finalizers: []string{qsv1a1.Finalizer},
 			},
 			Spec: qsv1a1.QuarksSecretSpec{
 				Type:       "password",
@@ -68,6 +87,7 @@ var _ = Describe("ReconcileQuarksSecret", func() {
 			},
 		}
 		generator = &generatorfakes.FakeGenerator{}
+		options = qscontroller.Options{Config: config, Generator: generator}
 		client = &cfakes.FakeClient{}
 		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
 			switch object := object.(type) {
@@ -83,7 +103,7 @@ var _ = Describe("ReconcileQuarksSecret", func() {
 	})
 
 	JustBeforeEach(func() {
-		reconciler = qscontroller.NewQuarksSecretReconciler(ctx, config, manager, generator, setReferenceFunc)
+		reconciler = qscontroller.NewQuarksSecretReconciler(ctx, options, manager, setReferenceFunc, nil)
 	})
 
 	Context("if the resource can not be resolved", func() {
@@ -158,6 +178,285 @@ var _ = Describe("ReconcileQuarksSecret", func() {
 		})
 	})
 
+	Context("when the namespace has quarks secret defaults", func() {
+		var (
+			defaults []qsv1a1.QuarksSecretDefaults
+			password string
+		)
+
+		BeforeEach(func() {
+			defaults = []qsv1a1.QuarksSecretDefaults{{
+				ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "default"},
+				Spec: qsv1a1.QuarksSecretDefaultsSpec{
+					PasswordPolicy: &qsv1a1.PasswordPolicy{Characters: "ab"},
+				},
+			}}
+			options.Defaults = &credsgen.Defaults{
+				KeyAlgorithm:      "rsa",
+				KeySize:           2048,
+				CertificateExpiry: 365,
+				PasswordLength:    16,
+			}
+
+			client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+				if object, ok := object.(*qsv1a1.QuarksSecretDefaultsList); ok {
+					object.Items = defaults
+				}
+				return nil
+			})
+			client.CreateCalls(func(context context.Context, object runtime.Object, _ ...crc.CreateOption) error {
+				password = object.(*corev1.Secret).StringData["password"]
+				return nil
+			})
+		})
+
+		JustBeforeEach(func() {
+			options.Generator = inmemorygenerator.NewInMemoryGeneratorWithDefaults(log, *options.Defaults)
+			reconciler = qscontroller.NewQuarksSecretReconciler(ctx, options, manager, setReferenceFunc, nil)
+		})
+
+		It("generates passwords with the policy of the namespace and the operator's length", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(password).To(MatchRegexp("^[ab]{16}$"))
+		})
+
+		It("prefers the password length of the namespace", func() {
+			defaults[0].Spec.PasswordPolicy.Length = 8
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(password).To(MatchRegexp("^[ab]{8}$"))
+		})
+
+		It("fails if the defaults are invalid", func() {
+			defaults[0].Spec.KeyAlgorithm = "ecdsa"
+			defaults[0].Spec.KeySize = 2048
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ecdsa key size 2048"))
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+
+		It("fails if the namespace has more than one", func() {
+			defaults = append(defaults, *defaults[0].DeepCopy())
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("only one is allowed"))
+		})
+	})
+
+	Context("when the quarks secret references a generator", func() {
+		BeforeEach(func() {
+			generator.GeneratePasswordReturns("securepassword")
+
+			generators, err := backend.New(log, credsgen.NewDefaults(), backend.Config{})
+			Expect(err).ToNot(HaveOccurred())
+			options.Generators = generators
+		})
+
+		It("uses the default generator for the in-memory backend", func() {
+			qSecret.Spec.GeneratorRef = backend.InMemory
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator.GeneratePasswordCallCount()).To(Equal(0))
+			Expect(client.CreateCallCount()).To(Equal(1))
+		})
+
+		It("returns an error if the backend is not configured", func() {
+			qSecret.Spec.GeneratorRef = backend.Vault
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("generator 'vault' is not configured"))
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+
+		Context("with the vault backend", func() {
+			var (
+				vault  *httptest.Server
+				secret *corev1.Secret
+			)
+
+			BeforeEach(func() {
+				vault = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/v1/pki/issue/quarks" {
+						http.NotFound(w, r)
+						return
+					}
+					_, _ = w.Write([]byte(`{"data": {"certificate": "the-certificate", "private_key": "the-private-key", "issuing_ca": "the-vault-ca"}}`))
+				}))
+
+				generators, err := backend.New(log, credsgen.NewDefaults(), backend.Config{
+					Vault: vaultgenerator.Config{Address: vault.URL, PKIRole: "quarks"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				options.Generators = generators
+
+				qSecret.Spec.Type = qsv1a1.Certificate
+				qSecret.Spec.GeneratorRef = backend.Vault
+				qSecret.Spec.Request.CertificateRequest = qsv1a1.CertificateRequest{CommonName: "example.com"}
+
+				client.CreateCalls(func(context context.Context, object runtime.Object, _ ...crc.CreateOption) error {
+					secret = object.(*corev1.Secret)
+					return nil
+				})
+			})
+
+			AfterEach(func() {
+				vault.Close()
+			})
+
+			It("validates and issues certificates without a CA reference", func() {
+				validator := qscontroller.NewValidator(ctx, options)
+				Expect(validator.ValidateQuarksSecret(qSecret)).To(BeEmpty())
+
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(secret.StringData).To(HaveKeyWithValue("certificate", "the-certificate"))
+				Expect(secret.StringData).To(HaveKeyWithValue("private_key", "the-private-key"))
+				Expect(secret.StringData).To(HaveKeyWithValue("ca", "the-vault-ca"))
+			})
+
+			It("rejects CA references and CAs", func() {
+				qSecret.Spec.Request.CertificateRequest.CARef = qsv1a1.SecretReference{Name: "ca", Key: "certificate"}
+				qSecret.Spec.Request.CertificateRequest.IsCA = true
+
+				errs := qscontroller.NewValidator(ctx, options).ValidateQuarksSecret(qSecret)
+				Expect(errs.ToAggregate()).To(MatchError(ContainSubstring("spec.request.certificate.CARef.name: Invalid value")))
+				Expect(errs.ToAggregate()).To(MatchError(ContainSubstring("spec.request.certificate.isCA: Invalid value")))
+			})
+
+			It("still requires a CA reference for the in-memory backend", func() {
+				qSecret.Spec.GeneratorRef = ""
+
+				errs := qscontroller.NewValidator(ctx, options).ValidateQuarksSecret(qSecret)
+				Expect(errs.ToAggregate()).To(MatchError(ContainSubstring("spec.request.certificate.CARef.name: Required value")))
+			})
+		})
+	})
+
+	Context("in FIPS mode", func() {
+		var (
+			statusWriter *cfakes.FakeStatusWriter
+			defaults     []qsv1a1.QuarksSecretDefaults
+			caCert       []byte
+		)
+
+		certificateWithCurve := func(curve elliptic.Curve) []byte {
+			key, err := ecdsa.GenerateKey(curve, rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			template := &x509.Certificate{
+				SerialNumber:          big.NewInt(1),
+				Subject:               pkix.Name{CommonName: "ca"},
+				NotBefore:             time.Now(),
+				NotAfter:              time.Now().Add(time.Hour),
+				IsCA:                  true,
+				BasicConstraintsValid: true,
+			}
+			der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+			Expect(err).ToNot(HaveOccurred())
+			return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		}
+
+		fipsCondition := func() *qsv1a1.QuarksSecretCondition {
+			Expect(statusWriter.PatchCallCount()).To(BeNumerically(">", 0))
+			_, object, _, _ := statusWriter.PatchArgsForCall(statusWriter.PatchCallCount() - 1)
+			return object.(*qsv1a1.QuarksSecret).Status.GetCondition(qsv1a1.ConditionFIPSCompliant)
+		}
+
+		BeforeEach(func() {
+			defaults = nil
+			caCert = certificateWithCurve(elliptic.P256())
+			fips := credsgen.NewDefaults()
+			fips.FIPS = true
+			options.Defaults = &fips
+			generator.GeneratePasswordReturns("securepassword")
+			generator.GenerateCertificateReturns(credsgen.Certificate{Certificate: []byte("cert"), PrivateKey: []byte("key")}, nil)
+
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					qSecret.DeepCopyInto(object)
+					return nil
+				case *corev1.Secret:
+					if nn.Name == "ca" {
+						object.Data = map[string][]byte{"certificate": caCert, "private_key": []byte("key")}
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+			client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+				if object, ok := object.(*qsv1a1.QuarksSecretDefaultsList); ok {
+					object.Items = defaults
+				}
+				return nil
+			})
+			statusWriter = &cfakes.FakeStatusWriter{}
+			client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		})
+
+		It("generates secrets with approved parameters", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(1))
+
+			condition := fipsCondition()
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(qsv1a1.FIPSReasonApproved))
+		})
+
+		It("rejects namespace defaults, which are not approved", func() {
+			defaults = []qsv1a1.QuarksSecretDefaults{{
+				Spec: qsv1a1.QuarksSecretDefaultsSpec{KeyAlgorithm: "ecdsa", KeySize: 521},
+			}}
+
+			result, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+			Expect(client.CreateCallCount()).To(Equal(0))
+
+			condition := fipsCondition()
+			Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(qsv1a1.FIPSReasonNotApproved))
+			Expect(condition.Message).To(ContainSubstring("ecdsa key size 521"))
+		})
+
+		Context("when generating certificates", func() {
+			BeforeEach(func() {
+				qSecret.Spec.Type = qsv1a1.Certificate
+				qSecret.Spec.Request.CertificateRequest = qsv1a1.CertificateRequest{
+					CommonName: "example.com",
+					CARef:      qsv1a1.SecretReference{Name: "ca", Key: "certificate"},
+					CAKeyRef:   qsv1a1.SecretReference{Name: "ca", Key: "private_key"},
+				}
+			})
+
+			It("signs with an approved CA", func() {
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(generator.GenerateCertificateCallCount()).To(Equal(1))
+				Expect(fipsCondition().Status).To(Equal(corev1.ConditionTrue))
+			})
+
+			It("rejects CAs, which are not approved", func() {
+				caCert = certificateWithCurve(elliptic.P521())
+
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(generator.GenerateCertificateCallCount()).To(Equal(0))
+
+				condition := fipsCondition()
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Message).To(ContainSubstring("P-521"))
+			})
+		})
+	})
+
 	Context("when generating RSA keys", func() {
 		BeforeEach(func() {
 			qSecret.Spec.Type = "rsa"
@@ -510,9 +809,79 @@ var _ = Describe("ReconcileQuarksSecret", func() {
 		})
 	})
 
+	Context("when creating a plugin type", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "quarks-secret-plugins")
+			Expect(err).ToNot(HaveOccurred())
+			options.PluginRunner = plugin.NewRunner(dir)
+
+			path := filepath.Join(dir, "example.com", "token")
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(`#!/bin/sh
+grep -q '"secretName":"generated-secret"' || exit 1
+echo '{"apiVersion": "quarks.cloudfoundry.org/v1alpha1", "kind": "PluginResponse", "data": {"token": "t0k3n"}}'
+`), 0755)).To(Succeed())
+
+			qSecret.Spec.Type = "example.com/token"
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("creates a secret with the data of the plugin", func() {
+			client.CreateCalls(func(context context.Context, object runtime.Object, _ ...crc.CreateOption) error {
+				secret := object.(*corev1.Secret)
+				Expect(secret.Name).To(Equal("generated-secret"))
+				Expect(secret.StringData).To(Equal(map[string]string{"token": "t0k3n"}))
+				return nil
+			})
+
+			result, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(1))
+			Expect(result).To(Equal(reconcile.Result{}))
+		})
+
+		It("returns an error if there is no plugin for the type", func() {
+			qSecret.Spec.Type = "example.com/missing"
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no plugin for type 'example.com/missing'"))
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+
+		It("requeues until the referenced secrets exist", func() {
+			qSecret.Spec.Request.PluginRequest.Values = map[string]qsv1a1.SecretReference{
+				"seed": {Name: "seed", Key: "value"},
+			}
+
+			result, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(5 * time.Second))
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+	})
+
 	Context("when creating basic-auth", func() {
 		BeforeEach(func() {
 			qSecret.Spec.Type = "basic-auth"
+			generator.GeneratePasswordReturns("some-secret-password")
+		})
+
+		When("the generator returns an empty password", func() {
+			It("returns an error and doesn't create a secret", func() {
+				generator.GeneratePasswordReturns("")
+
+				_, err := reconciler.Reconcile(request)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("generator returned an empty password for 'foo/username'"))
+				Expect(client.CreateCallCount()).To(Equal(0))
+			})
 		})
 
 		It("creates a secret with k8s type basic-auth", func() {
@@ -685,4 +1054,611 @@ var _ = Describe("ReconcileQuarksSecret", func() {
 
 		})
 	})
+
+	Context("when a rotation is requested", func() {
+		var statusWriter *cfakes.FakeStatusWriter
+
+		BeforeEach(func() {
+			generator.GeneratePasswordReturns("securepassword")
+			statusWriter = &cfakes.FakeStatusWriter{}
+			client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+
+			qSecret.Status.Generated = pointers.Bool(true)
+			qSecret.Status.Rotation = &qsv1a1.RotationStatus{Generation: 1}
+			qSecret.Spec.Rotation.Generation = 2
+			qSecret.Annotations = map[string]string{qsv1a1.AnnotationRotate: "2020-10-01"}
+		})
+
+		It("regenerates the secret and records the rotation in the status", func() {
+			result, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reconcile.Result{}).To(Equal(result))
+			Expect(client.CreateCallCount()).To(Equal(1))
+
+			Expect(statusWriter.PatchCallCount()).To(Equal(1))
+			_, object, _, _ := statusWriter.PatchArgsForCall(0)
+			qsec := object.(*qsv1a1.QuarksSecret)
+			Expect(qsec.RotationRequested()).To(BeFalse())
+			Expect(qsec.Status.Rotation.Generation).To(Equal(int64(2)))
+			Expect(qsec.Status.Rotation.Annotation).To(Equal("2020-10-01"))
+			Expect(qsec.Status.Rotation.LastRotation).ToNot(BeNil())
+		})
+
+		It("doesn't record a rotation for the initial generation", func() {
+			qSecret.Status = qsv1a1.QuarksSecretStatus{}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, object, _, _ := statusWriter.PatchArgsForCall(0)
+			qsec := object.(*qsv1a1.QuarksSecret)
+			Expect(qsec.Status.Rotation.Generation).To(Equal(int64(2)))
+			Expect(qsec.Status.Rotation.LastRotation).To(BeNil())
+		})
+
+		It("records the rotation requested by a rotation config", func() {
+			qSecret.Spec.Rotation.Generation = 1
+			qSecret.Annotations = nil
+			qSecret.Status.Generated = pointers.Bool(false)
+			qSecret.Status.Rotation = &qsv1a1.RotationStatus{Generation: 1, Requested: true}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(1))
+
+			_, object, _, _ := statusWriter.PatchArgsForCall(0)
+			qsec := object.(*qsv1a1.QuarksSecret)
+			Expect(qsec.Status.Rotation.Requested).To(BeFalse())
+			Expect(qsec.Status.Rotation.LastRotation).ToNot(BeNil())
+			Expect(qsec.RotationDue()).To(BeFalse())
+		})
+
+		It("records the rotation and the version, if the status was written in the meantime", func() {
+			qSecret.Spec.Rotation.Generation = 1
+			qSecret.Annotations = nil
+			qSecret.ResourceVersion = "1"
+			qSecret.Status.Generated = pointers.Bool(false)
+			qSecret.Status.Version = 1
+			qSecret.Status.Rotation = &qsv1a1.RotationStatus{Generation: 1, Requested: true}
+			calls := 0
+			statusWriter.PatchCalls(func(context.Context, runtime.Object, crc.Patch, ...crc.PatchOption) error {
+				calls++
+				if calls == 1 {
+					// the restart reconciler recorded the checksum of the
+					// new secret first
+					qSecret.Status.RestartChecksum = "new-hash"
+					qSecret.ResourceVersion = "2"
+					return errors.NewConflict(schema.GroupResource{}, "foo", fmt.Errorf("object was modified"))
+				}
+				return nil
+			})
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(1))
+
+			Expect(statusWriter.PatchCallCount()).To(Equal(2))
+			_, object, _, _ := statusWriter.PatchArgsForCall(1)
+			qsec := object.(*qsv1a1.QuarksSecret)
+			Expect(qsec.Status.IsGenerated()).To(BeTrue())
+			Expect(qsec.Status.Version).To(Equal(int64(2)))
+			Expect(qsec.Status.Rotation.Requested).To(BeFalse())
+			Expect(qsec.Status.Rotation.LastRotation).ToNot(BeNil())
+			Expect(qsec.Status.RestartChecksum).To(Equal("new-hash"))
+		})
+
+		It("returns the error, if the status can't be written", func() {
+			statusWriter.PatchReturns(fmt.Errorf("fake-error"))
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not update QuarksSecret status"))
+		})
+
+		It("doesn't record a rotation if the secret is regenerated without a request", func() {
+			qSecret.Spec.Rotation.Generation = 1
+			qSecret.Annotations = nil
+			qSecret.Status.Generated = pointers.Bool(false)
+			qSecret.Status.Rotation = &qsv1a1.RotationStatus{Generation: 1}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(1))
+
+			_, object, _, _ := statusWriter.PatchArgsForCall(0)
+			qsec := object.(*qsv1a1.QuarksSecret)
+			Expect(qsec.Status.IsGenerated()).To(BeTrue())
+			Expect(qsec.Status.Rotation.LastRotation).To(BeNil())
+		})
+
+		It("doesn't record a rotation if an existing secret is left alone", func() {
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					qSecret.DeepCopyInto(object)
+					return nil
+				case *corev1.Secret:
+					if nn.Name == "generated-secret" {
+						object.Name = nn.Name
+						object.Namespace = nn.Namespace
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(0))
+
+			_, object, _, _ := statusWriter.PatchArgsForCall(0)
+			qsec := object.(*qsv1a1.QuarksSecret)
+			Expect(qsec.Status.Rotation.Generation).To(Equal(int64(2)))
+			Expect(qsec.Status.Rotation.LastRotation).To(BeNil())
+		})
+	})
+
+	Context("when keeping previous credentials", func() {
+		var secret *corev1.Secret
+
+		BeforeEach(func() {
+			generator.GeneratePasswordReturns("new-password")
+			qSecret.Spec.Rotation.KeepPrevious = true
+			qSecret.Spec.Rotation.GracePeriod = &metav1.Duration{Duration: 30 * time.Minute}
+
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "generated-secret",
+					Namespace: "default",
+					Labels:    map[string]string{qsv1a1.LabelKind: qsv1a1.GeneratedSecretKind},
+				},
+				Data: map[string][]byte{
+					"password": []byte("old-password"),
+				},
+			}
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					qSecret.DeepCopyInto(object)
+				case *corev1.Secret:
+					secret.DeepCopyInto(object)
+				}
+				return nil
+			})
+		})
+
+		It("keeps the previous password until the grace period ends", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.UpdateCallCount()).To(Equal(1))
+
+			_, object, _ := client.UpdateArgsForCall(0)
+			updated := object.(*corev1.Secret)
+			Expect(updated.StringData).To(HaveKeyWithValue("password", "new-password"))
+			Expect(updated.StringData).To(HaveKeyWithValue("previous_password", "old-password"))
+
+			expires, err := time.Parse(time.RFC3339, updated.Annotations[qsv1a1.AnnotationPreviousExpires])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(expires).To(BeTemporally("~", time.Now().Add(30*time.Minute), time.Minute))
+		})
+
+		It("doesn't keep the previous password if disabled", func() {
+			qSecret.Spec.Rotation.KeepPrevious = false
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, object, _ := client.UpdateArgsForCall(0)
+			updated := object.(*corev1.Secret)
+			Expect(updated.StringData).ToNot(HaveKey("previous_password"))
+			Expect(updated.Annotations).ToNot(HaveKey(qsv1a1.AnnotationPreviousExpires))
+		})
+	})
+
+	Context("when keeping a history of versions", func() {
+		var (
+			secret       *corev1.Secret
+			history      []corev1.Secret
+			statusWriter *cfakes.FakeStatusWriter
+		)
+
+		newHistorySecret := func(version int64, password string) corev1.Secret {
+			return corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("generated-secret-v%d", version),
+					Namespace: "default",
+					Labels:    map[string]string{qsv1a1.LabelKind: qsv1a1.HistorySecretKind},
+					Annotations: map[string]string{
+						qsv1a1.AnnotationQSecName: "foo",
+						qsv1a1.AnnotationVersion:  fmt.Sprintf("%d", version),
+					},
+				},
+				Data: map[string][]byte{"password": []byte(password)},
+			}
+		}
+
+		createdSecrets := func() []*corev1.Secret {
+			created := []*corev1.Secret{}
+			for i := 0; i < client.CreateCallCount(); i++ {
+				_, object, _ := client.CreateArgsForCall(i)
+				created = append(created, object.(*corev1.Secret))
+			}
+			return created
+		}
+
+		updatedStatus := func() qsv1a1.QuarksSecretStatus {
+			Expect(statusWriter.PatchCallCount()).To(Equal(1))
+			_, object, _, _ := statusWriter.PatchArgsForCall(0)
+			return object.(*qsv1a1.QuarksSecret).Status
+		}
+
+		BeforeEach(func() {
+			generator.GeneratePasswordReturns("new-password")
+			qSecret.Spec.HistoryLimit = 2
+			qSecret.Status.Generated = pointers.Bool(false)
+			qSecret.Status.Rotation = &qsv1a1.RotationStatus{Requested: true}
+			qSecret.Status.Version = 3
+
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "generated-secret",
+					Namespace: "default",
+					Labels:    map[string]string{qsv1a1.LabelKind: qsv1a1.GeneratedSecretKind},
+				},
+				Data: map[string][]byte{
+					"password": []byte("old-password"),
+				},
+			}
+			history = []corev1.Secret{
+				newHistorySecret(2, "older-password"),
+				newHistorySecret(3, "old-password"),
+			}
+
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					qSecret.DeepCopyInto(object)
+					return nil
+				case *corev1.Secret:
+					if nn.Name == "generated-secret" {
+						secret.DeepCopyInto(object)
+						return nil
+					}
+					for _, s := range history {
+						if s.Name == nn.Name {
+							s.DeepCopyInto(object)
+							return nil
+						}
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+			client.CreateCalls(func(context context.Context, object runtime.Object, _ ...crc.CreateOption) error {
+				history = append(history, *object.(*corev1.Secret))
+				return nil
+			})
+			client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+				if object, ok := object.(*corev1.SecretList); ok {
+					object.Items = history
+				}
+				return nil
+			})
+			statusWriter = &cfakes.FakeStatusWriter{}
+			client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		})
+
+		It("records the regenerated secret as a new version", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			created := createdSecrets()
+			Expect(created).To(HaveLen(1))
+			Expect(created[0].Name).To(Equal("generated-secret-v4"))
+			Expect(created[0].Immutable).To(Equal(pointers.Bool(true)))
+			Expect(created[0].Data).To(Equal(map[string][]byte{"password": []byte("new-password")}))
+			Expect(created[0].Annotations).To(HaveKeyWithValue(qsv1a1.AnnotationVersionReason, "rotation"))
+
+			Expect(client.DeleteCallCount()).To(Equal(1))
+			_, object, _ := client.DeleteArgsForCall(0)
+			Expect(object.(*corev1.Secret).Name).To(Equal("generated-secret-v2"))
+
+			Expect(updatedStatus().Version).To(Equal(int64(4)))
+		})
+
+		It("only counts versions if the history is disabled", func() {
+			qSecret.Spec.HistoryLimit = 0
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(0))
+			for i := 0; i < client.ListCallCount(); i++ {
+				_, object, _ := client.ListArgsForCall(i)
+				Expect(object).ToNot(BeAssignableToTypeOf(&corev1.SecretList{}))
+			}
+			Expect(updatedStatus().Version).To(Equal(int64(4)))
+		})
+
+		Context("when a rollback is requested", func() {
+			BeforeEach(func() {
+				qSecret.Spec.RollbackTo = pointers.Int64(2)
+				qSecret.Status.Generated = pointers.Bool(true)
+			})
+
+			It("restores the version into the generated secret", func() {
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(generator.GeneratePasswordCallCount()).To(Equal(0))
+
+				Expect(client.UpdateCallCount()).To(Equal(1))
+				_, object, _ := client.UpdateArgsForCall(0)
+				Expect(object.(*corev1.Secret).Data).To(Equal(map[string][]byte{"password": []byte("older-password")}))
+
+				created := createdSecrets()
+				Expect(created).To(HaveLen(1))
+				Expect(created[0].Name).To(Equal("generated-secret-v4"))
+				Expect(created[0].Annotations).To(HaveKeyWithValue(qsv1a1.AnnotationVersionReason, "rollback"))
+
+				status := updatedStatus()
+				Expect(status.Version).To(Equal(int64(4)))
+				Expect(status.Rollback.Version).To(Equal(int64(2)))
+				Expect(status.Copied).To(Equal(pointers.Bool(false)))
+			})
+
+			It("records the rollback condition", func() {
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+
+				condition := updatedStatus().GetCondition(qsv1a1.ConditionRollback)
+				Expect(condition).ToNot(BeNil())
+				Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+				Expect(condition.Reason).To(Equal(qsv1a1.RollbackReasonRestored))
+			})
+
+			It("records a rollback to a version missing from the history as failed and generates the secret", func() {
+				qSecret.Spec.RollbackTo = pointers.Int64(1)
+
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(generator.GeneratePasswordCallCount()).To(Equal(1))
+
+				Expect(statusWriter.PatchCallCount()).To(Equal(2))
+				_, object, _, _ := statusWriter.PatchArgsForCall(0)
+				qsec := object.(*qsv1a1.QuarksSecret)
+				Expect(qsec.Status.Rollback.Version).To(Equal(int64(1)))
+				Expect(qsec.RollbackRequested()).To(BeFalse())
+				condition := qsec.Status.GetCondition(qsv1a1.ConditionRollback)
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal(qsv1a1.RollbackReasonVersionNotFound))
+				Expect(qsec.Status.Version).To(Equal(int64(4)))
+				Expect(qsec.Status.IsGenerated()).To(BeTrue())
+			})
+
+			It("records a rollback of a secret, which is no longer generated, as failed", func() {
+				gets := 0
+				client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+					switch object := object.(type) {
+					case *qsv1a1.QuarksSecret:
+						qSecret.DeepCopyInto(object)
+						return nil
+					case *corev1.Secret:
+						if nn.Name == "generated-secret" {
+							gets++
+							secret.DeepCopyInto(object)
+							if gets > 1 {
+								// replaced by a user after the existing secret check
+								object.Labels = nil
+							}
+							return nil
+						}
+						for _, s := range history {
+							if s.Name == nn.Name {
+								s.DeepCopyInto(object)
+								return nil
+							}
+						}
+					}
+					return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+				})
+
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(createdSecrets()).ToNot(ContainElement(WithTransform(func(s *corev1.Secret) string {
+					return s.Annotations[qsv1a1.AnnotationVersionReason]
+				}, Equal("rollback"))))
+
+				Expect(statusWriter.PatchCallCount()).To(BeNumerically(">", 0))
+				_, object, _, _ := statusWriter.PatchArgsForCall(0)
+				qsec := object.(*qsv1a1.QuarksSecret)
+				Expect(qsec.RollbackRequested()).To(BeFalse())
+				condition := qsec.Status.GetCondition(qsv1a1.ConditionRollback)
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal(qsv1a1.RollbackReasonNotGenerated))
+			})
+
+			It("doesn't roll back twice", func() {
+				qSecret.Status.Rollback = &qsv1a1.RollbackStatus{Version: 2}
+
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(generator.GeneratePasswordCallCount()).To(Equal(1))
+			})
+
+			It("rolls back to the same version again, if the rollback annotation changed", func() {
+				qSecret.Status.Rollback = &qsv1a1.RollbackStatus{Version: 2, Annotation: "first"}
+				qSecret.Annotations = map[string]string{qsv1a1.AnnotationRollback: "second"}
+
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(generator.GeneratePasswordCallCount()).To(Equal(0))
+
+				created := createdSecrets()
+				Expect(created).To(HaveLen(1))
+				Expect(created[0].Annotations).To(HaveKeyWithValue(qsv1a1.AnnotationVersionReason, "rollback"))
+
+				status := updatedStatus()
+				Expect(status.Rollback.Version).To(Equal(int64(2)))
+				Expect(status.Rollback.Annotation).To(Equal("second"))
+			})
+		})
+	})
+
+	Context("when the quarks secret is deleted", func() {
+		var secrets map[string]*corev1.Secret
+
+		newCopy := func(namespace string, copyOf string) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "copy",
+					Namespace:   namespace,
+					Annotations: map[string]string{qsv1a1.AnnotationCopyOf: copyOf},
+				},
+			}
+		}
+
+		updatedObjects := func() ([]*corev1.Secret, []*qsv1a1.QuarksSecret) {
+			secrets := []*corev1.Secret{}
+			qsecs := []*qsv1a1.QuarksSecret{}
+			for i := 0; i < client.UpdateCallCount(); i++ {
+				_, object, _ := client.UpdateArgsForCall(i)
+				switch object := object.(type) {
+				case *corev1.Secret:
+					secrets = append(secrets, object)
+				case *qsv1a1.QuarksSecret:
+					qsecs = append(qsecs, object)
+				}
+			}
+			return secrets, qsecs
+		}
+
+		BeforeEach(func() {
+			now := metav1.Now()
+			qSecret.UID = "qsec-uid"
+			qSecret.DeletionTimestamp = &now
+			qSecret.Spec.Copies = []qsv1a1.Copy{{Name: "copy", Namespace: "ns1"}}
+			qSecret.Status.Copies = []qsv1a1.CopyStatus{{Name: "copy", Namespace: "ns2"}, {Name: "copy", Namespace: "ns3"}}
+
+			secrets = map[string]*corev1.Secret{
+				"default/generated-secret": {
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "generated-secret",
+						Namespace:       "default",
+						OwnerReferences: []metav1.OwnerReference{{UID: "qsec-uid"}},
+					},
+				},
+				"ns1/copy": newCopy("ns1", "default/foo"),
+				"ns2/copy": newCopy("ns2", "default/foo"),
+				"ns3/copy": newCopy("ns3", "default/other"),
+			}
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					qSecret.DeepCopyInto(object)
+					return nil
+				case *corev1.Secret:
+					if secret, ok := secrets[nn.String()]; ok {
+						secret.DeepCopyInto(object)
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+		})
+
+		It("deletes the copies and removes the finalizer", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(generator.GeneratePasswordCallCount()).To(Equal(0))
+
+			Expect(client.DeleteCallCount()).To(Equal(2))
+			deleted := []string{}
+			for i := 0; i < client.DeleteCallCount(); i++ {
+				_, object, _ := client.DeleteArgsForCall(i)
+				deleted = append(deleted, object.(*corev1.Secret).Namespace)
+			}
+			Expect(deleted).To(ConsistOf("ns1", "ns2"))
+
+			updatedSecrets, qsecs := updatedObjects()
+			Expect(updatedSecrets).To(BeEmpty())
+			Expect(qsecs).To(HaveLen(1))
+			Expect(qsecs[0].Finalizers).To(BeEmpty())
+		})
+
+		It("keeps the generated secret and the copies with the orphan policy", func() {
+			qSecret.Spec.DeletionPolicy = qsv1a1.DeletionPolicyOrphan
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.DeleteCallCount()).To(Equal(0))
+
+			updatedSecrets, qsecs := updatedObjects()
+			Expect(updatedSecrets).To(HaveLen(3))
+			for _, secret := range updatedSecrets {
+				if secret.Name == "generated-secret" {
+					Expect(secret.OwnerReferences).To(BeEmpty())
+				} else {
+					Expect(secret.Annotations).ToNot(HaveKey(qsv1a1.AnnotationCopyOf))
+				}
+			}
+			Expect(qsecs[0].Finalizers).To(BeEmpty())
+		})
+
+		It("keeps the generated secret with the retain policy", func() {
+			qSecret.Spec.DeletionPolicy = qsv1a1.DeletionPolicyRetain
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.DeleteCallCount()).To(Equal(2))
+
+			updatedSecrets, _ := updatedObjects()
+			Expect(updatedSecrets).To(HaveLen(1))
+			Expect(updatedSecrets[0].Name).To(Equal("generated-secret"))
+			Expect(updatedSecrets[0].OwnerReferences).To(BeEmpty())
+		})
+
+		It("deletes the public outputs", func() {
+			qSecret.Spec.Copies = nil
+			qSecret.Status.Copies = nil
+			qSecret.Spec.PublicOutput = &qsv1a1.PublicOutput{Namespaces: []string{"ns1"}}
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *qsv1a1.QuarksSecret:
+					qSecret.DeepCopyInto(object)
+					return nil
+				case *corev1.ConfigMap:
+					if nn.Namespace == "ns1" {
+						object.Name = nn.Name
+						object.Namespace = nn.Namespace
+						object.Labels = map[string]string{qsv1a1.LabelKind: qsv1a1.PublicOutputKind}
+						object.Annotations = map[string]string{qsv1a1.AnnotationPublicOutputOf: "default/foo"}
+						return nil
+					}
+				}
+				return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.DeleteCallCount()).To(Equal(1))
+			_, object, _ := client.DeleteArgsForCall(0)
+			Expect(object.(*corev1.ConfigMap).Namespace).To(Equal("ns1"))
+		})
+	})
+
+	Context("when the finalizer is missing", func() {
+		BeforeEach(func() {
+			qSecret.Finalizers = nil
+			generator.GeneratePasswordReturns("securepassword")
+		})
+
+		It("adds the finalizer before generating the secret", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.UpdateCallCount()).To(Equal(1))
+			_, object, _ := client.UpdateArgsForCall(0)
+			Expect(object.(*qsv1a1.QuarksSecret).Finalizers).To(ConsistOf(qsv1a1.Finalizer))
+			Expect(client.CreateCallCount()).To(Equal(1))
+		})
+	})
 })
@@ -13,23 +13,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	credsgen "code.cloudfoundry.org/quarks-secret/pkg/credsgen/in_memory_generator"
 	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
-	"code.cloudfoundry.org/quarks-utils/pkg/config"
 	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
 )
 
 // AddQuarksSecretSecretMeta creates a new QuarksSecrets controller to watch for the
 // custom resource changes for `SecretLabels` and `SecretAnnotations`.
-func AddQuarksSecretSecretMeta(ctx context.Context, config *config.Config, mgr manager.Manager) error {
+func AddQuarksSecretSecretMeta(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
 	ctx = ctxlog.NewContextWithRecorder(ctx, "quarkssecret-secretmeta-reconciler", mgr.GetEventRecorderFor("quarkssecret-secretmeta-recorder"))
-	log := ctxlog.ExtractLogger(ctx)
-	r := NewQuarksSecretSecretMetaReconciler(ctx, config, mgr, credsgen.NewInMemoryGenerator(log))
+	r := NewQuarksSecretSecretMetaReconciler(ctx, config, mgr, options.generator(ctx))
 
 	// Create a new controller
-	c, err := controller.New("quarkssecret-secretmeta-controller", mgr, controller.Options{
+	c, err := controller.New(QuarksSecretSecretMetaController, mgr, controller.Options{
 		Reconciler:              r,
-		MaxConcurrentReconciles: config.MaxQuarksSecretWorkers,
+		MaxConcurrentReconciles: options.workers(QuarksSecretSecretMetaController),
 	})
 	if err != nil {
 		return errors.Wrap(err, "Adding secret metadata controller to manager failed.")
@@ -56,7 +54,7 @@ func AddQuarksSecretSecretMeta(ctx context.Context, config *config.Config, mgr m
 			return false
 		},
 	}
-	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, nsPred, p)
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, options.quarksSecretPredicates(nsPred, p)...)
 	if err != nil {
 		return errors.Wrapf(err, "Watching quarks secrets failed in quarkssecret-secretmeta controller.")
 	}
@@ -82,6 +82,13 @@ func (r *ReconcileQuarksSecretSecretMeta) Reconcile(request reconcile.Request) (
 		newSecretLabels[qsv1a1.LabelKind] = secret.GetLabels()[qsv1a1.LabelKind]
 	}
 
+	// keep the expiry of previous credentials and the content hash
+	for _, key := range []string{qsv1a1.AnnotationPreviousExpires, qsv1a1.AnnotationContentHash} {
+		if value, ok := secret.GetAnnotations()[key]; ok {
+			newSecretAnnotations[key] = value
+		}
+	}
+
 	if !reflect.DeepEqual(newSecretLabels, secret.Labels) || !reflect.DeepEqual(newSecretAnnotations, secret.Annotations) {
 		secret.SetLabels(newSecretLabels)
 		secret.SetAnnotations(newSecretAnnotations)
@@ -0,0 +1,110 @@
+package quarkssecret
+
+import (
+	"context"
+	"net/http"
+
+	"go.uber.org/zap"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/names"
+	wh "code.cloudfoundry.org/quarks-utils/pkg/webhook"
+)
+
+// NewQuarksSecretValidator creates a validating hook for QuarksSecret and adds it to the Manager
+func NewQuarksSecretValidator(ctx context.Context, options Options) *wh.OperatorWebhook {
+	log := ctxlog.ExtractLogger(ctx)
+	log.Info("Setting up validator for QuarksSecret")
+
+	quarksSecretValidator := NewValidationHandler(log, NewValidator(ctx, options))
+
+	globalScopeType := admissionregistration.ScopeType("*")
+	return &wh.OperatorWebhook{
+		FailurePolicy: admissionregistration.Fail,
+		Rules: []admissionregistration.RuleWithOperations{
+			{
+				Rule: admissionregistration.Rule{
+					APIGroups:   []string{names.GroupName},
+					APIVersions: []string{qsv1a1.SchemeGroupVersion.Version},
+					Resources:   []string{qsv1a1.QuarksSecretResourcePlural},
+					Scope:       &globalScopeType,
+				},
+				Operations: []admissionregistration.OperationType{
+					"CREATE",
+					"UPDATE",
+				},
+			},
+		},
+		Path: "/validate-quarks-cloudfoundry-org-v1alpha1-quarkssecret",
+		Name: "validate-quarkssecret." + names.GroupName,
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				qsv1a1.LabelNamespace: options.Config.MonitoredID,
+			},
+		},
+		Webhook: &admission.Webhook{
+			Handler: quarksSecretValidator,
+		},
+	}
+}
+
+// ValidationHandler represents a validation handler for QuarksSecret
+type ValidationHandler struct {
+	decoder   *admission.Decoder
+	log       *zap.SugaredLogger
+	validator *Validator
+}
+
+// NewValidationHandler returns a new ValidationHandler
+func NewValidationHandler(log *zap.SugaredLogger, validator *Validator) admission.Handler {
+	return &ValidationHandler{log: log, validator: validator}
+}
+
+// InjectDecoder injects the decoder.
+func (v *ValidationHandler) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle validates a QuarksSecret
+func (v *ValidationHandler) Handle(_ context.Context, req admission.Request) admission.Response {
+	qsec := &qsv1a1.QuarksSecret{}
+	err := v.decoder.Decode(req, qsec)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if qsec.Namespace == "" {
+		qsec.Namespace = req.Namespace
+	}
+
+	v.log.Infof("Validating QuarksSecret '%s'", qsec.GetNamespacedName())
+
+	var allErrs field.ErrorList
+	if req.Operation == admissionv1beta1.Update {
+		old := &qsv1a1.QuarksSecret{}
+		err := v.decoder.DecodeRaw(req.OldObject, old)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if old.Namespace == "" {
+			old.Namespace = req.Namespace
+		}
+
+		allErrs = v.validator.ValidateQuarksSecretUpdate(old, qsec)
+	} else {
+		allErrs = v.validator.ValidateQuarksSecret(qsec)
+	}
+
+	if len(allErrs) > 0 {
+		v.log.Infof("Rejecting QuarksSecret '%s': %s", qsec.GetNamespacedName(), allErrs.ToAggregate().Error())
+		return admission.Denied(allErrs.ToAggregate().Error())
+	}
+
+	return admission.Allowed("")
+}
@@ -0,0 +1,309 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/scheme"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/controllers"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ValidationHandler", func() {
+	var (
+		handler admission.Handler
+		qsec    *qsv1a1.QuarksSecret
+		old     *qsv1a1.QuarksSecret
+	)
+
+	newRequest := func(operation admissionv1beta1.Operation, obj, oldObj *qsv1a1.QuarksSecret) admission.Request {
+		raw, err := json.Marshal(obj)
+		Expect(err).ToNot(HaveOccurred())
+
+		req := admission.Request{
+			AdmissionRequest: admissionv1beta1.AdmissionRequest{
+				Operation: operation,
+				Namespace: "default",
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		}
+		if oldObj != nil {
+			rawOld, err := json.Marshal(oldObj)
+			Expect(err).ToNot(HaveOccurred())
+			req.OldObject = runtime.RawExtension{Raw: rawOld}
+		}
+		return req
+	}
+
+	create := func() admission.Response {
+		return handler.Handle(context.Background(), newRequest(admissionv1beta1.Create, qsec, nil))
+	}
+
+	update := func() admission.Response {
+		return handler.Handle(context.Background(), newRequest(admissionv1beta1.Update, qsec, old))
+	}
+
+	BeforeEach(func() {
+		err := controllers.AddToScheme(scheme.Scheme)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, log := helper.NewTestLogger()
+		handler = qscontroller.NewValidationHandler(log, qscontroller.NewValidator(ctxlog.NewParentContext(log), qscontroller.Options{}))
+		decoder, err := admission.NewDecoder(scheme.Scheme)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = admission.InjectDecoderInto(decoder, handler)
+		Expect(err).ToNot(HaveOccurred())
+
+		qsec = &qsv1a1.QuarksSecret{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "quarks.cloudfoundry.org/v1alpha1",
+				Kind:       "QuarksSecret",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: qsv1a1.QuarksSecretSpec{
+				Type:       qsv1a1.Password,
+				SecretName: "generated-secret",
+			},
+		}
+	})
+
+	Context("when creating a QuarksSecret", func() {
+		It("allows valid specs", func() {
+			Expect(create().Allowed).To(BeTrue())
+		})
+
+		It("rejects unknown types", func() {
+			qsec.Spec.Type = "foo"
+
+			resp := create()
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring(`spec.type: Unsupported value: "foo"`))
+		})
+
+		It("rejects negative rotation generations", func() {
+			qsec.Spec.Rotation.Generation = -1
+
+			resp := create()
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.rotation.generation"))
+		})
+
+		It("rejects rollbacks to version zero", func() {
+			qsec.Spec.RollbackTo = pointers.Int64(0)
+
+			resp := create()
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.rollbackTo"))
+		})
+
+		It("rejects copies to the source namespace", func() {
+			qsec.Spec.Copies = []qsv1a1.Copy{{Name: "copy", Namespace: "default"}}
+
+			resp := create()
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.copies[0].namespace"))
+		})
+
+		It("rejects copies, which rename two keys to the same key", func() {
+			qsec.Spec.Copies = []qsv1a1.Copy{{
+				Name:       "copy",
+				Namespace:  "other",
+				RenameKeys: map[string]string{"certificate": "ca.crt", "ca": "ca.crt"},
+			}}
+
+			resp := create()
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.copies[0].renameKeys[certificate]"))
+		})
+
+		It("rejects public outputs, which list a namespace twice", func() {
+			qsec.Spec.PublicOutput = &qsv1a1.PublicOutput{Namespaces: []string{"default", "other", "default"}}
+
+			resp := create()
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.publicOutput.namespaces[2]: Duplicate value"))
+		})
+
+		It("rejects restarts without a selector or auto discovery", func() {
+			qsec.Spec.RestartOnChange = &qsv1a1.RestartOnChange{}
+
+			resp := create()
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.restartOnChange: Required value"))
+		})
+
+		Context("with a certificate request", func() {
+			BeforeEach(func() {
+				qsec.Spec.Type = qsv1a1.Certificate
+				qsec.Spec.Request.CertificateRequest = qsv1a1.CertificateRequest{
+					CommonName: "example.com",
+					CARef:      qsv1a1.SecretReference{Name: "ca", Key: "certificate"},
+					CAKeyRef:   qsv1a1.SecretReference{Name: "ca", Key: "private_key"},
+				}
+			})
+
+			It("allows valid specs", func() {
+				Expect(create().Allowed).To(BeTrue())
+			})
+
+			It("rejects a missing CA key", func() {
+				qsec.Spec.Request.CertificateRequest.CARef.Key = ""
+
+				resp := create()
+				Expect(resp.Allowed).To(BeFalse())
+				Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.request.certificate.CARef.key: Required value"))
+			})
+
+			It("rejects a missing CA for locally signed certificates", func() {
+				qsec.Spec.Request.CertificateRequest.CARef = qsv1a1.SecretReference{}
+				qsec.Spec.Request.CertificateRequest.CAKeyRef = qsv1a1.SecretReference{}
+
+				resp := create()
+				Expect(resp.Allowed).To(BeFalse())
+				Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.request.certificate.CARef.name: Required value"))
+			})
+
+			It("rejects unknown signer types", func() {
+				qsec.Spec.Request.CertificateRequest.SignerType = "foo"
+
+				resp := create()
+				Expect(resp.Allowed).To(BeFalse())
+				Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.request.certificate.signerType: Unsupported value"))
+			})
+
+			It("rejects tls types with the cluster signer", func() {
+				qsec.Spec.Type = qsv1a1.TLS
+				qsec.Spec.Request.CertificateRequest.SignerType = qsv1a1.ClusterSigner
+
+				resp := create()
+				Expect(resp.Allowed).To(BeFalse())
+				Expect(string(resp.Result.Reason)).To(ContainSubstring("tls secrets can't be signed by the cluster"))
+			})
+		})
+
+		Context("with a templated config request", func() {
+			BeforeEach(func() {
+				qsec.Spec.Type = qsv1a1.TemplatedConfig
+				qsec.Spec.Request.TemplatedConfigRequest = qsv1a1.TemplatedConfigRequest{
+					Type: qscontroller.HelmTemplate,
+					Templates: map[string]string{
+						"config": "password: {{ .Values.pass }}",
+					},
+					Values: map[string]qsv1a1.SecretReference{
+						"pass": {Name: "password", Key: "password"},
+					},
+				}
+			})
+
+			It("allows valid specs", func() {
+				Expect(create().Allowed).To(BeTrue())
+			})
+
+			It("rejects unsupported template types", func() {
+				qsec.Spec.Request.TemplatedConfigRequest.Type = "jinja"
+
+				resp := create()
+				Expect(resp.Allowed).To(BeFalse())
+				Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.request.templatedConfig.type: Unsupported value"))
+			})
+
+			It("rejects templates, which don't render", func() {
+				qsec.Spec.Request.TemplatedConfigRequest.Templates["config"] = "password: {{ .Values.pass "
+
+				resp := create()
+				Expect(resp.Allowed).To(BeFalse())
+				Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.request.templatedConfig.templates[config]"))
+			})
+		})
+
+		Context("with a plugin request", func() {
+			BeforeEach(func() {
+				qsec.Spec.Type = "example.com/ldap-password"
+				qsec.Spec.Request.PluginRequest = qsv1a1.PluginRequest{
+					Parameters: map[string]string{"length": "32"},
+					Values: map[string]qsv1a1.SecretReference{
+						"seed": {Name: "seed", Key: "value"},
+					},
+				}
+			})
+
+			It("allows valid specs", func() {
+				Expect(create().Allowed).To(BeTrue())
+			})
+
+			It("rejects values without a key", func() {
+				qsec.Spec.Request.PluginRequest.Values["seed"] = qsv1a1.SecretReference{Name: "seed"}
+
+				resp := create()
+				Expect(resp.Allowed).To(BeFalse())
+				Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.request.plugin.values[seed].key: Required value"))
+			})
+
+			It("rejects types, which aren't qualified by a domain", func() {
+				qsec.Spec.Type = "example.com/"
+
+				resp := create()
+				Expect(resp.Allowed).To(BeFalse())
+				Expect(string(resp.Result.Reason)).To(ContainSubstring(`spec.type: Unsupported value: "example.com/"`))
+			})
+		})
+	})
+
+	Context("when updating a QuarksSecret", func() {
+		BeforeEach(func() {
+			old = qsec.DeepCopy()
+		})
+
+		It("allows changing mutable fields", func() {
+			qsec.Spec.SecretLabels = map[string]string{"foo": "bar"}
+
+			Expect(update().Allowed).To(BeTrue())
+		})
+
+		It("rejects changing the type", func() {
+			qsec.Spec.Type = qsv1a1.RSAKey
+
+			resp := update()
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.type: Forbidden: field is immutable"))
+		})
+
+		It("allows bumping the rotation generation", func() {
+			qsec.Spec.Rotation.Generation = 1
+
+			Expect(update().Allowed).To(BeTrue())
+		})
+
+		It("rejects decreasing the rotation generation", func() {
+			old.Spec.Rotation.Generation = 2
+			qsec.Spec.Rotation.Generation = 1
+
+			resp := update()
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(string(resp.Result.Reason)).To(ContainSubstring("spec.rotation.generation: Invalid value: 1: may not be decreased"))
+		})
+
+		It("doesn't validate an unchanged spec", func() {
+			qsec.Spec.Copies = []qsv1a1.Copy{{Name: "copy", Namespace: "default"}}
+			old = qsec.DeepCopy()
+			qsec.Labels = map[string]string{"foo": "bar"}
+
+			Expect(update().Allowed).To(BeTrue())
+		})
+	})
+})
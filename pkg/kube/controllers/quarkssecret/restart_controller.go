@@ -0,0 +1,95 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// AddRestart creates a new controller, which restarts the workloads of a
+// quarks secret, when its generated secret changes
+func AddRestart(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
+	ctx = ctxlog.NewContextWithRecorder(ctx, "restart-reconciler", mgr.GetEventRecorderFor("quarks-secret-recorder"))
+	r := NewRestartReconciler(ctx, config, mgr)
+
+	// Create a new controller
+	c, err := controller.New(RestartController, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: options.workers(RestartController),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Adding restart controller to manager failed.")
+	}
+
+	nsPred := newNSPredicate(ctx, mgr.GetClient(), config.MonitoredID)
+
+	// Watch for quarks secrets, which enable restarts
+	p := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.(*qsv1a1.QuarksSecret).Spec.RestartOnChange != nil
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*qsv1a1.QuarksSecret)
+			o := e.ObjectOld.(*qsv1a1.QuarksSecret)
+
+			if !reflect.DeepEqual(n.Spec.RestartOnChange, o.Spec.RestartOnChange) {
+				ctxlog.Debugf(ctx, "Restart of QuarksSecret '%s' changed", n.Name)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecret{}}, &handler.EnqueueRequestForObject{}, options.quarksSecretPredicates(nsPred, p)...)
+	if err != nil {
+		return errors.Wrapf(err, "Watching quarks secrets failed in restart controller.")
+	}
+
+	// Watch for changed content of generated secrets
+	generated := func(o *corev1.Secret) bool {
+		return o.GetLabels()[qsv1a1.LabelKind] == qsv1a1.GeneratedSecretKind
+	}
+	p = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return generated(e.Object.(*corev1.Secret))
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*corev1.Secret)
+			o := e.ObjectOld.(*corev1.Secret)
+			if generated(n) && n.GetAnnotations()[qsv1a1.AnnotationContentHash] != o.GetAnnotations()[qsv1a1.AnnotationContentHash] {
+				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+					ctx, e.MetaNew, "corev1.Secret",
+					fmt.Sprintf("Update predicate passed for '%s/%s': content hash changed", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+				)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &qsv1a1.QuarksSecret{},
+		IsController: true,
+	}, nsPred, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching secrets failed in restart controller.")
+	}
+
+	return nil
+}
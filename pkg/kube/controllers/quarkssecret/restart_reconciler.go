@@ -0,0 +1,228 @@
+package quarkssecret
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// NewRestartReconciler returns a new ReconcileRestart
+func NewRestartReconciler(ctx context.Context, config *config.Config, mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileRestart{
+		ctx:    ctx,
+		config: config,
+		client: mgr.GetClient(),
+	}
+}
+
+// ReconcileRestart restarts the workloads of a quarks secret, when the
+// content of its generated secret changes
+type ReconcileRestart struct {
+	ctx    context.Context
+	client client.Client
+	config *config.Config
+}
+
+// workload is a deployment or stateful set, which can be restarted by
+// changing its pod template
+type workload struct {
+	object   runtime.Object
+	meta     metav1.Object
+	template *corev1.PodTemplateSpec
+}
+
+// Reconcile compares the content hash of the generated secret with the hash
+// the workloads were last restarted for and patches a checksum annotation
+// into the pod template of the workloads, if it changed
+func (r *ReconcileRestart) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	qsec := &qsv1a1.QuarksSecret{}
+
+	// Set the ctx to be Background, as the top-level context for incoming requests.
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.CtxTimeOut)
+	defer cancel()
+
+	ctxlog.Infof(ctx, "Reconciling workload restarts of QuarksSecret %s", request.NamespacedName)
+	err := r.client.Get(ctx, request.NamespacedName, qsec)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Info(ctx, "Skip reconcile: quarks secret not found")
+			return reconcile.Result{}, nil
+		}
+		ctxlog.Info(ctx, "Error reading the object")
+		return reconcile.Result{}, errors.Wrap(err, "Error reading quarksSecret")
+	}
+
+	if qsec.GetDeletionTimestamp() != nil || qsec.Spec.Type == qsv1a1.SecretCopy || qsec.Spec.RestartOnChange == nil {
+		return reconcile.Result{}, nil
+	}
+
+	secret := &corev1.Secret{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: qsec.Spec.SecretName, Namespace: qsec.Namespace}, secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Debugf(ctx, "Skip reconcile: secret '%s/%s' not found", qsec.Namespace, qsec.Spec.SecretName)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "could not get secret '%s/%s'", qsec.Namespace, qsec.Spec.SecretName)
+	}
+
+	// the hash is computed when the secret is written, from the data
+	// without the previous values
+	checksum, ok := secret.Annotations[qsv1a1.AnnotationContentHash]
+	if !ok {
+		checksum = contentHash(versionData(secret))
+	}
+	if checksum == qsec.Status.RestartChecksum {
+		return reconcile.Result{}, nil
+	}
+
+	// the workloads already use the content, which was generated before
+	// restarts were enabled
+	if qsec.Status.RestartChecksum != "" {
+		if err := r.restartWorkloads(ctx, qsec, checksum); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	err = patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+		status.RestartChecksum = checksum
+	})
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// restartWorkloads patches the checksum into the pod template of the
+// selected workloads, which don't have it yet
+func (r *ReconcileRestart) restartWorkloads(ctx context.Context, qsec *qsv1a1.QuarksSecret, checksum string) error {
+	workloads, err := r.listWorkloads(ctx, qsec)
+	if err != nil {
+		return err
+	}
+
+	key := qsv1a1.AnnotationRestartChecksumPrefix + qsec.Spec.SecretName
+	for _, w := range workloads {
+		if w.template.Annotations[key] == checksum {
+			continue
+		}
+
+		patch := client.MergeFrom(w.object.DeepCopyObject())
+		if w.template.Annotations == nil {
+			w.template.Annotations = map[string]string{}
+		}
+		w.template.Annotations[key] = checksum
+		if err := r.client.Patch(ctx, w.object, patch); err != nil {
+			return errors.Wrapf(err, "could not restart workload '%s/%s'", w.meta.GetNamespace(), w.meta.GetName())
+		}
+		ctxlog.WithEvent(qsec, "Restart").Infof(ctx, "Restarting workload '%s/%s', secret '%s' changed", w.meta.GetNamespace(), w.meta.GetName(), qsec.Spec.SecretName)
+	}
+
+	return nil
+}
+
+// listWorkloads returns the deployments and stateful sets in the namespace
+// of the quarks secret, which match the selector or use the generated
+// secret with auto discovery
+func (r *ReconcileRestart) listWorkloads(ctx context.Context, qsec *qsv1a1.QuarksSecret) ([]workload, error) {
+	restart := qsec.Spec.RestartOnChange
+
+	var selector labels.Selector
+	if restart.Selector != nil {
+		s, err := metav1.LabelSelectorAsSelector(restart.Selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid restart selector of QuarksSecret '%s'", qsec.GetNamespacedName())
+		}
+		selector = s
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.client.List(ctx, deployments, client.InNamespace(qsec.Namespace)); err != nil {
+		return nil, errors.Wrapf(err, "could not list deployments in namespace '%s'", qsec.Namespace)
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.client.List(ctx, statefulSets, client.InNamespace(qsec.Namespace)); err != nil {
+		return nil, errors.Wrapf(err, "could not list stateful sets in namespace '%s'", qsec.Namespace)
+	}
+
+	candidates := []workload{}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		candidates = append(candidates, workload{object: d, meta: d, template: &d.Spec.Template})
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		candidates = append(candidates, workload{object: s, meta: s, template: &s.Spec.Template})
+	}
+
+	workloads := []workload{}
+	for _, w := range candidates {
+		selected := selector != nil && !selector.Empty() && selector.Matches(labels.Set(w.meta.GetLabels()))
+		if selected || (restart.AutoDiscover && usesSecret(w.template.Spec, qsec.Spec.SecretName)) {
+			workloads = append(workloads, w)
+		}
+	}
+	return workloads, nil
+}
+
+// usesSecret returns true if the pod spec mounts the secret or uses it in
+// environment variables
+func usesSecret(spec corev1.PodSpec, name string) bool {
+	return contains(secretsUsedBy(spec), name)
+}
+
+// secretsUsedBy returns the names of the secrets, which the pod spec mounts
+// or uses in environment variables
+func secretsUsedBy(spec corev1.PodSpec) []string {
+	names := []string{}
+	add := func(name string) {
+		if name != "" && !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			add(volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					add(source.Secret.Name)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.EnvFrom {
+			if env.SecretRef != nil {
+				add(env.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				add(env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	return names
+}
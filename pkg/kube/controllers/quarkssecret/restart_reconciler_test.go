@@ -0,0 +1,225 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ReconcileRestart", func() {
+	var (
+		manager      *cfakes.FakeManager
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		client       *cfakes.FakeClient
+		statusWriter *cfakes.FakeStatusWriter
+		qSecret      *qsv1a1.QuarksSecret
+		secret       *corev1.Secret
+		deployments  []appsv1.Deployment
+		statefulSets []appsv1.StatefulSet
+	)
+
+	const checksumKey = "checksum.quarks.cloudfoundry.org/generated-secret"
+
+	patchedNames := func() []string {
+		names := []string{}
+		for i := 0; i < client.PatchCallCount(); i++ {
+			_, object, _, _ := client.PatchArgsForCall(i)
+			switch object := object.(type) {
+			case *appsv1.Deployment:
+				Expect(object.Spec.Template.Annotations[checksumKey]).To(Equal("new-hash"))
+				names = append(names, object.Name)
+			case *appsv1.StatefulSet:
+				Expect(object.Spec.Template.Annotations[checksumKey]).To(Equal("new-hash"))
+				names = append(names, object.Name)
+			}
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		manager = &cfakes.FakeManager{}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+		qSecret = &qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: qsv1a1.QuarksSecretSpec{
+				Type:       "password",
+				SecretName: "generated-secret",
+				RestartOnChange: &qsv1a1.RestartOnChange{
+					Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "selected"}},
+					AutoDiscover: true,
+				},
+			},
+			Status: qsv1a1.QuarksSecretStatus{
+				RestartChecksum: "old-hash",
+			},
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "generated-secret",
+				Namespace:   "default",
+				Annotations: map[string]string{qsv1a1.AnnotationContentHash: "new-hash"},
+			},
+		}
+		deployments = []appsv1.Deployment{
+			{ObjectMeta: metav1.ObjectMeta{Name: "selected", Namespace: "default", Labels: map[string]string{"app": "selected"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "env", Namespace: "default"},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Env: []corev1.EnvVar{{
+							Name: "PASSWORD",
+							ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "generated-secret"},
+								Key:                  "password",
+							}},
+						}},
+					}},
+				}}},
+			},
+		}
+		statefulSets = []appsv1.StatefulSet{{
+			ObjectMeta: metav1.ObjectMeta{Name: "mount", Namespace: "default"},
+			Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{
+					Name:         "secret",
+					VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "generated-secret"}},
+				}},
+			}}},
+		}}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			switch object := object.(type) {
+			case *qsv1a1.QuarksSecret:
+				qSecret.DeepCopyInto(object)
+				return nil
+			case *corev1.Secret:
+				if nn.Name == secret.Name {
+					secret.DeepCopyInto(object)
+					return nil
+				}
+			}
+			return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+		client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+			switch object := object.(type) {
+			case *appsv1.DeploymentList:
+				object.Items = deployments
+			case *appsv1.StatefulSetList:
+				object.Items = statefulSets
+			}
+			return nil
+		})
+		statusWriter = &cfakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		manager.GetClientReturns(client)
+	})
+
+	JustBeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		ctx := ctxlog.NewParentContext(log)
+		reconciler = qscontroller.NewRestartReconciler(ctx, &cfcfg.Config{CtxTimeOut: 10 * time.Second}, manager)
+	})
+
+	It("restarts the selected and discovered workloads", func() {
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patchedNames()).To(ConsistOf("selected", "env", "mount"))
+
+		Expect(statusWriter.PatchCallCount()).To(Equal(1))
+		_, object, _, _ := statusWriter.PatchArgsForCall(0)
+		Expect(object.(*qsv1a1.QuarksSecret).Status.RestartChecksum).To(Equal("new-hash"))
+	})
+
+	It("only patches the checksum and keeps the status written by the generation", func() {
+		calls := 0
+		qSecret.ResourceVersion = "1"
+		statusWriter.PatchCalls(func(_ context.Context, object runtime.Object, patch crc.Patch, _ ...crc.PatchOption) error {
+			calls++
+			if calls == 1 {
+				// the generation reconciler wrote the status in the meantime
+				qSecret.Status.Generated = pointers.Bool(true)
+				qSecret.Status.Version = 2
+				qSecret.ResourceVersion = "2"
+				return errors.NewConflict(schema.GroupResource{}, "foo", fmt.Errorf("object was modified"))
+			}
+			data, err := patch.Data(object)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("restartChecksum"))
+			Expect(string(data)).To(ContainSubstring(`"resourceVersion":"2"`))
+			Expect(string(data)).ToNot(ContainSubstring("generated"))
+			return nil
+		})
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(statusWriter.PatchCallCount()).To(Equal(2))
+		_, object, _, _ := statusWriter.PatchArgsForCall(1)
+		status := object.(*qsv1a1.QuarksSecret).Status
+		Expect(status.RestartChecksum).To(Equal("new-hash"))
+		Expect(status.IsGenerated()).To(BeTrue())
+		Expect(status.Version).To(Equal(int64(2)))
+	})
+
+	It("only restarts the selected workloads without auto discovery", func() {
+		qSecret.Spec.RestartOnChange.AutoDiscover = false
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patchedNames()).To(ConsistOf("selected"))
+	})
+
+	It("skips workloads, which have the checksum already", func() {
+		deployments[0].Spec.Template.Annotations = map[string]string{checksumKey: "new-hash"}
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(patchedNames()).To(ConsistOf("env", "mount"))
+	})
+
+	It("doesn't restart anything, if the content didn't change", func() {
+		qSecret.Status.RestartChecksum = "new-hash"
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.PatchCallCount()).To(Equal(0))
+		Expect(statusWriter.PatchCallCount()).To(Equal(0))
+	})
+
+	It("only records the checksum, when restarts are enabled", func() {
+		qSecret.Status.RestartChecksum = ""
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.PatchCallCount()).To(Equal(0))
+		_, object, _, _ := statusWriter.PatchArgsForCall(0)
+		Expect(object.(*qsv1a1.QuarksSecret).Status.RestartChecksum).To(Equal("new-hash"))
+	})
+})
@@ -0,0 +1,68 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// AddRotationPolicy creates a new QuarksSecretRotationPolicy controller,
+// which rotates the selected QuarksSecrets on a schedule
+func AddRotationPolicy(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
+	ctx = ctxlog.NewContextWithRecorder(ctx, "rotation-policy-reconciler", mgr.GetEventRecorderFor("quarks-secret-recorder"))
+	r := NewRotationPolicyReconciler(ctx, config, mgr)
+
+	// Create a new controller
+	c, err := controller.New(RotationPolicyController, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: options.workers(RotationPolicyController),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Adding rotation policy controller to manager failed.")
+	}
+
+	nsPred := newNSPredicate(ctx, mgr.GetClient(), config.MonitoredID)
+
+	// Watch for changes to QuarksSecretRotationPolicies, the reconciler
+	// requeues itself for the next schedule
+	p := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			ctxlog.NewPredicateEvent(e.Object).Debug(
+				ctx, e.Meta, "qsv1a1.QuarksSecretRotationPolicy",
+				fmt.Sprintf("Create predicate passed for '%s/%s'", e.Meta.GetNamespace(), e.Meta.GetName()),
+			)
+			return true
+		},
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// status updates don't change the generation
+			if e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() {
+				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+					ctx, e.MetaNew, "qsv1a1.QuarksSecretRotationPolicy",
+					fmt.Sprintf("Update predicate passed for '%s/%s'", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+				)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &qsv1a1.QuarksSecretRotationPolicy{}}, &handler.EnqueueRequestForObject{}, nsPred, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching rotation policies failed in rotation policy controller.")
+	}
+
+	return nil
+}
@@ -0,0 +1,374 @@
+package quarkssecret
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// rotationPolicyPollInterval is how often a running rotation checks for
+// regenerated secrets
+const rotationPolicyPollInterval = 10 * time.Second
+
+// NewRotationPolicyReconciler returns a new ReconcileRotationPolicy
+func NewRotationPolicyReconciler(ctx context.Context, config *config.Config, mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileRotationPolicy{
+		ctx:    ctx,
+		config: config,
+		client: mgr.GetClient(),
+		scheme: mgr.GetScheme(),
+	}
+}
+
+// ReconcileRotationPolicy reconciles a QuarksSecretRotationPolicy object
+type ReconcileRotationPolicy struct {
+	ctx    context.Context
+	client client.Client
+	scheme *runtime.Scheme
+	config *config.Config
+}
+
+// rotationSchedule is the parsed schedule of a rotation policy
+type rotationSchedule struct {
+	location *time.Location
+	cron     cron.Schedule
+	windows  []maintenanceWindow
+}
+
+// maintenanceWindow is a parsed qsv1a1.MaintenanceWindow, start and end are
+// offsets from midnight
+type maintenanceWindow struct {
+	days  map[time.Weekday]bool
+	start time.Duration
+	end   time.Duration
+}
+
+// Reconcile starts a rotation of the selected quarks secrets, once the
+// policies schedule is due. Schedules outside of the maintenance windows are
+// skipped. A running rotation regenerates at most spec.maxConcurrency
+// secrets at a time and pauses while the maintenance windows are closed.
+func (r *ReconcileRotationPolicy) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	policy := &qsv1a1.QuarksSecretRotationPolicy{}
+
+	// Set the ctx to be Background, as the top-level context for incoming requests.
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.CtxTimeOut)
+	defer cancel()
+
+	ctxlog.Infof(ctx, "Reconciling QuarksSecretRotationPolicy '%s'", request.NamespacedName)
+	err := r.client.Get(ctx, request.NamespacedName, policy)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Info(ctx, "Skip reconcile: QuarksSecretRotationPolicy not found")
+			return reconcile.Result{}, nil
+		}
+		ctxlog.Info(ctx, "Error reading the object")
+		return reconcile.Result{}, errors.Wrap(err, "Error reading QuarksSecretRotationPolicy")
+	}
+
+	schedule, err := parseRotationSchedule(policy.Spec)
+	if err != nil {
+		ctxlog.WithEvent(policy, "InvalidRotationPolicy").Errorf(ctx, "Invalid QuarksSecretRotationPolicy '%s': %s", request.NamespacedName, err)
+		return reconcile.Result{}, nil
+	}
+	now := time.Now().In(schedule.location)
+
+	running := rotationRunning(policy)
+	err = r.checkInProgress(ctx, policy)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "Error checking rotations of QuarksSecretRotationPolicy '%s'", request.NamespacedName)
+	}
+
+	result := reconcile.Result{RequeueAfter: rotationPolicyPollInterval}
+	if !running {
+		base := policy.CreationTimestamp.Time
+		if policy.Status.LastScheduleTime != nil {
+			base = policy.Status.LastScheduleTime.Time
+		}
+		next := schedule.cron.Next(base.In(schedule.location))
+
+		if next.After(now) {
+			policy.Status.NextScheduleTime = &metav1.Time{Time: next}
+			result = reconcile.Result{RequeueAfter: next.Sub(now)}
+		} else {
+			// missed schedules collapse into the latest one
+			for n := schedule.cron.Next(next); !n.After(now); n = schedule.cron.Next(n) {
+				next = n
+			}
+
+			if schedule.inMaintenanceWindow(next) && schedule.inMaintenanceWindow(now) {
+				err = r.schedule(ctx, policy, now)
+				if err != nil {
+					return reconcile.Result{}, errors.Wrapf(err, "Error scheduling rotation of QuarksSecretRotationPolicy '%s'", request.NamespacedName)
+				}
+				running = true
+			} else {
+				ctxlog.WithEvent(policy, "RotationSkipped").Infof(ctx, "QuarksSecretRotationPolicy '%s' skipped the rotation scheduled at %s outside of the maintenance windows", request.NamespacedName, next.Format(time.RFC3339))
+				policy.Status.LastScheduleTime = &metav1.Time{Time: now}
+				next = schedule.cron.Next(now)
+				policy.Status.NextScheduleTime = &metav1.Time{Time: next}
+				result = reconcile.Result{RequeueAfter: next.Sub(now)}
+			}
+		}
+	}
+
+	if running {
+		if schedule.inMaintenanceWindow(now) {
+			r.rotatePending(ctx, policy)
+		} else if len(policy.Status.Pending) > 0 {
+			ctxlog.Debugf(ctx, "QuarksSecretRotationPolicy '%s' maintenance window closed, %d secrets are pending", request.NamespacedName, len(policy.Status.Pending))
+			result = reconcile.Result{RequeueAfter: schedule.nextMaintenanceWindow(now).Sub(now)}
+		}
+
+		if !rotationRunning(policy) {
+			policy.Status.LastCompletionTime = &metav1.Time{Time: now}
+			next := schedule.cron.Next(now)
+			policy.Status.NextScheduleTime = &metav1.Time{Time: next}
+			result = reconcile.Result{RequeueAfter: next.Sub(now)}
+			ctxlog.WithEvent(policy, "RotationCompleted").Infof(ctx, "QuarksSecretRotationPolicy '%s' completed the rotation", request.NamespacedName)
+		}
+	}
+
+	err = r.client.Status().Update(ctx, policy)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "Error updating status of QuarksSecretRotationPolicy '%s'", request.NamespacedName)
+	}
+
+	return result, nil
+}
+
+// checkInProgress removes all secrets from the in progress list, which have
+// been regenerated or deleted
+func (r *ReconcileRotationPolicy) checkInProgress(ctx context.Context, policy *qsv1a1.QuarksSecretRotationPolicy) error {
+	inProgress := []string{}
+	for _, name := range policy.Status.InProgress {
+		qsec := &qsv1a1.QuarksSecret{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: name, Namespace: policy.Namespace}, qsec)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if qsec.Status.NotGenerated() {
+			inProgress = append(inProgress, name)
+		}
+	}
+	policy.Status.InProgress = inProgress
+	return nil
+}
+
+// schedule marks all selected, generated quarks secrets as pending
+func (r *ReconcileRotationPolicy) schedule(ctx context.Context, policy *qsv1a1.QuarksSecretRotationPolicy, now time.Time) error {
+	selector := labels.Everything()
+	if policy.Spec.Selector != nil {
+		s, err := metav1.LabelSelectorAsSelector(policy.Spec.Selector)
+		if err != nil {
+			return errors.Wrap(err, "parsing label selector")
+		}
+		selector = s
+	}
+
+	qsecs := &qsv1a1.QuarksSecretList{}
+	err := r.client.List(ctx, qsecs, client.InNamespace(policy.Namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return errors.Wrap(err, "listing quarks secrets")
+	}
+
+	pending := []string{}
+	for _, qsec := range qsecs.Items {
+		if qsec.Spec.Type == qsv1a1.SecretCopy {
+			continue
+		}
+		if len(policy.Spec.Types) > 0 && !contains(policy.Spec.Types, qsec.Spec.Type) {
+			continue
+		}
+		if !qsec.Status.IsGenerated() {
+			continue
+		}
+		pending = append(pending, qsec.Name)
+	}
+
+	policy.Status.LastScheduleTime = &metav1.Time{Time: now}
+	policy.Status.NextScheduleTime = nil
+	policy.Status.Pending = pending
+	ctxlog.WithEvent(policy, "RotationScheduled").Infof(ctx, "QuarksSecretRotationPolicy '%s' scheduled the rotation of %d secrets", policy.GetNamespacedName(), len(pending))
+	return nil
+}
+
+// rotatePending starts the rotation of pending secrets, as long as less
+// than spec.maxConcurrency are in progress
+func (r *ReconcileRotationPolicy) rotatePending(ctx context.Context, policy *qsv1a1.QuarksSecretRotationPolicy) {
+	for len(policy.Status.Pending) > 0 {
+		if policy.Spec.MaxConcurrency > 0 && len(policy.Status.InProgress) >= policy.Spec.MaxConcurrency {
+			return
+		}
+
+		name := policy.Status.Pending[0]
+		qsec := &qsv1a1.QuarksSecret{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: name, Namespace: policy.Namespace}, qsec)
+		if err != nil && !apierrors.IsNotFound(err) {
+			ctxlog.Errorf(ctx, "Error reading QuarksSecret '%s/%s' for rotation: %s", policy.Namespace, name, err)
+			return
+		}
+		if err != nil {
+			policy.Status.Pending = policy.Status.Pending[1:]
+			continue
+		}
+
+		switch rotateQuarksSecret(ctx, r.client, qsec) {
+		case RotationResultRotated:
+			policy.Status.InProgress = append(policy.Status.InProgress, name)
+		case RotationResultFailed:
+			// retry on the next reconcile
+			return
+		}
+		policy.Status.Pending = policy.Status.Pending[1:]
+	}
+}
+
+// rotationRunning returns true if secrets are waiting for or in rotation
+func rotationRunning(policy *qsv1a1.QuarksSecretRotationPolicy) bool {
+	return len(policy.Status.Pending) > 0 || len(policy.Status.InProgress) > 0
+}
+
+// parseRotationSchedule parses the time zone, cron schedule and maintenance
+// windows of the policy
+func parseRotationSchedule(spec qsv1a1.QuarksSecretRotationPolicySpec) (*rotationSchedule, error) {
+	location := time.UTC
+	if spec.TimeZone != "" {
+		l, err := time.LoadLocation(spec.TimeZone)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading time zone '%s'", spec.TimeZone)
+		}
+		location = l
+	}
+
+	schedule, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing schedule '%s'", spec.Schedule)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, errors.Errorf("schedule '%s' is never due", spec.Schedule)
+	}
+
+	windows := make([]maintenanceWindow, 0, len(spec.MaintenanceWindows))
+	for _, w := range spec.MaintenanceWindows {
+		window, err := parseMaintenanceWindow(w)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	return &rotationSchedule{location: location, cron: schedule, windows: windows}, nil
+}
+
+func parseMaintenanceWindow(w qsv1a1.MaintenanceWindow) (maintenanceWindow, error) {
+	window := maintenanceWindow{days: map[time.Weekday]bool{}}
+
+	for _, day := range w.Days {
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(d.String(), day) {
+				window.days[d] = true
+				found = true
+			}
+		}
+		if !found {
+			return window, errors.Errorf("invalid maintenance window day '%s'", day)
+		}
+	}
+
+	for _, t := range []struct {
+		value  string
+		offset *time.Duration
+	}{{w.Start, &window.start}, {w.End, &window.end}} {
+		parsed, err := time.Parse("15:04", t.value)
+		if err != nil {
+			return window, errors.Wrapf(err, "parsing maintenance window time '%s'", t.value)
+		}
+		*t.offset = time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute
+	}
+
+	return window, nil
+}
+
+// opensOn returns true if the window opens on the given day of the week
+func (w maintenanceWindow) opensOn(day time.Weekday) bool {
+	return len(w.days) == 0 || w.days[day]
+}
+
+// bounds returns the start and end of the window opening on the day of t
+func (w maintenanceWindow) bounds(t time.Time) (time.Time, time.Time) {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	start := midnight.Add(w.start)
+	end := midnight.Add(w.end)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// inMaintenanceWindow returns true if there are no maintenance windows or
+// one of them is open at t
+func (s *rotationSchedule) inMaintenanceWindow(t time.Time) bool {
+	if len(s.windows) == 0 {
+		return true
+	}
+
+	for _, w := range s.windows {
+		// windows can start on the previous day and end after midnight
+		for _, day := range []time.Time{t.AddDate(0, 0, -1), t} {
+			if !w.opensOn(day.Weekday()) {
+				continue
+			}
+			start, end := w.bounds(day)
+			if !t.Before(start) && t.Before(end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextMaintenanceWindow returns the time the next maintenance window opens
+// after t
+func (s *rotationSchedule) nextMaintenanceWindow(t time.Time) time.Time {
+	var next time.Time
+	for _, w := range s.windows {
+		for i := 0; i <= 7; i++ {
+			day := t.AddDate(0, 0, i)
+			if !w.opensOn(day.Weekday()) {
+				continue
+			}
+			start, _ := w.bounds(day)
+			if start.After(t) {
+				if next.IsZero() || start.Before(next) {
+					next = start
+				}
+				break
+			}
+		}
+	}
+
+	if next.IsZero() {
+		return t.Add(rotationPolicyPollInterval)
+	}
+	return next
+}
@@ -0,0 +1,252 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/scheme"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/controllers"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ReconcileRotationPolicy", func() {
+	var (
+		manager      *cfakes.FakeManager
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		ctx          context.Context
+		config       *cfcfg.Config
+		client       *cfakes.FakeClient
+		statusWriter *cfakes.FakeStatusWriter
+		policy       *qsv1a1.QuarksSecretRotationPolicy
+		qsecs        []qsv1a1.QuarksSecret
+	)
+
+	newQuarksSecret := func(name string, secretType qsv1a1.SecretType, generated bool) qsv1a1.QuarksSecret {
+		return qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       qsv1a1.QuarksSecretSpec{Type: secretType, SecretName: name},
+			Status:     qsv1a1.QuarksSecretStatus{Generated: pointers.Bool(generated)},
+		}
+	}
+
+	// updatedStatus returns the policy status and the names of the rotated
+	// quarks secrets from the status writer calls
+	updatedStatus := func() (*qsv1a1.QuarksSecretRotationPolicyStatus, []string) {
+		var status *qsv1a1.QuarksSecretRotationPolicyStatus
+		rotated := []string{}
+		for i := 0; i < statusWriter.UpdateCallCount(); i++ {
+			_, object, _ := statusWriter.UpdateArgsForCall(i)
+			switch object := object.(type) {
+			case *qsv1a1.QuarksSecretRotationPolicy:
+				status = &object.Status
+			case *qsv1a1.QuarksSecret:
+				Expect(object.Status.NotGenerated()).To(BeTrue())
+				rotated = append(rotated, object.Name)
+			}
+		}
+		Expect(status).ToNot(BeNil())
+		return status, rotated
+	}
+
+	BeforeEach(func() {
+		err := controllers.AddToScheme(scheme.Scheme)
+		Expect(err).ToNot(HaveOccurred())
+		manager = &cfakes.FakeManager{}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "policy", Namespace: "default"}}
+		config = &cfcfg.Config{CtxTimeOut: 10 * time.Second}
+		_, log := helper.NewTestLogger()
+		ctx = ctxlog.NewParentContext(log)
+
+		policy = &qsv1a1.QuarksSecretRotationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec: qsv1a1.QuarksSecretRotationPolicySpec{
+				Schedule: "* * * * *",
+				Types:    []string{qsv1a1.BasicAuth},
+			},
+			Status: qsv1a1.QuarksSecretRotationPolicyStatus{
+				LastScheduleTime: &metav1.Time{Time: time.Now().Add(-2 * time.Minute)},
+			},
+		}
+		qsecs = []qsv1a1.QuarksSecret{
+			newQuarksSecret("auth-1", qsv1a1.BasicAuth, true),
+			newQuarksSecret("auth-2", qsv1a1.BasicAuth, true),
+			newQuarksSecret("auth-3", qsv1a1.BasicAuth, false),
+			newQuarksSecret("cert", qsv1a1.Certificate, true),
+		}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			switch object := object.(type) {
+			case *qsv1a1.QuarksSecretRotationPolicy:
+				policy.DeepCopyInto(object)
+				return nil
+			case *qsv1a1.QuarksSecret:
+				for _, qsec := range qsecs {
+					if qsec.Name == nn.Name {
+						qsec.DeepCopyInto(object)
+						return nil
+					}
+				}
+			}
+			return apierrors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+		client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+			if list, ok := object.(*qsv1a1.QuarksSecretList); ok {
+				list.Items = qsecs
+			}
+			return nil
+		})
+		statusWriter = &cfakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		manager.GetClientReturns(client)
+	})
+
+	JustBeforeEach(func() {
+		reconciler = qscontroller.NewRotationPolicyReconciler(ctx, config, manager)
+	})
+
+	When("the schedule is not due", func() {
+		BeforeEach(func() {
+			policy.Spec.Schedule = "0 0 1 1 *"
+			policy.Status.LastScheduleTime = &metav1.Time{Time: time.Now()}
+		})
+
+		It("requeues for the next schedule", func() {
+			result, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", time.Hour))
+			Expect(client.ListCallCount()).To(Equal(0))
+
+			status, rotated := updatedStatus()
+			Expect(rotated).To(BeEmpty())
+			Expect(status.NextScheduleTime.Month()).To(Equal(time.January))
+		})
+	})
+
+	When("the schedule is due", func() {
+		It("rotates the generated secrets of the selected types", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			status, rotated := updatedStatus()
+			Expect(rotated).To(ConsistOf("auth-1", "auth-2"))
+			Expect(status.InProgress).To(ConsistOf("auth-1", "auth-2"))
+			Expect(status.Pending).To(BeEmpty())
+		})
+
+		Context("without types", func() {
+			BeforeEach(func() {
+				policy.Spec.Types = nil
+				qsecs = append(qsecs, newQuarksSecret("placeholder", qsv1a1.SecretCopy, true))
+			})
+
+			It("rotates all generated secrets, but no placeholders for copies", func() {
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+
+				status, rotated := updatedStatus()
+				Expect(rotated).To(ConsistOf("auth-1", "auth-2", "cert"))
+				Expect(status.InProgress).To(ConsistOf("auth-1", "auth-2", "cert"))
+			})
+		})
+
+		Context("with a maximum concurrency", func() {
+			BeforeEach(func() {
+				policy.Spec.MaxConcurrency = 1
+			})
+
+			It("rotates one secret at a time", func() {
+				result, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+				status, rotated := updatedStatus()
+				Expect(rotated).To(ConsistOf("auth-1"))
+				Expect(status.InProgress).To(ConsistOf("auth-1"))
+				Expect(status.Pending).To(ConsistOf("auth-2"))
+			})
+		})
+
+		Context("outside of the maintenance windows", func() {
+			BeforeEach(func() {
+				now := time.Now().UTC()
+				policy.Spec.MaintenanceWindows = []qsv1a1.MaintenanceWindow{
+					{
+						Start: now.Add(2 * time.Hour).Format("15:04"),
+						End:   now.Add(3 * time.Hour).Format("15:04"),
+					},
+				}
+			})
+
+			It("skips the rotation", func() {
+				_, err := reconciler.Reconcile(request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(client.ListCallCount()).To(Equal(0))
+
+				status, rotated := updatedStatus()
+				Expect(rotated).To(BeEmpty())
+				Expect(status.Pending).To(BeEmpty())
+				Expect(status.NextScheduleTime).ToNot(BeNil())
+			})
+		})
+	})
+
+	When("the rotated secrets have been regenerated", func() {
+		BeforeEach(func() {
+			policy.Status.LastScheduleTime = &metav1.Time{Time: time.Now()}
+			policy.Status.InProgress = []string{"auth-1", "auth-3"}
+		})
+
+		It("keeps waiting for secrets not yet generated", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			status, _ := updatedStatus()
+			Expect(status.InProgress).To(ConsistOf("auth-3"))
+			Expect(status.LastCompletionTime).To(BeNil())
+		})
+
+		It("completes the rotation once all are generated", func() {
+			policy.Status.InProgress = []string{"auth-1", "auth-2"}
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			status, _ := updatedStatus()
+			Expect(status.InProgress).To(BeEmpty())
+			Expect(status.LastCompletionTime).ToNot(BeNil())
+			Expect(status.NextScheduleTime).ToNot(BeNil())
+		})
+	})
+
+	When("the schedule is invalid", func() {
+		BeforeEach(func() {
+			policy.Spec.Schedule = "every sunday"
+		})
+
+		It("doesn't requeue", func() {
+			result, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+			Expect(statusWriter.UpdateCallCount()).To(Equal(0))
+		})
+	})
+})
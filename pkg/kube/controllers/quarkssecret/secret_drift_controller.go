@@ -0,0 +1,80 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// AddSecretDrift creates a new controller, which watches generated secrets
+// and restores or reports them, if they are deleted or changed outside of
+// the operator
+func AddSecretDrift(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
+	ctx = ctxlog.NewContextWithRecorder(ctx, "secret-drift-reconciler", mgr.GetEventRecorderFor("quarks-secret-recorder"))
+	r := NewSecretDriftReconciler(ctx, config, mgr, controllerutil.SetControllerReference)
+
+	// Create a new controller
+	c, err := controller.New(SecretDriftController, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: options.workers(SecretDriftController),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Adding secret drift controller to manager failed.")
+	}
+
+	nsPred := newNSPredicate(ctx, mgr.GetClient(), config.MonitoredID)
+
+	// Watch for deleted or modified generated secrets
+	generated := func(o *corev1.Secret) bool {
+		return o.GetLabels()[qsv1a1.LabelKind] == qsv1a1.GeneratedSecretKind
+	}
+	p := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			if generated(e.Object.(*corev1.Secret)) {
+				ctxlog.NewPredicateEvent(e.Object).Debug(
+					ctx, e.Meta, "corev1.Secret",
+					fmt.Sprintf("Delete predicate passed for '%s/%s'", e.Meta.GetNamespace(), e.Meta.GetName()),
+				)
+				return true
+			}
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*corev1.Secret)
+			expected, ok := n.GetAnnotations()[qsv1a1.AnnotationContentHash]
+			if generated(n) && ok && expected != contentHash(versionData(n)) {
+				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+					ctx, e.MetaNew, "corev1.Secret",
+					fmt.Sprintf("Update predicate passed for '%s/%s': content hash changed", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+				)
+				return true
+			}
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &qsv1a1.QuarksSecret{},
+		IsController: true,
+	}, nsPred, p)
+	if err != nil {
+		return errors.Wrapf(err, "Watching secrets failed in secret drift controller.")
+	}
+
+	return nil
+}
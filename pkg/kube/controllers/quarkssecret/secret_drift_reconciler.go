@@ -0,0 +1,193 @@
+package quarkssecret
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+)
+
+// NewSecretDriftReconciler returns a new ReconcileSecretDrift
+func NewSecretDriftReconciler(ctx context.Context, config *config.Config, mgr manager.Manager, srf setReferenceFunc) reconcile.Reconciler {
+	return &ReconcileSecretDrift{
+		ctx:          ctx,
+		config:       config,
+		client:       mgr.GetClient(),
+		scheme:       mgr.GetScheme(),
+		setReference: srf,
+	}
+}
+
+// ReconcileSecretDrift detects generated secrets, which were deleted or
+// changed outside of the operator, and applies the drift policy
+type ReconcileSecretDrift struct {
+	ctx          context.Context
+	client       client.Client
+	scheme       *runtime.Scheme
+	setReference setReferenceFunc
+	config       *config.Config
+}
+
+// Reconcile compares the generated secret of the quarks secret with the
+// content hash, which was stored when it was generated
+func (r *ReconcileSecretDrift) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	qsec := &qsv1a1.QuarksSecret{}
+
+	// Set the ctx to be Background, as the top-level context for incoming requests.
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.CtxTimeOut)
+	defer cancel()
+
+	ctxlog.Infof(ctx, "Reconciling drift of QuarksSecret %s", request.NamespacedName)
+	err := r.client.Get(ctx, request.NamespacedName, qsec)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Info(ctx, "Skip reconcile: quarks secret not found")
+			return reconcile.Result{}, nil
+		}
+		ctxlog.Info(ctx, "Error reading the object")
+		return reconcile.Result{}, errors.Wrap(err, "Error reading quarksSecret")
+	}
+
+	if qsec.GetDeletionTimestamp() != nil || qsec.Spec.Type == qsv1a1.SecretCopy || !qsec.Status.IsGenerated() {
+		return reconcile.Result{}, nil
+	}
+
+	policy := qsec.Spec.DriftPolicy
+	if policy == "" {
+		policy = qsv1a1.DriftPolicyRestore
+	}
+	if policy == qsv1a1.DriftPolicyIgnore {
+		return reconcile.Result{}, nil
+	}
+
+	reason := ""
+	secret := &corev1.Secret{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: qsec.Spec.SecretName, Namespace: qsec.Namespace}, secret)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, errors.Wrapf(err, "could not get secret '%s/%s'", qsec.Namespace, qsec.Spec.SecretName)
+		}
+		reason = qsv1a1.DriftReasonDeleted
+	} else {
+		expected, ok := secret.Annotations[qsv1a1.AnnotationContentHash]
+		if !ok || expected == contentHash(versionData(secret)) {
+			return reconcile.Result{}, nil
+		}
+		reason = qsv1a1.DriftReasonModified
+	}
+	ctxlog.WithEvent(qsec, "SecretDrift").Infof(ctx, "Secret '%s/%s' was %s outside of the operator", qsec.Namespace, qsec.Spec.SecretName, reason)
+
+	if policy == qsv1a1.DriftPolicyRestore {
+		restored, err := r.restore(ctx, qsec, reason)
+		if err != nil || restored {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if qsec.Status.Drift != nil && qsec.Status.Drift.Reason == reason {
+		return reconcile.Result{}, nil
+	}
+	now := metav1.Now()
+	err = patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+		status.Drift = &qsv1a1.DriftStatus{Reason: reason, DetectedAt: &now}
+	})
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// restore writes the current version from the history into the generated
+// secret. Without history, a deleted secret is regenerated and a modified
+// secret can't be restored. Both outcomes are reported by the drift
+// condition.
+func (r *ReconcileSecretDrift) restore(ctx context.Context, qsec *qsv1a1.QuarksSecret, reason string) (bool, error) {
+	history := &corev1.Secret{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: historySecretName(qsec.Spec.SecretName, qsec.Status.Version), Namespace: qsec.Namespace}, history)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "could not get version %d of secret '%s/%s'", qsec.Status.Version, qsec.Namespace, qsec.Spec.SecretName)
+	}
+
+	if err == nil {
+		err = restoreSecret(ctx, r.client, r.scheme, r.setReference, qsec, history)
+		if err != nil {
+			return false, errors.Wrapf(err, "could not restore version %d of secret '%s/%s'", qsec.Status.Version, qsec.Namespace, qsec.Spec.SecretName)
+		}
+		ctxlog.WithEvent(qsec, "SecretDrift").Infof(ctx, "Restored version %d of secret '%s/%s'", qsec.Status.Version, qsec.Namespace, qsec.Spec.SecretName)
+
+		condition := qsv1a1.QuarksSecretCondition{
+			Type:    qsv1a1.ConditionDrift,
+			Status:  corev1.ConditionTrue,
+			Reason:  qsv1a1.DriftConditionRestored,
+			Message: fmt.Sprintf("the secret was %s and version %d was restored", strings.ToLower(reason), qsec.Status.Version),
+		}
+		err = patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+			status.Drift = nil
+			status.Copied = pointers.Bool(false)
+			status.SetCondition(condition)
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+		}
+		return true, nil
+	}
+
+	if reason == qsv1a1.DriftReasonDeleted {
+		ctxlog.WithEvent(qsec, "SecretDrift").Infof(ctx, "Regenerating secret '%s/%s', version %d is not in the history", qsec.Namespace, qsec.Spec.SecretName, qsec.Status.Version)
+
+		// not a rotation, the generation reconciler only records rotations,
+		// which were requested
+		condition := qsv1a1.QuarksSecretCondition{
+			Type:    qsv1a1.ConditionDrift,
+			Status:  corev1.ConditionTrue,
+			Reason:  qsv1a1.DriftConditionRegenerated,
+			Message: fmt.Sprintf("the secret was deleted and regenerated with new credentials, version %d is not in the history", qsec.Status.Version),
+		}
+		err = patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+			status.Generated = pointers.Bool(false)
+			status.SetCondition(condition)
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+		}
+		return true, nil
+	}
+
+	ctxlog.WithEvent(qsec, "SecretDrift").Infof(ctx, "Can't restore secret '%s/%s', version %d is not in the history", qsec.Namespace, qsec.Spec.SecretName, qsec.Status.Version)
+	return false, nil
+}
+
+// contentHash returns a hash over the keys and values of the secret data
+func contentHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%d:%s:%d:", len(key), key, len(data[key]))
+		h.Write(data[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
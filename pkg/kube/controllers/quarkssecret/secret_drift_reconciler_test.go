@@ -0,0 +1,181 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ReconcileSecretDrift", func() {
+	var (
+		manager      *cfakes.FakeManager
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		client       *cfakes.FakeClient
+		statusWriter *cfakes.FakeStatusWriter
+		qSecret      *qsv1a1.QuarksSecret
+		secret       *corev1.Secret
+		history      *corev1.Secret
+	)
+
+	updatedStatus := func() qsv1a1.QuarksSecretStatus {
+		Expect(statusWriter.PatchCallCount()).To(Equal(1))
+		_, object, _, _ := statusWriter.PatchArgsForCall(0)
+		return object.(*qsv1a1.QuarksSecret).Status
+	}
+
+	BeforeEach(func() {
+		manager = &cfakes.FakeManager{}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+		qSecret = &qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: qsv1a1.QuarksSecretSpec{
+				Type:       "password",
+				SecretName: "generated-secret",
+			},
+			Status: qsv1a1.QuarksSecretStatus{
+				Generated: pointers.Bool(true),
+				Version:   2,
+			},
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "generated-secret",
+				Namespace:   "default",
+				Labels:      map[string]string{qsv1a1.LabelKind: qsv1a1.GeneratedSecretKind},
+				Annotations: map[string]string{qsv1a1.AnnotationContentHash: "stale"},
+			},
+			Data: map[string][]byte{"password": []byte("changed-password")},
+		}
+		history = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "generated-secret-v2",
+				Namespace: "default",
+				Labels:    map[string]string{qsv1a1.LabelKind: qsv1a1.HistorySecretKind},
+			},
+			Data: map[string][]byte{"password": []byte("generated-password")},
+		}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			switch object := object.(type) {
+			case *qsv1a1.QuarksSecret:
+				qSecret.DeepCopyInto(object)
+				return nil
+			case *corev1.Secret:
+				if nn.Name == secret.Name {
+					secret.DeepCopyInto(object)
+					return nil
+				}
+				if history != nil && nn.Name == history.Name {
+					history.DeepCopyInto(object)
+					return nil
+				}
+			}
+			return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+		statusWriter = &cfakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		manager.GetClientReturns(client)
+	})
+
+	JustBeforeEach(func() {
+		_, log := helper.NewTestLogger()
+		ctx := ctxlog.NewParentContext(log)
+		setReference := func(owner, object metav1.Object, scheme *runtime.Scheme) error { return nil }
+		reconciler = qscontroller.NewSecretDriftReconciler(ctx, &cfcfg.Config{CtxTimeOut: 10 * time.Second}, manager, setReference)
+	})
+
+	When("the generated secret was modified", func() {
+		It("restores the current version from the history", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(client.UpdateCallCount()).To(Equal(1))
+			_, object, _ := client.UpdateArgsForCall(0)
+			restored := object.(*corev1.Secret)
+			Expect(restored.Data).To(Equal(map[string][]byte{"password": []byte("generated-password")}))
+			Expect(restored.Annotations[qsv1a1.AnnotationContentHash]).ToNot(Equal("stale"))
+
+			status := updatedStatus()
+			Expect(status.Drift).To(BeNil())
+			Expect(status.Copied).To(Equal(pointers.Bool(false)))
+			Expect(status.GetCondition(qsv1a1.ConditionDrift).Reason).To(Equal(qsv1a1.DriftConditionRestored))
+		})
+
+		It("reports the drift if the version is missing from the history", func() {
+			history = nil
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.UpdateCallCount()).To(Equal(0))
+			Expect(updatedStatus().Drift.Reason).To(Equal(qsv1a1.DriftReasonModified))
+		})
+
+		It("only reports the drift with the report policy", func() {
+			qSecret.Spec.DriftPolicy = qsv1a1.DriftPolicyReport
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.UpdateCallCount()).To(Equal(0))
+
+			status := updatedStatus()
+			Expect(status.Drift.Reason).To(Equal(qsv1a1.DriftReasonModified))
+			Expect(status.Drift.DetectedAt).ToNot(BeNil())
+		})
+
+		It("does nothing with the ignore policy", func() {
+			qSecret.Spec.DriftPolicy = qsv1a1.DriftPolicyIgnore
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.UpdateCallCount()).To(Equal(0))
+			Expect(statusWriter.PatchCallCount()).To(Equal(0))
+		})
+	})
+
+	When("the generated secret was deleted", func() {
+		BeforeEach(func() {
+			secret.Name = "deleted"
+			history = nil
+		})
+
+		It("regenerates it without rotating, if the version is missing from the history", func() {
+			qSecret.Spec.HistoryLimit = 0
+
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.CreateCallCount()).To(Equal(0))
+
+			status := updatedStatus()
+			Expect(status.Generated).To(Equal(pointers.Bool(false)))
+			Expect(status.Rotation).To(BeNil())
+			condition := status.GetCondition(qsv1a1.ConditionDrift)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(qsv1a1.DriftConditionRegenerated))
+		})
+	})
+})
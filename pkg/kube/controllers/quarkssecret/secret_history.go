@@ -0,0 +1,289 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+)
+
+// Reasons for creating a new version of a generated secret
+const (
+	versionReasonCreated  = "created"
+	versionReasonRotation = "rotation"
+	versionReasonUpdate   = "update"
+	versionReasonRollback = "rollback"
+	versionReasonAdoption = "adoption"
+)
+
+// historySecretName returns the name of the secret, which keeps a version
+// of the generated secret
+func historySecretName(secretName string, version int64) string {
+	return fmt.Sprintf("%s-v%d", secretName, version)
+}
+
+// versionReason returns why the generated secret is about to change
+func versionReason(qsec *qsv1a1.QuarksSecret) string {
+	switch {
+	case qsec.Status.Generated == nil:
+		return versionReasonCreated
+	case qsec.RotationDue():
+		return versionReasonRotation
+	default:
+		return versionReasonUpdate
+	}
+}
+
+// versionData returns the data of the generated secret after the mutation,
+// without previous credentials, which are not part of a version
+func versionData(s *corev1.Secret) map[string][]byte {
+	data := map[string][]byte{}
+	for key, value := range s.Data {
+		data[key] = value
+	}
+	for key, value := range s.StringData {
+		data[key] = []byte(value)
+	}
+	for _, key := range previousKeys {
+		delete(data, key)
+	}
+	return data
+}
+
+// recordVersion bumps the version of the generated secret in the status.
+// If the history is enabled, the data is kept in an immutable secret, owned
+// by the quarks secret, and versions exceeding the history limit are removed.
+func (r *ReconcileQuarksSecret) recordVersion(ctx context.Context, qsec *qsv1a1.QuarksSecret, secretType corev1.SecretType, data map[string][]byte, reason string) error {
+	qsec.Status.Version++
+	if qsec.Spec.HistoryLimit <= 0 {
+		return nil
+	}
+
+	history := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      historySecretName(qsec.Spec.SecretName, qsec.Status.Version),
+			Namespace: qsec.Namespace,
+			Labels: map[string]string{
+				qsv1a1.LabelKind: qsv1a1.HistorySecretKind,
+			},
+			Annotations: map[string]string{
+				qsv1a1.AnnotationQSecName:      qsec.Name,
+				qsv1a1.AnnotationVersion:       strconv.FormatInt(qsec.Status.Version, 10),
+				qsv1a1.AnnotationVersionReason: reason,
+			},
+		},
+		Type:      secretType,
+		Data:      data,
+		Immutable: pointers.Bool(true),
+	}
+	if err := r.setReference(qsec, history, r.scheme); err != nil {
+		return errors.Wrapf(err, "error setting owner for secret '%s' to QuarksSecret '%s'", history.GetName(), qsec.GetNamespacedName())
+	}
+
+	err := r.client.Create(ctx, history)
+	if apierrors.IsAlreadyExists(err) {
+		// left over from a reconcile, which failed to update the status
+		existing := history.DeepCopy()
+		if err := r.client.Delete(ctx, existing); err != nil {
+			return errors.Wrapf(err, "could not replace history secret '%s/%s'", history.Namespace, history.Name)
+		}
+		err = r.client.Create(ctx, history)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not create history secret '%s/%s'", history.Namespace, history.Name)
+	}
+	ctxlog.Debugf(ctx, "Recorded version %d of secret '%s/%s': %s", qsec.Status.Version, qsec.Namespace, qsec.Spec.SecretName, reason)
+
+	return r.pruneHistory(ctx, qsec)
+}
+
+// pruneHistory removes the oldest history secrets exceeding the history limit
+func (r *ReconcileQuarksSecret) pruneHistory(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
+	secrets, err := listHistorySecrets(ctx, r.client, qsec)
+	if err != nil {
+		return errors.Wrapf(err, "could not list history secrets of QuarksSecret '%s'", qsec.GetNamespacedName())
+	}
+	if len(secrets) <= qsec.Spec.HistoryLimit {
+		return nil
+	}
+
+	sort.Slice(secrets, func(i, j int) bool {
+		return secretVersion(secrets[i]) > secretVersion(secrets[j])
+	})
+	for i := qsec.Spec.HistoryLimit; i < len(secrets); i++ {
+		secret := secrets[i]
+		err := r.client.Delete(ctx, &secret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "could not delete history secret '%s/%s'", secret.Namespace, secret.Name)
+		}
+		ctxlog.Debugf(ctx, "Removed version %d of secret '%s/%s' from history", secretVersion(secret), qsec.Namespace, qsec.Spec.SecretName)
+	}
+
+	return nil
+}
+
+// rollback restores the version from spec.rollbackTo into the generated
+// secret. The restored data is recorded as a new version and the copies
+// are updated by the copy reconciler. It returns false, if the version
+// can't be restored. The failed rollback is recorded, so it doesn't block
+// the generation.
+func (r *ReconcileQuarksSecret) rollback(ctx context.Context, qsec *qsv1a1.QuarksSecret) (bool, error) {
+	version := *qsec.Spec.RollbackTo
+
+	history := &corev1.Secret{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: historySecretName(qsec.Spec.SecretName, version), Namespace: qsec.Namespace}, history)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.WithEvent(qsec, "RollbackError").Errorf(ctx, "Version %d of secret '%s/%s' is not in the history", version, qsec.Namespace, qsec.Spec.SecretName)
+			return false, r.rollbackFailed(ctx, qsec, qsv1a1.RollbackReasonVersionNotFound, fmt.Sprintf("version %d is not in the history", version))
+		}
+		return false, errors.Wrapf(err, "could not get version %d of secret '%s/%s'", version, qsec.Namespace, qsec.Spec.SecretName)
+	}
+
+	secret := &corev1.Secret{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: qsec.Spec.SecretName, Namespace: qsec.Namespace}, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "could not get secret '%s/%s'", qsec.Namespace, qsec.Spec.SecretName)
+	}
+	if err == nil && secret.Labels[qsv1a1.LabelKind] != qsv1a1.GeneratedSecretKind {
+		ctxlog.WithEvent(qsec, "RollbackError").Errorf(ctx, "Secret '%s/%s' was not generated, skipping rollback", qsec.Namespace, qsec.Spec.SecretName)
+		return false, r.rollbackFailed(ctx, qsec, qsv1a1.RollbackReasonNotGenerated, "the secret was not generated")
+	}
+
+	err = restoreSecret(ctx, r.client, r.scheme, r.setReference, qsec, history)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not restore version %d of secret '%s/%s'", version, qsec.Namespace, qsec.Spec.SecretName)
+	}
+	ctxlog.WithEvent(qsec, "Rollback").Infof(ctx, "Restored version %d of secret '%s/%s'", version, qsec.Namespace, qsec.Spec.SecretName)
+
+	if err := r.recordVersion(ctx, qsec, history.Type, history.Data, versionReasonRollback); err != nil {
+		return false, err
+	}
+
+	now := metav1.Now()
+	recorded := qsec.Status.Version
+	rollback := &qsv1a1.RollbackStatus{Version: version, Annotation: qsec.Annotations[qsv1a1.AnnotationRollback], Time: &now}
+	err = patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+		status.Version = recorded
+		status.Rollback = rollback
+		status.SetCondition(qsv1a1.QuarksSecretCondition{
+			Type:    qsv1a1.ConditionRollback,
+			Status:  corev1.ConditionTrue,
+			Reason:  qsv1a1.RollbackReasonRestored,
+			Message: fmt.Sprintf("version %d was restored as version %d", version, recorded),
+		})
+		status.Drift = nil
+		status.Copied = pointers.Bool(false)
+		status.LastReconcile = &now
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+	}
+
+	return true, nil
+}
+
+// rollbackFailed records the rollback, which can't be done, and the reason
+// in the rollback condition
+func (r *ReconcileQuarksSecret) rollbackFailed(ctx context.Context, qsec *qsv1a1.QuarksSecret, reason string, message string) error {
+	now := metav1.Now()
+	rollback := &qsv1a1.RollbackStatus{Version: *qsec.Spec.RollbackTo, Annotation: qsec.Annotations[qsv1a1.AnnotationRollback], Time: &now}
+	err := patchStatus(ctx, r.client, qsec, func(status *qsv1a1.QuarksSecretStatus) {
+		status.Rollback = rollback
+		status.SetCondition(qsv1a1.QuarksSecretCondition{
+			Type:    qsv1a1.ConditionRollback,
+			Status:  corev1.ConditionFalse,
+			Reason:  reason,
+			Message: message,
+		})
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not update QuarksSecret status '%s'", qsec.GetNamespacedName())
+	}
+	return nil
+}
+
+// restoreSecret writes the data of a history secret into the generated
+// secret, which is created again if it was deleted
+func restoreSecret(ctx context.Context, client crc.Client, scheme *runtime.Scheme, setReference setReferenceFunc, qsec *qsv1a1.QuarksSecret, history *corev1.Secret) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      qsec.Spec.SecretName,
+			Namespace: qsec.Namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, client, secret, func() error {
+		if secret.CreationTimestamp.IsZero() {
+			secret.Type = history.Type
+		}
+
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		for key, value := range qsec.Spec.SecretLabels {
+			secret.Labels[key] = value
+		}
+		secret.Labels[qsv1a1.LabelKind] = qsv1a1.GeneratedSecretKind
+
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		for key, value := range qsec.Spec.SecretAnnotations {
+			secret.Annotations[key] = value
+		}
+		delete(secret.Annotations, qsv1a1.AnnotationPreviousExpires)
+		secret.Annotations[qsv1a1.AnnotationContentHash] = contentHash(history.Data)
+
+		secret.Data = map[string][]byte{}
+		for key, value := range history.Data {
+			secret.Data[key] = value
+		}
+		secret.StringData = nil
+
+		return setReference(qsec, secret, scheme)
+	})
+
+	return err
+}
+
+// listHistorySecrets gets all history secrets of the QuarksSecret
+func listHistorySecrets(ctx context.Context, client crc.Client, qsec *qsv1a1.QuarksSecret) ([]corev1.Secret, error) {
+	allSecrets := &corev1.SecretList{}
+	err := client.List(ctx, allSecrets,
+		crc.InNamespace(qsec.Namespace),
+		crc.MatchingLabels{qsv1a1.LabelKind: qsv1a1.HistorySecretKind},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []corev1.Secret{}
+	for _, s := range allSecrets.Items {
+		if s.Annotations[qsv1a1.AnnotationQSecName] == qsec.Name {
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
+
+// secretVersion returns the version of a history secret
+func secretVersion(s corev1.Secret) int64 {
+	version, _ := strconv.ParseInt(s.Annotations[qsv1a1.AnnotationVersion], 10, 64)
+	return version
+}
@@ -15,19 +15,19 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
-	"code.cloudfoundry.org/quarks-utils/pkg/config"
 	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
 )
 
 // AddSecretRotation resets all QuarksSecret to status' to generated=false
-func AddSecretRotation(ctx context.Context, config *config.Config, mgr manager.Manager) error {
+func AddSecretRotation(ctx context.Context, options Options, mgr manager.Manager) error {
+	config := options.Config
 	ctx = ctxlog.NewContextWithRecorder(ctx, "secret-rotation-reconciler", mgr.GetEventRecorderFor("quarks-secret-recorder"))
 	r := NewSecretRotationReconciler(ctx, config, mgr)
 
 	// Create a new controller
-	c, err := controller.New("secret-rotation-controller", mgr, controller.Options{
+	c, err := controller.New(SecretRotationController, mgr, controller.Options{
 		Reconciler:              r,
-		MaxConcurrentReconciles: config.MaxQuarksSecretWorkers,
+		MaxConcurrentReconciles: options.workers(SecretRotationController),
 	})
 	if err != nil {
 		return errors.Wrap(err, "Adding quarks secret controller to manager failed.")
@@ -52,7 +52,19 @@ func AddSecretRotation(ctx context.Context, config *config.Config, mgr manager.M
 		},
 		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
 		GenericFunc: func(e event.GenericEvent) bool { return false },
-		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			n := e.ObjectNew.(*corev1.ConfigMap)
+			o := e.ObjectOld.(*corev1.ConfigMap)
+			_, found := n.GetLabels()[qsv1a1.LabelSecretRotationTrigger]
+			if found && rotationTriggerChanged(o, n) {
+				ctxlog.NewPredicateEvent(e.ObjectNew).Debug(
+					ctx, e.MetaNew, "corev1.ConfigMap",
+					fmt.Sprintf("Update predicate passed for '%s/%s'", e.MetaNew.GetNamespace(), e.MetaNew.GetName()),
+				)
+				return true
+			}
+			return false
+		},
 	}
 	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForObject{}, nsPred, p)
 	if err != nil {
@@ -61,3 +73,18 @@ func AddSecretRotation(ctx context.Context, config *config.Config, mgr manager.M
 
 	return nil
 }
+
+// rotationTriggerChanged returns true if the label was added or any entry,
+// except for the results written by the operator, changed
+func rotationTriggerChanged(o, n *corev1.ConfigMap) bool {
+	if _, found := o.GetLabels()[qsv1a1.LabelSecretRotationTrigger]; !found {
+		return true
+	}
+
+	for _, key := range []string{qsv1a1.RotateQSecretListName, qsv1a1.RotateQSecretSelectorName, qsv1a1.RotateQSecretTypesName, qsv1a1.RotateQSecretAllName} {
+		if o.Data[key] != n.Data[key] {
+			return true
+		}
+	}
+	return false
+}
@@ -3,13 +3,16 @@ package quarkssecret
 import (
 	"context"
 	"encoding/json"
+	"sort"
 
 	"github.com/pkg/errors"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -20,6 +23,24 @@ import (
 	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
 )
 
+// Results of a secret rotation, written to the rotation config map per
+// quarks secret
+const (
+	// RotationResultRotated means the secret will be regenerated
+	RotationResultRotated = "rotated"
+	// RotationResultNotGenerated means the secret was not rotated, because
+	// it has not been generated yet
+	RotationResultNotGenerated = "skipped-not-generated"
+	// RotationResultCopy means the secret was not rotated, because the
+	// quarks secret is a placeholder for a copy
+	RotationResultCopy = "skipped-copy"
+	// RotationResultNotFound means the listed quarks secret doesn't exist
+	RotationResultNotFound = "not-found"
+	// RotationResultFailed means the status of the quarks secret could not
+	// be updated
+	RotationResultFailed = "failed"
+)
+
 // NewSecretRotationReconciler returns a new ReconcileQuarksSecret
 func NewSecretRotationReconciler(ctx context.Context, config *config.Config, mgr manager.Manager) reconcile.Reconciler {
 	return &ReconcileSecretRotation{
@@ -38,6 +59,16 @@ type ReconcileSecretRotation struct {
 	config *config.Config
 }
 
+// rotationTrigger selects the quarks secrets to rotate. Named secrets are
+// always selected, the selector and types narrow down all other secrets in
+// the namespace.
+type rotationTrigger struct {
+	names    []string
+	selector labels.Selector
+	types    []string
+	all      bool
+}
+
 // Reconcile reads that state of the cluster and trigger secret rotation for
 // all listed deployments.
 func (r *ReconcileSecretRotation) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -58,41 +89,179 @@ func (r *ReconcileSecretRotation) Reconcile(request reconcile.Request) (reconcil
 		return reconcile.Result{}, errors.Wrap(err, "Error reading quarksSecret")
 	}
 
-	data, found := instance.Data[qsv1a1.RotateQSecretListName]
-	if !found {
-		ctxlog.Debugf(ctx, "QuarksSecret rotation config didn't list any names, key %s not found", qsv1a1.RotateQSecretListName)
+	trigger, err := parseRotationTrigger(instance.Data)
+	if err != nil {
+		ctxlog.WithEvent(instance, "InvalidRotationConfig").Errorf(ctx, "Invalid QuarksSecret rotation config '%s': %s", request.NamespacedName, err)
+		return reconcile.Result{}, nil
+	}
+	if trigger == nil {
+		ctxlog.Debugf(ctx, "QuarksSecret rotation config didn't select any secrets, none of the keys %s, %s, %s, %s found",
+			qsv1a1.RotateQSecretListName, qsv1a1.RotateQSecretSelectorName, qsv1a1.RotateQSecretTypesName, qsv1a1.RotateQSecretAllName)
 		return reconcile.Result{}, nil
 	}
 
-	names := []string{}
-	err = json.Unmarshal([]byte(data), &names)
+	qsecs := &qsv1a1.QuarksSecretList{}
+	err = r.client.List(ctx, qsecs, client.InNamespace(instance.Namespace))
 	if err != nil {
-		return reconcile.Result{}, errors.Wrapf(err, "Error un-marshalling list of secrets to rotate from '%s'", request.NamespacedName)
+		return reconcile.Result{}, errors.Wrapf(err, "Error listing QuarksSecrets for rotation config '%s'", request.NamespacedName)
 	}
 
-	for _, name := range names {
-		qsec := &qsv1a1.QuarksSecret{}
-		qsecname := types.NamespacedName{Name: name, Namespace: instance.Namespace}
-		err := r.client.Get(ctx, qsecname, qsec)
-		if err != nil {
-			ctxlog.Errorf(ctx, "Error getting QuarksSecret '%s', skipping secret rotation: %s", qsecname.String(), err)
+	results := map[string]string{}
+	for _, name := range trigger.names {
+		results[name] = RotationResultNotFound
+	}
+	for i := range qsecs.Items {
+		qsec := &qsecs.Items[i]
+		if !trigger.selects(qsec) {
 			continue
 		}
+		results[qsec.Name] = rotateQuarksSecret(ctx, r.client, qsec)
+	}
 
-		// skip manual secrets or the ones that have not yet been generated
-		if qsec.Status.NotGenerated() {
-			ctxlog.Debugf(ctx, "QuarksSecret '%s' cannot be rotated, it was not generated", qsec.GetNamespacedName())
-			continue
-		}
+	err = r.writeResults(ctx, instance, results)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "Error writing results to rotation config '%s'", request.NamespacedName)
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// rotateQuarksSecret resets the generated status of the quarks secret, so
+// it's regenerated by the quarks secret controller
+func rotateQuarksSecret(ctx context.Context, c client.Client, qsec *qsv1a1.QuarksSecret) string {
+	// copies are rotated with the quarks secret they are copied from
+	if qsec.Spec.Type == qsv1a1.SecretCopy {
+		ctxlog.Debugf(ctx, "QuarksSecret '%s' cannot be rotated, it is a placeholder for a copy", qsec.GetNamespacedName())
+		return RotationResultCopy
+	}
 
+	// skip manual secrets or the ones that have not yet been generated
+	if !qsec.Status.IsGenerated() {
+		ctxlog.Debugf(ctx, "QuarksSecret '%s' cannot be rotated, it was not generated", qsec.GetNamespacedName())
+		return RotationResultNotGenerated
+	}
+
+	ctxlog.Debugf(ctx, "QuarksSecret '%s' status.generated will be reset to false to trigger regeneration", qsec.GetNamespacedName())
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		qsec.Status.Generated = pointers.Bool(false)
-		ctxlog.Debugf(ctx, "QuarksSecret '%s' status.generated will be reset to false to trigger regeneration", qsec.GetNamespacedName())
+		if qsec.Status.Rotation == nil {
+			qsec.Status.Rotation = &qsv1a1.RotationStatus{}
+		}
+		qsec.Status.Rotation.Requested = true
+		err := c.Status().Update(ctx, qsec)
+		if apierrors.IsConflict(err) {
+			if err := c.Get(ctx, types.NamespacedName{Name: qsec.Name, Namespace: qsec.Namespace}, qsec); err != nil {
+				return err
+			}
+		}
+		return err
+	})
+	if err != nil {
+		ctxlog.Errorf(ctx, "Error updating QuarksSecret '%s' status, skipping secret rotation: %s", qsec.GetNamespacedName(), err)
+		return RotationResultFailed
+	}
+
+	return RotationResultRotated
+}
 
-		err = r.client.Status().Update(ctx, qsec)
+// writeResults stores the result per quarks secret in the rotation config
+// map and emits a summary event
+func (r *ReconcileSecretRotation) writeResults(ctx context.Context, instance *corev1.ConfigMap, results map[string]string) error {
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result]++
+	}
+	ctxlog.WithEvent(instance, "SecretRotation").Infof(ctx, "QuarksSecret rotation '%s/%s': %d %s, %d %s, %d %s, %d %s, %d %s",
+		instance.Namespace, instance.Name,
+		counts[RotationResultRotated], RotationResultRotated,
+		counts[RotationResultNotGenerated], RotationResultNotGenerated,
+		counts[RotationResultCopy], RotationResultCopy,
+		counts[RotationResultNotFound], RotationResultNotFound,
+		counts[RotationResultFailed], RotationResultFailed,
+	)
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		return errors.Wrap(err, "marshalling rotation results")
+	}
+
+	if instance.Data == nil {
+		instance.Data = map[string]string{}
+	}
+	instance.Data[qsv1a1.RotateQSecretResultsName] = string(data)
+
+	return r.client.Update(ctx, instance)
+}
+
+// parseRotationTrigger reads the rotation config map entries, it returns
+// nil if the config map doesn't select any secrets
+func parseRotationTrigger(data map[string]string) (*rotationTrigger, error) {
+	trigger := &rotationTrigger{}
+	found := false
+
+	if list, ok := data[qsv1a1.RotateQSecretListName]; ok {
+		found = true
+		err := json.Unmarshal([]byte(list), &trigger.names)
 		if err != nil {
-			return reconcile.Result{}, errors.Wrap(err, "Error updating QuarksSecret status")
+			return nil, errors.Wrapf(err, "un-marshalling list of secrets to rotate from '%s'", qsv1a1.RotateQSecretListName)
 		}
+		sort.Strings(trigger.names)
 	}
 
-	return reconcile.Result{}, nil
+	if selector, ok := data[qsv1a1.RotateQSecretSelectorName]; ok {
+		found = true
+		s, err := labels.Parse(selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing label selector from '%s'", qsv1a1.RotateQSecretSelectorName)
+		}
+		trigger.selector = s
+	}
+
+	if list, ok := data[qsv1a1.RotateQSecretTypesName]; ok {
+		found = true
+		err := json.Unmarshal([]byte(list), &trigger.types)
+		if err != nil {
+			return nil, errors.Wrapf(err, "un-marshalling list of secret types to rotate from '%s'", qsv1a1.RotateQSecretTypesName)
+		}
+	}
+
+	if all, ok := data[qsv1a1.RotateQSecretAllName]; ok && all == "true" {
+		found = true
+		trigger.all = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return trigger, nil
+}
+
+// selects returns true if the quarks secret is listed by name, or matches
+// the selector and types, or all secrets are to be rotated
+func (t *rotationTrigger) selects(qsec *qsv1a1.QuarksSecret) bool {
+	for _, name := range t.names {
+		if name == qsec.Name {
+			return true
+		}
+	}
+
+	// placeholders for copies are only selected by name
+	if qsec.Spec.Type == qsv1a1.SecretCopy {
+		return false
+	}
+
+	if t.all {
+		return true
+	}
+
+	if t.selector == nil && len(t.types) == 0 {
+		return false
+	}
+	if t.selector != nil && !t.selector.Matches(labels.Set(qsec.Labels)) {
+		return false
+	}
+	if len(t.types) > 0 && !contains(t.types, qsec.Spec.Type) {
+		return false
+	}
+	return true
 }
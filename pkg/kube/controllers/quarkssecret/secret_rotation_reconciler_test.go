@@ -0,0 +1,207 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/client/clientset/versioned/scheme"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/controllers"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	"code.cloudfoundry.org/quarks-utils/pkg/pointers"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+var _ = Describe("ReconcileSecretRotation", func() {
+	var (
+		manager      *cfakes.FakeManager
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		ctx          context.Context
+		config       *cfcfg.Config
+		client       *cfakes.FakeClient
+		statusWriter *cfakes.FakeStatusWriter
+		configMap    *corev1.ConfigMap
+		qsecs        []qsv1a1.QuarksSecret
+	)
+
+	newQuarksSecret := func(name string, secretType qsv1a1.SecretType, labels map[string]string, generated *bool) qsv1a1.QuarksSecret {
+		return qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Spec:       qsv1a1.QuarksSecretSpec{Type: secretType, SecretName: name},
+			Status:     qsv1a1.QuarksSecretStatus{Generated: generated},
+		}
+	}
+
+	rotatedNames := func() []string {
+		names := []string{}
+		for i := 0; i < statusWriter.UpdateCallCount(); i++ {
+			_, object, _ := statusWriter.UpdateArgsForCall(i)
+			qsec := object.(*qsv1a1.QuarksSecret)
+			Expect(qsec.Status.NotGenerated()).To(BeTrue())
+			names = append(names, qsec.Name)
+		}
+		return names
+	}
+
+	results := func() map[string]string {
+		Expect(client.UpdateCallCount()).To(Equal(1))
+		_, object, _ := client.UpdateArgsForCall(0)
+		cm := object.(*corev1.ConfigMap)
+		r := map[string]string{}
+		Expect(json.Unmarshal([]byte(cm.Data[qsv1a1.RotateQSecretResultsName]), &r)).To(Succeed())
+		return r
+	}
+
+	BeforeEach(func() {
+		err := controllers.AddToScheme(scheme.Scheme)
+		Expect(err).ToNot(HaveOccurred())
+		manager = &cfakes.FakeManager{}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "rotate", Namespace: "default"}}
+		config = &cfcfg.Config{CtxTimeOut: 10 * time.Second}
+		_, log := helper.NewTestLogger()
+		ctx = ctxlog.NewParentContext(log)
+
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rotate",
+				Namespace: "default",
+				Labels:    map[string]string{qsv1a1.LabelSecretRotationTrigger: "true"},
+			},
+			Data: map[string]string{},
+		}
+		qsecs = []qsv1a1.QuarksSecret{
+			newQuarksSecret("password", qsv1a1.Password, map[string]string{"app": "one"}, pointers.Bool(true)),
+			newQuarksSecret("cert", qsv1a1.Certificate, map[string]string{"app": "one"}, pointers.Bool(true)),
+			newQuarksSecret("tls", qsv1a1.TLS, map[string]string{"app": "two"}, pointers.Bool(true)),
+			newQuarksSecret("pending", qsv1a1.Certificate, map[string]string{"app": "one"}, pointers.Bool(false)),
+			newQuarksSecret("new", qsv1a1.Certificate, map[string]string{"app": "one"}, nil),
+			newQuarksSecret("placeholder", qsv1a1.SecretCopy, map[string]string{"app": "one"}, pointers.Bool(false)),
+		}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			if cm, ok := object.(*corev1.ConfigMap); ok {
+				configMap.DeepCopyInto(cm)
+			}
+			return nil
+		})
+		client.ListCalls(func(context context.Context, object runtime.Object, _ ...crc.ListOption) error {
+			if list, ok := object.(*qsv1a1.QuarksSecretList); ok {
+				list.Items = qsecs
+			}
+			return nil
+		})
+		statusWriter = &cfakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		manager.GetClientReturns(client)
+	})
+
+	JustBeforeEach(func() {
+		reconciler = qscontroller.NewSecretRotationReconciler(ctx, config, manager)
+	})
+
+	When("the config map lists secrets by name", func() {
+		BeforeEach(func() {
+			configMap.Data[qsv1a1.RotateQSecretListName] = `["password", "pending", "new", "placeholder", "missing"]`
+		})
+
+		It("rotates the generated secrets and writes the results", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotatedNames()).To(ConsistOf("password"))
+			Expect(results()).To(Equal(map[string]string{
+				"password":    qscontroller.RotationResultRotated,
+				"pending":     qscontroller.RotationResultNotGenerated,
+				"new":         qscontroller.RotationResultNotGenerated,
+				"placeholder": qscontroller.RotationResultCopy,
+				"missing":     qscontroller.RotationResultNotFound,
+			}))
+		})
+	})
+
+	When("the config map selects secrets by label and type", func() {
+		BeforeEach(func() {
+			configMap.Data[qsv1a1.RotateQSecretSelectorName] = "app=one"
+			configMap.Data[qsv1a1.RotateQSecretTypesName] = `["certificate"]`
+		})
+
+		It("rotates the secrets matching both", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotatedNames()).To(ConsistOf("cert"))
+			Expect(results()).To(Equal(map[string]string{
+				"cert":    qscontroller.RotationResultRotated,
+				"pending": qscontroller.RotationResultNotGenerated,
+				"new":     qscontroller.RotationResultNotGenerated,
+			}))
+		})
+	})
+
+	When("the config map selects secrets by type", func() {
+		BeforeEach(func() {
+			configMap.Data[qsv1a1.RotateQSecretTypesName] = `["certificate", "tls"]`
+		})
+
+		It("rotates all secrets of these types", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotatedNames()).To(ConsistOf("cert", "tls"))
+		})
+	})
+
+	When("the config map selects all secrets", func() {
+		BeforeEach(func() {
+			configMap.Data[qsv1a1.RotateQSecretAllName] = "true"
+		})
+
+		It("rotates all generated secrets", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotatedNames()).To(ConsistOf("password", "cert", "tls"))
+			Expect(results()).To(Equal(map[string]string{
+				"password": qscontroller.RotationResultRotated,
+				"cert":     qscontroller.RotationResultRotated,
+				"tls":      qscontroller.RotationResultRotated,
+				"pending":  qscontroller.RotationResultNotGenerated,
+				"new":      qscontroller.RotationResultNotGenerated,
+			}))
+		})
+	})
+
+	When("the config map has an invalid selector", func() {
+		BeforeEach(func() {
+			configMap.Data[qsv1a1.RotateQSecretSelectorName] = "app in (one"
+		})
+
+		It("doesn't rotate any secrets", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statusWriter.UpdateCallCount()).To(Equal(0))
+			Expect(client.UpdateCallCount()).To(Equal(0))
+		})
+	})
+
+	When("the config map doesn't select any secrets", func() {
+		It("doesn't list quarks secrets", func() {
+			_, err := reconciler.Reconcile(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.ListCallCount()).To(Equal(0))
+			Expect(client.UpdateCallCount()).To(Equal(0))
+		})
+	})
+})
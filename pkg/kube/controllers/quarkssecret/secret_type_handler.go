@@ -0,0 +1,322 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+)
+
+// dependencyRequeueAfter is the delay before a quarks secret is reconciled
+// again, if the secrets it depends on don't exist yet
+const dependencyRequeueAfter = 5 * time.Second
+
+// SecretTypeHandler validates and generates the secrets of a quarks secret
+// type
+type SecretTypeHandler interface {
+	// Validate checks the request of the quarks secret, path is the path of
+	// the request in the spec
+	Validate(qsec *qsv1a1.QuarksSecret, path *field.Path) field.ErrorList
+	// Dependencies returns the secrets the generation depends on, the
+	// quarks secret is requeued until all of them exist
+	Dependencies(qsec *qsv1a1.QuarksSecret) []qsv1a1.SecretReference
+	// Generate creates or updates the secret of the quarks secret
+	Generate(ctx context.Context, r *ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error
+	// Outputs returns the keys every generated secret of the quarks secret
+	// has, existing secrets without them are not accepted
+	Outputs(qsec *qsv1a1.QuarksSecret) []string
+}
+
+// secretTypeHandlers are the handlers of a controller instance by secret
+// type. The copy type has no handler, copies are created by the copy
+// controller.
+type secretTypeHandlers map[qsv1a1.SecretType]SecretTypeHandler
+
+// newSecretTypeHandlers returns the handlers of the built-in types and the
+// custom handlers. The built-in handlers can't be replaced. ownCA tells the
+// certificate handler, if the generator of a quarks secret signs with its
+// own CA, it may be nil if the handlers don't validate.
+func newSecretTypeHandlers(custom map[qsv1a1.SecretType]SecretTypeHandler, ownCA func(*qsv1a1.QuarksSecret) bool) secretTypeHandlers {
+	handlers := secretTypeHandlers{}
+	for secretType, handler := range custom {
+		handlers[secretType] = handler
+	}
+	for secretType, handler := range map[qsv1a1.SecretType]SecretTypeHandler{
+		qsv1a1.Password:         passwordHandler{},
+		qsv1a1.RSAKey:           rsaKeyHandler{},
+		qsv1a1.SSHKey:           sshKeyHandler{},
+		qsv1a1.Certificate:      certificateHandler{ownCA: ownCA},
+		qsv1a1.TLS:              certificateHandler{ownCA: ownCA},
+		qsv1a1.BasicAuth:        basicAuthHandler{},
+		qsv1a1.DockerConfigJSON: dockerConfigJSONHandler{},
+		qsv1a1.TemplatedConfig:  templatedConfigHandler{},
+	} {
+		handlers[secretType] = handler
+	}
+	return handlers
+}
+
+// validateSecretTypeHandlers returns an error if a custom handler would
+// replace the handler of a built-in type
+func validateSecretTypeHandlers(custom map[qsv1a1.SecretType]SecretTypeHandler) error {
+	builtin := newSecretTypeHandlers(nil, nil)
+	for secretType := range custom {
+		if secretType == qsv1a1.SecretCopy {
+			return errors.Errorf("can't add a handler for secret type '%s'", secretType)
+		}
+		if _, ok := builtin[secretType]; ok {
+			return errors.Errorf("secret type '%s' already has a handler", secretType)
+		}
+	}
+	return nil
+}
+
+// handlerFor returns the handler of the secret type. Custom types without a
+// handler are generated by exec plugins.
+func (h secretTypeHandlers) handlerFor(secretType qsv1a1.SecretType) (SecretTypeHandler, bool) {
+	if handler, ok := h[secretType]; ok {
+		return handler, true
+	}
+	if qsv1a1.IsPluginType(secretType) {
+		return pluginHandler{}, true
+	}
+	return nil, false
+}
+
+// types returns the sorted names of the supported secret types
+func (h secretTypeHandlers) types() []string {
+	types := []string{qsv1a1.SecretCopy}
+	for secretType := range h {
+		types = append(types, secretType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+type notReadyError struct {
+	message string
+}
+
+func newNotReadyError(message string) *notReadyError {
+	return &notReadyError{message: message}
+}
+
+// Error returns the error message
+func (e *notReadyError) Error() string {
+	return e.message
+}
+
+func isNotReady(err error) bool {
+	_, ok := errors.Cause(err).(*notReadyError)
+	return ok
+}
+
+// generate creates the secret of the quarks secret with the handler of its
+// type, once the secrets it depends on exist
+func (r *ReconcileQuarksSecret) generate(ctx context.Context, handler SecretTypeHandler, qsec *qsv1a1.QuarksSecret) error {
+	for _, ref := range handler.Dependencies(qsec) {
+		if ref.Name == "" {
+			continue
+		}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: qsec.Namespace, Name: ref.Name}, &corev1.Secret{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return newNotReadyError(fmt.Sprintf("secret '%s' not found", ref.Name))
+			}
+			return errors.Wrapf(err, "getting secret '%s'", ref.Name)
+		}
+	}
+
+	return handler.Generate(ctx, r, qsec)
+}
+
+// valueReferences returns the secret references of named values, sorted by
+// name
+func valueReferences(values map[string]qsv1a1.SecretReference) []qsv1a1.SecretReference {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	refs := make([]qsv1a1.SecretReference, 0, len(values))
+	for _, name := range names {
+		refs = append(refs, values[name])
+	}
+	return refs
+}
+
+type passwordHandler struct{}
+
+func (passwordHandler) Validate(_ *qsv1a1.QuarksSecret, _ *field.Path) field.ErrorList {
+	return field.ErrorList{}
+}
+
+func (passwordHandler) Dependencies(_ *qsv1a1.QuarksSecret) []qsv1a1.SecretReference {
+	return nil
+}
+
+func (passwordHandler) Generate(ctx context.Context, r *ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error {
+	return r.createPasswordSecret(ctx, qsec)
+}
+
+func (passwordHandler) Outputs(_ *qsv1a1.QuarksSecret) []string {
+	return []string{"password"}
+}
+
+type rsaKeyHandler struct{}
+
+func (rsaKeyHandler) Validate(_ *qsv1a1.QuarksSecret, _ *field.Path) field.ErrorList {
+	return field.ErrorList{}
+}
+
+func (rsaKeyHandler) Dependencies(_ *qsv1a1.QuarksSecret) []qsv1a1.SecretReference {
+	return nil
+}
+
+func (rsaKeyHandler) Generate(ctx context.Context, r *ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error {
+	return r.createRSASecret(ctx, qsec)
+}
+
+func (rsaKeyHandler) Outputs(_ *qsv1a1.QuarksSecret) []string {
+	return []string{"private_key", "public_key"}
+}
+
+type sshKeyHandler struct{}
+
+func (sshKeyHandler) Validate(_ *qsv1a1.QuarksSecret, _ *field.Path) field.ErrorList {
+	return field.ErrorList{}
+}
+
+func (sshKeyHandler) Dependencies(_ *qsv1a1.QuarksSecret) []qsv1a1.SecretReference {
+	return nil
+}
+
+func (sshKeyHandler) Generate(ctx context.Context, r *ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error {
+	return r.createSSHSecret(ctx, qsec)
+}
+
+func (sshKeyHandler) Outputs(_ *qsv1a1.QuarksSecret) []string {
+	return []string{"private_key", "public_key", "public_key_fingerprint"}
+}
+
+// certificateHandler handles the certificate and the tls type
+type certificateHandler struct {
+	ownCA func(*qsv1a1.QuarksSecret) bool
+}
+
+// Validate checks the request, certificates of generators, which sign with
+// their own CA, don't need a CA reference
+func (h certificateHandler) Validate(qsec *qsv1a1.QuarksSecret, path *field.Path) field.ErrorList {
+	ownCA := h.ownCA != nil && h.ownCA(qsec)
+	return validateCertificateRequest(qsec.Spec.Type, qsec.Spec.Request.CertificateRequest, ownCA, path.Child("certificate"))
+}
+
+// Dependencies returns the CA of locally signed certificates
+func (certificateHandler) Dependencies(qsec *qsv1a1.QuarksSecret) []qsv1a1.SecretReference {
+	request := qsec.Spec.Request.CertificateRequest
+	if request.SignerType == qsv1a1.ClusterSigner || request.CARef.Name == "" {
+		return nil
+	}
+	return []qsv1a1.SecretReference{request.CARef, request.CAKeyRef}
+}
+
+func (certificateHandler) Generate(ctx context.Context, r *ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error {
+	return r.createCertificateSecret(ctx, qsec)
+}
+
+func (certificateHandler) Outputs(qsec *qsv1a1.QuarksSecret) []string {
+	if qsec.Spec.Type == qsv1a1.TLS {
+		return []string{"tls.crt", "tls.key"}
+	}
+	return []string{"certificate", "private_key"}
+}
+
+type basicAuthHandler struct{}
+
+func (basicAuthHandler) Validate(_ *qsv1a1.QuarksSecret, _ *field.Path) field.ErrorList {
+	return field.ErrorList{}
+}
+
+func (basicAuthHandler) Dependencies(_ *qsv1a1.QuarksSecret) []qsv1a1.SecretReference {
+	return nil
+}
+
+func (basicAuthHandler) Generate(ctx context.Context, r *ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error {
+	return r.createBasicAuthSecret(ctx, qsec)
+}
+
+func (basicAuthHandler) Outputs(_ *qsv1a1.QuarksSecret) []string {
+	return []string{"username", "password"}
+}
+
+type dockerConfigJSONHandler struct{}
+
+func (dockerConfigJSONHandler) Validate(qsec *qsv1a1.QuarksSecret, path *field.Path) field.ErrorList {
+	return validateImageCredentialsRequest(qsec.Spec.Request.ImageCredentialsRequest, path.Child("imageCredentials"))
+}
+
+func (dockerConfigJSONHandler) Dependencies(qsec *qsv1a1.QuarksSecret) []qsv1a1.SecretReference {
+	request := qsec.Spec.Request.ImageCredentialsRequest
+	return []qsv1a1.SecretReference{request.Username, request.Password}
+}
+
+func (dockerConfigJSONHandler) Generate(ctx context.Context, r *ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error {
+	return r.createDockerConfigJSON(ctx, qsec)
+}
+
+func (dockerConfigJSONHandler) Outputs(_ *qsv1a1.QuarksSecret) []string {
+	return []string{corev1.DockerConfigJsonKey}
+}
+
+type templatedConfigHandler struct{}
+
+func (templatedConfigHandler) Validate(qsec *qsv1a1.QuarksSecret, path *field.Path) field.ErrorList {
+	return validateTemplatedConfigRequest(qsec.Spec.Request.TemplatedConfigRequest, path.Child("templatedConfig"))
+}
+
+func (templatedConfigHandler) Dependencies(qsec *qsv1a1.QuarksSecret) []qsv1a1.SecretReference {
+	return valueReferences(qsec.Spec.Request.TemplatedConfigRequest.Values)
+}
+
+func (templatedConfigHandler) Generate(ctx context.Context, r *ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error {
+	return r.createTemplatedConfigSecret(ctx, qsec)
+}
+
+// Outputs returns the keys of the templates
+func (templatedConfigHandler) Outputs(qsec *qsv1a1.QuarksSecret) []string {
+	keys := []string{}
+	for key := range qsec.Spec.Request.TemplatedConfigRequest.Templates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pluginHandler handles custom types with exec plugins
+type pluginHandler struct{}
+
+func (pluginHandler) Validate(qsec *qsv1a1.QuarksSecret, path *field.Path) field.ErrorList {
+	return validatePluginRequest(qsec.Spec.Request.PluginRequest, path.Child("plugin"))
+}
+
+func (pluginHandler) Dependencies(qsec *qsv1a1.QuarksSecret) []qsv1a1.SecretReference {
+	return valueReferences(qsec.Spec.Request.PluginRequest.Values)
+}
+
+func (pluginHandler) Generate(ctx context.Context, r *ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error {
+	return r.createPluginSecret(ctx, qsec)
+}
+
+// Outputs returns no keys, the plugin decides on the keys
+func (pluginHandler) Outputs(_ *qsv1a1.QuarksSecret) []string {
+	return nil
+}
@@ -0,0 +1,187 @@
+package quarkssecret_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	generatorfakes "code.cloudfoundry.org/quarks-secret/pkg/credsgen/fakes"
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	cfakes "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/fakes"
+	qscontroller "code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	cfcfg "code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	helper "code.cloudfoundry.org/quarks-utils/testing/testhelper"
+)
+
+const customType = "example.com/custom"
+
+// customHandler records the calls for the custom type
+type customHandler struct {
+	dependencies []qsv1a1.SecretReference
+	err          error
+	generated    []string
+}
+
+func (h *customHandler) Validate(qsec *qsv1a1.QuarksSecret, path *field.Path) field.ErrorList {
+	if qsec.Spec.Request.PluginRequest.Parameters["length"] == "" {
+		return field.ErrorList{field.Required(path.Child("plugin", "parameters").Key("length"), "the length is required")}
+	}
+	return field.ErrorList{}
+}
+
+func (h *customHandler) Dependencies(_ *qsv1a1.QuarksSecret) []qsv1a1.SecretReference {
+	return h.dependencies
+}
+
+func (h *customHandler) Generate(_ context.Context, _ *qscontroller.ReconcileQuarksSecret, qsec *qsv1a1.QuarksSecret) error {
+	h.generated = append(h.generated, qsec.Name)
+	return h.err
+}
+
+func (h *customHandler) Outputs(_ *qsv1a1.QuarksSecret) []string {
+	return []string{"custom"}
+}
+
+var _ = Describe("SecretTypeHandler", func() {
+	var (
+		manager      *cfakes.FakeManager
+		client       *cfakes.FakeClient
+		statusWriter *cfakes.FakeStatusWriter
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		qSecret      *qsv1a1.QuarksSecret
+		custom       *customHandler
+		handlers     map[qsv1a1.SecretType]qscontroller.SecretTypeHandler
+	)
+
+	BeforeEach(func() {
+		custom = &customHandler{}
+		handlers = map[qsv1a1.SecretType]qscontroller.SecretTypeHandler{customType: custom}
+
+		qSecret = &qsv1a1.QuarksSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "foo",
+				Namespace:  "default",
+				Finalizers: []string{qsv1a1.Finalizer},
+			},
+			Spec: qsv1a1.QuarksSecretSpec{
+				Type:       customType,
+				SecretName: "generated-secret",
+				Request: qsv1a1.Request{
+					PluginRequest: qsv1a1.PluginRequest{
+						Parameters: map[string]string{"length": "32"},
+					},
+				},
+			},
+		}
+
+		client = &cfakes.FakeClient{}
+		client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+			switch object := object.(type) {
+			case *qsv1a1.QuarksSecret:
+				qSecret.DeepCopyInto(object)
+			case *corev1.Secret:
+				if nn.Name == "seed" {
+					return nil
+				}
+				return apierrors.NewNotFound(schema.GroupResource{}, "not found")
+			}
+			return nil
+		})
+		statusWriter = &cfakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+		manager = &cfakes.FakeManager{}
+		manager.GetClientReturns(client)
+
+		_, log := helper.NewTestLogger()
+		reconciler = qscontroller.NewQuarksSecretReconciler(
+			ctxlog.NewParentContext(log),
+			qscontroller.Options{
+				Config:             &cfcfg.Config{CtxTimeOut: 10 * time.Second},
+				Generator:          &generatorfakes.FakeGenerator{},
+				SecretTypeHandlers: handlers,
+			},
+			manager,
+			func(owner, object metav1.Object, scheme *runtime.Scheme) error { return nil },
+			nil,
+		)
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "default"}}
+	})
+
+	It("generates custom types with the registered handler", func() {
+		result, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(reconcile.Result{}))
+		Expect(custom.generated).To(Equal([]string{"foo"}))
+		Expect(statusWriter.PatchCallCount()).To(Equal(1))
+	})
+
+	It("generates once the dependencies exist", func() {
+		custom.dependencies = []qsv1a1.SecretReference{{Name: "seed", Key: "value"}}
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(custom.generated).To(HaveLen(1))
+	})
+
+	It("requeues until the dependencies exist", func() {
+		custom.dependencies = []qsv1a1.SecretReference{{Name: "seed", Key: "value"}, {Name: "missing", Key: "value"}}
+
+		result, err := reconciler.Reconcile(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(reconcile.Result{RequeueAfter: 5 * time.Second}))
+		Expect(custom.generated).To(BeEmpty())
+		Expect(statusWriter.PatchCallCount()).To(Equal(0))
+	})
+
+	It("returns the generation error", func() {
+		custom.err = errors.New("ldap is down")
+
+		_, err := reconciler.Reconcile(request)
+		Expect(err).To(MatchError("generating example.com/custom secret: ldap is down"))
+		Expect(statusWriter.PatchCallCount()).To(Equal(0))
+	})
+
+	It("validates the request with the registered handler", func() {
+		qSecret.Spec.Request.PluginRequest.Parameters = nil
+
+		validator := qscontroller.NewValidator(context.Background(), qscontroller.Options{SecretTypeHandlers: handlers})
+		errs := validator.ValidateQuarksSecret(qSecret)
+		Expect(errs.ToAggregate()).To(MatchError(ContainSubstring("spec.request.plugin.parameters[length]: Required value")))
+	})
+
+	It("doesn't use the handlers of other instances", func() {
+		qSecret.Spec.Request.PluginRequest.Parameters = nil
+
+		// without the handler, the custom type is generated by a plugin
+		errs := qscontroller.NewValidator(context.Background(), qscontroller.Options{}).ValidateQuarksSecret(qSecret)
+		Expect(errs).To(BeEmpty())
+	})
+
+	It("doesn't replace the handlers of built-in types", func() {
+		options := qscontroller.Options{
+			Config:             &cfcfg.Config{},
+			SecretTypeHandlers: map[qsv1a1.SecretType]qscontroller.SecretTypeHandler{qsv1a1.Password: custom},
+		}
+		err := qscontroller.NewControllers(options).AddToManager(context.Background(), manager)
+		Expect(err).To(MatchError("secret type 'password' already has a handler"))
+
+		options.SecretTypeHandlers = map[qsv1a1.SecretType]qscontroller.SecretTypeHandler{qsv1a1.SecretCopy: custom}
+		err = qscontroller.NewControllers(options).AddToManager(context.Background(), manager)
+		Expect(err).To(MatchError("can't add a handler for secret type 'copy'"))
+	})
+})
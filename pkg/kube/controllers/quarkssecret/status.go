@@ -0,0 +1,30 @@
+package quarkssecret
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+)
+
+// patchStatus applies the change to the status of the quarks secret and
+// patches only the changed fields. Several controllers write the status, so
+// on a conflict the quarks secret is read again and the change is applied to
+// the current status.
+func patchStatus(ctx context.Context, client crc.Client, qsec *qsv1a1.QuarksSecret, change func(status *qsv1a1.QuarksSecretStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		original := qsec.DeepCopy()
+		change(&qsec.Status)
+		err := client.Status().Patch(ctx, qsec, crc.MergeFromWithOptions(original, crc.MergeFromWithOptimisticLock{}))
+		if apierrors.IsConflict(err) {
+			if err := client.Get(ctx, types.NamespacedName{Name: qsec.Name, Namespace: qsec.Namespace}, qsec); err != nil {
+				return err
+			}
+		}
+		return err
+	})
+}
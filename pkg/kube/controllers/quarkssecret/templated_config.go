@@ -16,7 +16,17 @@ import (
 // TemplateEngine renders TemplatedConfigs, which are stored in secret.data
 type TemplateEngine interface {
 	// ExecuteMap renders the templates in templates with variables from values
-	ExecuteMap(templates map[string]string, values map[string]interface{}) map[string]string
+	ExecuteMap(templates map[string]string, values map[string]interface{}) (map[string]string, error)
+}
+
+// newTemplateEngine returns the rendering engine for the given template type
+func newTemplateEngine(templateType string) (TemplateEngine, error) {
+	switch templateType {
+	case HelmTemplate:
+		return template.New(), nil
+	default:
+		return nil, errors.Errorf("unsupported template type '%s' has been specified", templateType)
+	}
 }
 
 // renderSecret uses the specified templating engine to render the templates in Spec.Templates with the data from the referenced secrets.
@@ -24,8 +34,27 @@ func (r *ReconcileQuarksSecret) renderSecret(ctx context.Context, namespace stri
 	empty := map[string]string{}
 
 	// Interpolate the given values, and retrieve the secret contents to fill our config with
+	secretValues, err := r.readValues(ctx, namespace, request.Values)
+	if err != nil {
+		return empty, err
+	}
 	values := map[string]interface{}{}
-	for name, ref := range request.Values {
+	for name, value := range secretValues {
+		values[name] = value
+	}
+
+	// Call the specified rendering engine to draw our data
+	engine, err := newTemplateEngine(request.Type)
+	if err != nil {
+		return empty, err
+	}
+	return engine.ExecuteMap(request.Templates, values)
+}
+
+// readValues returns the data of the referenced secret keys by name
+func (r *ReconcileQuarksSecret) readValues(ctx context.Context, namespace string, refs map[string]qsv1a1.SecretReference) (map[string]string, error) {
+	values := map[string]string{}
+	for name, ref := range refs {
 		secretName := ref.Name
 		field := ref.Key
 
@@ -37,26 +66,17 @@ func (r *ReconcileQuarksSecret) renderSecret(ctx context.Context, namespace stri
 		err := r.client.Get(ctx, namespacedName, existingSecret)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				return empty, newSecNotReadyError("secret not found")
+				return values, newNotReadyError("secret not found")
 			}
-			return empty, errors.Wrap(err, "getting secret")
+			return values, errors.Wrap(err, "getting secret")
 		}
 		data, ok := existingSecret.Data[field]
 		if !ok {
-			return empty, errors.Errorf("Failed to get secret data key: %s", field)
+			return values, errors.Errorf("Failed to get secret data key: %s", field)
 		}
 		values[name] = string(data)
 	}
-
-	// Call the specified rendering engine to draw our data
-	var engine TemplateEngine
-	switch request.Type {
-	case HelmTemplate:
-		engine = template.New()
-	default:
-		return empty, errors.New("unsupported template type has been specified")
-	}
-	return engine.ExecuteMap(request.Templates, values), nil
+	return values, nil
 }
 
 func (r *ReconcileQuarksSecret) createTemplatedConfigSecret(ctx context.Context, qsec *qsv1a1.QuarksSecret) error {
@@ -75,5 +95,5 @@ func (r *ReconcileQuarksSecret) createTemplatedConfigSecret(ctx context.Context,
 		StringData: secretData,
 	}
 
-	return r.createSecrets(ctx, qsec, secret)
+	return r.createSecret(ctx, qsec, secret)
 }
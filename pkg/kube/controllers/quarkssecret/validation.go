@@ -0,0 +1,344 @@
+package quarkssecret
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+)
+
+// templatePlaceholder is used instead of the referenced secret values, when
+// test rendering templates during validation
+const templatePlaceholder = "placeholder"
+
+var supportedSignerTypes = []string{
+	qsv1a1.LocalSigner,
+	qsv1a1.ClusterSigner,
+}
+
+// Validator checks the semantics of QuarksSecret specs, which can't be
+// expressed in the CRD schema
+type Validator struct {
+	handlers secretTypeHandlers
+}
+
+// NewValidator returns a validator, which knows the secret types, the
+// generator and the generator backends of the options
+func NewValidator(ctx context.Context, options Options) *Validator {
+	generators := options.generators(ctx)
+	generator := options.generator(ctx)
+
+	// ownCA mirrors the generator selection of the reconciler
+	ownCA := func(qsec *qsv1a1.QuarksSecret) bool {
+		if qsec.Spec.GeneratorRef == "" || generators == nil {
+			return credsgen.SignsWithOwnCA(generator)
+		}
+		g, err := generators.Get(qsec.Spec.GeneratorRef)
+		return err == nil && credsgen.SignsWithOwnCA(g)
+	}
+
+	return &Validator{handlers: newSecretTypeHandlers(options.SecretTypeHandlers, ownCA)}
+}
+
+// ValidateQuarksSecret checks the semantics of a QuarksSecret spec.
+func (v *Validator) ValidateQuarksSecret(qsec *qsv1a1.QuarksSecret) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if qsec.Spec.Type != qsv1a1.SecretCopy {
+		handler, ok := v.handlers.handlerFor(qsec.Spec.Type)
+		if !ok {
+			allErrs = append(allErrs, field.NotSupported(specPath.Child("type"), qsec.Spec.Type, v.handlers.types()))
+			return allErrs
+		}
+		allErrs = append(allErrs, handler.Validate(qsec, specPath.Child("request"))...)
+	}
+
+	if qsec.Spec.Rotation.Generation < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("rotation", "generation"), qsec.Spec.Rotation.Generation, "must be greater than or equal to 0"))
+	}
+
+	if qsec.Spec.Rotation.GracePeriod != nil && qsec.Spec.Rotation.GracePeriod.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("rotation", "gracePeriod"), qsec.Spec.Rotation.GracePeriod.Duration.String(), "must not be negative"))
+	}
+
+	if qsec.Spec.HistoryLimit < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("historyLimit"), qsec.Spec.HistoryLimit, "must be greater than or equal to 0"))
+	}
+
+	if qsec.Spec.RollbackTo != nil && *qsec.Spec.RollbackTo < 1 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("rollbackTo"), *qsec.Spec.RollbackTo, "must be greater than 0"))
+	}
+
+	allErrs = append(allErrs, validateCopies(qsec.Namespace, qsec.Spec.Copies, specPath.Child("copies"))...)
+	if selector := qsec.Spec.CopySelector; selector != nil {
+		allErrs = append(allErrs, validateCopyTransform(selector.IncludeKeys, selector.ExcludeKeys, selector.RenameKeys, specPath.Child("copySelector"))...)
+	}
+	if output := qsec.Spec.PublicOutput; output != nil {
+		allErrs = append(allErrs, validatePublicOutput(*output, specPath.Child("publicOutput"))...)
+	}
+	if restart := qsec.Spec.RestartOnChange; restart != nil {
+		allErrs = append(allErrs, validateRestartOnChange(*restart, qsec.Spec.SecretName, specPath.Child("restartOnChange"))...)
+	}
+
+	return allErrs
+}
+
+// ValidateQuarksSecretUpdate checks that no immutable fields are changed
+// and validates the new spec.
+func (v *Validator) ValidateQuarksSecretUpdate(old, new *qsv1a1.QuarksSecret) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	// Changing the type would leave the generated secret in an inconsistent shape
+	if old.Spec.Type != new.Spec.Type {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("type"), "field is immutable"))
+	}
+
+	// CSRs are immutable and the private key secret depends on the signer
+	if old.Spec.Request.CertificateRequest.SignerType != new.Spec.Request.CertificateRequest.SignerType {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("request", "certificate", "signerType"), "field is immutable"))
+	}
+
+	// Rotation generations are bumped, never reset
+	if new.Spec.Rotation.Generation < old.Spec.Rotation.Generation {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("rotation", "generation"), new.Spec.Rotation.Generation, "may not be decreased"))
+	}
+
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	// Only validate the spec if it changed, so metadata updates on existing
+	// resources are not blocked
+	if reflect.DeepEqual(old.Spec, new.Spec) {
+		return allErrs
+	}
+
+	return v.ValidateQuarksSecret(new)
+}
+
+func validateCertificateRequest(secretType string, request qsv1a1.CertificateRequest, ownCA bool, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	signerType := request.SignerType
+	if signerType == "" {
+		signerType = qsv1a1.LocalSigner
+	}
+	if !contains(supportedSignerTypes, signerType) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("signerType"), request.SignerType, supportedSignerTypes))
+	}
+
+	if secretType == qsv1a1.TLS && signerType == qsv1a1.ClusterSigner {
+		allErrs = append(allErrs, field.Invalid(path.Child("signerType"), request.SignerType, "tls secrets can't be signed by the cluster"))
+	}
+
+	if ownCA {
+		// the generator can only issue certificates signed by its CA
+		if signerType == qsv1a1.ClusterSigner {
+			allErrs = append(allErrs, field.Invalid(path.Child("signerType"), request.SignerType, "the generator signs with its own CA and doesn't support the cluster signer"))
+		}
+		if request.IsCA {
+			allErrs = append(allErrs, field.Invalid(path.Child("isCA"), true, "the generator signs with its own CA and doesn't generate CAs"))
+		}
+		if request.CARef.Name != "" {
+			allErrs = append(allErrs, field.Invalid(path.Child("CARef", "name"), request.CARef.Name, "the generator signs with its own CA, not with a referenced CA"))
+		}
+	} else if request.CARef.Name != "" {
+		if request.CARef.Key == "" {
+			allErrs = append(allErrs, field.Required(path.Child("CARef", "key"), "the key of the CA certificate in the CA secret is required"))
+		}
+		if request.CAKeyRef.Key == "" {
+			allErrs = append(allErrs, field.Required(path.Child("CAKeyRef", "key"), "the key of the CA private key in the CA secret is required"))
+		}
+	} else if signerType == qsv1a1.LocalSigner && !request.IsCA {
+		allErrs = append(allErrs, field.Required(path.Child("CARef", "name"), "locally signed certificates, which are not a CA, need a CA reference"))
+	}
+
+	if request.CAKeyRef.Name != "" && request.CAKeyRef.Key == "" {
+		allErrs = append(allErrs, field.Required(path.Child("CAKeyRef", "key"), "the key of the CA private key in the CA key secret is required"))
+	}
+
+	if request.ActivateEKSWorkaroundForSAN {
+		if signerType != qsv1a1.ClusterSigner {
+			allErrs = append(allErrs, field.Invalid(path.Child("activateEKSWorkaroundForSAN"), true, "the EKS workaround is only supported by the cluster signer"))
+		}
+		if len(request.ServiceRef) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("serviceRef"), "the EKS workaround needs a service reference"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateImageCredentialsRequest(request qsv1a1.ImageCredentialsRequest, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateOptionalSecretReference(request.Username, path.Child("username"))...)
+	allErrs = append(allErrs, validateOptionalSecretReference(request.Password, path.Child("password"))...)
+
+	return allErrs
+}
+
+func validateTemplatedConfigRequest(request qsv1a1.TemplatedConfigRequest, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	engine, err := newTemplateEngine(request.Type)
+	if err != nil {
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), request.Type, []string{HelmTemplate}))
+		return allErrs
+	}
+
+	values := map[string]interface{}{}
+	for name, ref := range request.Values {
+		valuePath := path.Child("values").Key(name)
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(valuePath.Child("name"), "the name of the referenced secret is required"))
+		}
+		if ref.Key == "" {
+			allErrs = append(allErrs, field.Required(valuePath.Child("key"), "the key in the referenced secret is required"))
+		}
+		values[name] = templatePlaceholder
+	}
+
+	// Test render the templates, referenced secrets might not exist yet
+	for key, tmpl := range request.Templates {
+		if _, err := engine.ExecuteMap(map[string]string{key: tmpl}, values); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("templates").Key(key), tmpl, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+func validatePluginRequest(request qsv1a1.PluginRequest, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for name, ref := range request.Values {
+		valuePath := path.Child("values").Key(name)
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(valuePath.Child("name"), "the name of the referenced secret is required"))
+		}
+		if ref.Key == "" {
+			allErrs = append(allErrs, field.Required(valuePath.Child("key"), "the key in the referenced secret is required"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateCopies(namespace string, copies []qsv1a1.Copy, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, copy := range copies {
+		copyPath := path.Index(i)
+		if copy.Name == "" {
+			allErrs = append(allErrs, field.Required(copyPath.Child("name"), "the name of the copy is required"))
+		}
+		if copy.Namespace == "" {
+			allErrs = append(allErrs, field.Required(copyPath.Child("namespace"), "the namespace of the copy is required"))
+		} else if copy.Namespace == namespace && copy.Cluster == "" {
+			allErrs = append(allErrs, field.Invalid(copyPath.Child("namespace"), copy.Namespace, "copies must target a namespace other than the source namespace"))
+		}
+		allErrs = append(allErrs, validateCopyTransform(copy.IncludeKeys, copy.ExcludeKeys, copy.RenameKeys, copyPath)...)
+	}
+
+	return allErrs
+}
+
+func validatePublicOutput(output qsv1a1.PublicOutput, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if output.Name != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(output.Name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("name"), output.Name, msg))
+		}
+	}
+
+	for i, namespace := range output.Namespaces {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(path.Child("namespaces").Index(i), namespace, msg))
+		}
+		if contains(output.Namespaces[:i], namespace) {
+			allErrs = append(allErrs, field.Duplicate(path.Child("namespaces").Index(i), namespace))
+		}
+	}
+
+	return allErrs
+}
+
+func validateRestartOnChange(restart qsv1a1.RestartOnChange, secretName string, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if restart.Selector == nil && !restart.AutoDiscover {
+		allErrs = append(allErrs, field.Required(path, "a selector or auto discovery is required"))
+	}
+	if restart.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(restart.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("selector"), restart.Selector, err.Error()))
+		}
+	}
+
+	// the secret name is part of the checksum annotation of the workloads
+	for _, msg := range validation.IsQualifiedName(qsv1a1.AnnotationRestartChecksumPrefix + secretName) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "secretName"), secretName, msg))
+	}
+
+	return allErrs
+}
+
+func validateCopyTransform(includeKeys []string, excludeKeys []string, renameKeys map[string]string, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, key := range excludeKeys {
+		if contains(includeKeys, key) {
+			allErrs = append(allErrs, field.Invalid(path.Child("excludeKeys"), key, "key is included and excluded"))
+		}
+	}
+
+	sources := make([]string, 0, len(renameKeys))
+	for source := range renameKeys {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	targets := map[string]string{}
+	for _, source := range sources {
+		target := renameKeys[source]
+		for _, msg := range validation.IsConfigMapKey(target) {
+			allErrs = append(allErrs, field.Invalid(path.Child("renameKeys").Key(source), target, msg))
+		}
+		if other, ok := targets[target]; ok {
+			allErrs = append(allErrs, field.Invalid(path.Child("renameKeys").Key(source), target, fmt.Sprintf("key '%s' is renamed to the same key", other)))
+		}
+		targets[target] = source
+	}
+
+	return allErrs
+}
+
+func validateOptionalSecretReference(ref qsv1a1.SecretReference, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if ref.Name != "" && ref.Key == "" {
+		allErrs = append(allErrs, field.Required(path.Child("key"), "the key in the referenced secret is required"))
+	}
+
+	return allErrs
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	"code.cloudfoundry.org/quarks-utils/pkg/config"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+	wh "code.cloudfoundry.org/quarks-utils/pkg/webhook"
+)
+
+const (
+	// WebhookServiceName is the name of the service, which points to the
+	// operators webhook server
+	WebhookServiceName = "quarks-secret-webhook"
+	// WebhookConfigPrefix is the prefix of the webhook configuration name,
+	// it's unique per operator namespace
+	WebhookConfigPrefix = "quarks-secret-hook-"
+	// ConversionWebhookPath is the path of the webhook, which converts
+	// between the QuarksSecret API versions
+	ConversionWebhookPath = "/convert"
+)
+
+// WebhooksEnabled returns true if the webhook server can be reached from the cluster
+func WebhooksEnabled(config *config.Config) bool {
+	return config.WebhookUseServiceRef || config.WebhookServerHost != ""
+}
+
+// webhookClientConfig returns how the kube api server reaches the webhook at path
+func webhookClientConfig(config *config.Config, caBundle []byte, path string) admissionregistration.WebhookClientConfig {
+	if config.WebhookUseServiceRef {
+		return admissionregistration.WebhookClientConfig{
+			CABundle: caBundle,
+			Service: &admissionregistration.ServiceReference{
+				Name:      WebhookServiceName,
+				Namespace: config.OperatorNamespace,
+				Path:      &path,
+			},
+		}
+	}
+
+	u := url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(config.WebhookServerHost, strconv.Itoa(int(config.WebhookServerPort))),
+		Path:   path,
+	}
+	urlString := u.String()
+
+	return admissionregistration.WebhookClientConfig{
+		CABundle: caBundle,
+		URL:      &urlString,
+	}
+}
+
+// createValidationWebhookServerConfig replaces the validating webhook
+// configuration for the operator with one for the given webhooks
+func createValidationWebhookServerConfig(ctx context.Context, c client.Client, config *config.Config, webhookConfig *wh.Config, webhooks []*wh.OperatorWebhook) error {
+	if len(webhookConfig.CaCertificate) == 0 {
+		return errors.Errorf("can not create a webhook server config with an empty ca certificate")
+	}
+
+	validatingConfig := &admissionregistration.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: webhookConfig.ConfigName,
+		},
+	}
+
+	for _, webhook := range webhooks {
+		ctxlog.Debugf(ctx, "Calculating validation webhook '%s'", webhook.Name)
+
+		failurePolicy := webhook.FailurePolicy
+		// Requests for other API versions are converted to the version of the rules
+		matchPolicy := admissionregistration.Equivalent
+		validatingConfig.Webhooks = append(validatingConfig.Webhooks, admissionregistration.ValidatingWebhook{
+			Name:              webhook.Name,
+			Rules:             webhook.Rules,
+			FailurePolicy:     &failurePolicy,
+			MatchPolicy:       &matchPolicy,
+			NamespaceSelector: webhook.NamespaceSelector,
+			ClientConfig:      webhookClientConfig(config, webhookConfig.CaCertificate, webhook.Path),
+		})
+	}
+
+	ctxlog.Debugf(ctx, "Creating validation webhook config '%s'", validatingConfig.Name)
+	if err := c.Delete(ctx, validatingConfig); err != nil && !apierrors.IsNotFound(err) {
+		ctxlog.Debugf(ctx, "Trying to delete existing validatingWebhookConfiguration '%s': %s", validatingConfig.Name, err.Error())
+	}
+
+	return c.Create(ctx, validatingConfig)
+}
+
+// enableConversionWebhook configures the QuarksSecret CRD to use the
+// operators conversion webhook and serves all its versions
+func enableConversionWebhook(ctx context.Context, restConfig *rest.Config, config *config.Config, caBundle []byte) error {
+	client, err := extv1client.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "could not get kube client")
+	}
+
+	clientConfig := &extv1.WebhookClientConfig{CABundle: caBundle}
+	if config.WebhookUseServiceRef {
+		path := ConversionWebhookPath
+		clientConfig.Service = &extv1.ServiceReference{
+			Name:      WebhookServiceName,
+			Namespace: config.OperatorNamespace,
+			Path:      &path,
+		}
+	} else {
+		clientConfig.URL = webhookClientConfig(config, caBundle, ConversionWebhookPath).URL
+	}
+
+	conversion := &extv1.CustomResourceConversion{
+		Strategy: extv1.WebhookConverter,
+		Webhook: &extv1.WebhookConversion{
+			ClientConfig:             clientConfig,
+			ConversionReviewVersions: []string{"v1beta1"},
+		},
+	}
+	return updateConversion(ctx, client, conversion, true)
+}
+
+// DisableConversionWebhook removes the conversion webhook from the
+// QuarksSecret CRD, e.g. after the webhook server was disabled. Without the
+// webhook only the storage version is served, the versions don't share a
+// schema.
+func DisableConversionWebhook(ctx context.Context, restConfig *rest.Config) error {
+	client, err := extv1client.NewForConfig(restConfig)
+	if err != nil {
+		return errors.Wrap(err, "could not get kube client")
+	}
+
+	conversion := &extv1.CustomResourceConversion{Strategy: extv1.NoneConverter}
+	return updateConversion(ctx, client, conversion, false)
+}
+
+// updateConversion sets the conversion of the QuarksSecret CRD and serves
+// its versions, which are not the storage version, if requested. The CRD is
+// only updated if it changes.
+func updateConversion(ctx context.Context, client extv1client.ApiextensionsV1Interface, conversion *extv1.CustomResourceConversion, serveAll bool) error {
+	crd, err := client.CustomResourceDefinitions().Get(ctx, qsv1a1.QuarksSecretResourceName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting CRD '%s'", qsv1a1.QuarksSecretResourceName)
+	}
+
+	updated := crd.DeepCopy()
+	updated.Spec.Conversion = conversion
+	for i := range updated.Spec.Versions {
+		updated.Spec.Versions[i].Served = serveAll || updated.Spec.Versions[i].Storage
+	}
+	if reflect.DeepEqual(updated.Spec, crd.Spec) {
+		return nil
+	}
+
+	ctxlog.Debugf(ctx, "Setting conversion strategy '%s' for CRD '%s'", conversion.Strategy, crd.Name)
+	_, err = client.CustomResourceDefinitions().Update(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "updating CRD '%s'", crd.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/credsgen"
+	inmemorygenerator "code.cloudfoundry.org/quarks-secret/pkg/credsgen/in_memory_generator"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
+	utilscredsgen "code.cloudfoundry.org/quarks-utils/pkg/credsgen"
+	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
+)
+
+// webhookGenerator generates the certificates of the webhook server with a
+// generator of the operator, so they follow its defaults and FIPS mode
+type webhookGenerator struct {
+	generator credsgen.Generator
+}
+
+// newWebhookGenerator returns a generator for the webhook server certificate.
+// It uses the generator of the options or their default backend, unless they
+// sign with their own CA and can't generate the CA of the webhook server,
+// then an in-memory generator with the defaults of the options is used.
+func newWebhookGenerator(ctx context.Context, options quarkssecret.Options) utilscredsgen.Generator {
+	generator := options.Generator
+	if generator == nil && options.Generators != nil {
+		generator = options.Generators.Default()
+	}
+	if generator == nil || credsgen.SignsWithOwnCA(generator) {
+		defaults := credsgen.NewDefaults()
+		if options.Defaults != nil {
+			defaults = *options.Defaults
+		}
+		generator = inmemorygenerator.NewInMemoryGeneratorWithDefaults(ctxlog.ExtractLogger(ctx), defaults)
+	}
+	return &webhookGenerator{generator: generator}
+}
+
+// GeneratePassword generates a password
+func (g *webhookGenerator) GeneratePassword(name string, request utilscredsgen.PasswordGenerationRequest) string {
+	return g.generator.GeneratePassword(name, credsgen.PasswordGenerationRequest{Length: request.Length})
+}
+
+// GenerateCertificate generates a certificate
+func (g *webhookGenerator) GenerateCertificate(name string, request utilscredsgen.CertificateGenerationRequest) (utilscredsgen.Certificate, error) {
+	cert, err := g.generator.GenerateCertificate(name, credsgen.CertificateGenerationRequest{
+		CommonName:       request.CommonName,
+		AlternativeNames: request.AlternativeNames,
+		IsCA:             request.IsCA,
+		CA: credsgen.Certificate{
+			IsCA:        request.CA.IsCA,
+			Certificate: request.CA.Certificate,
+			PrivateKey:  request.CA.PrivateKey,
+		},
+	})
+	if err != nil {
+		return utilscredsgen.Certificate{}, err
+	}
+	return utilscredsgen.Certificate{IsCA: cert.IsCA, Certificate: cert.Certificate, PrivateKey: cert.PrivateKey}, nil
+}
+
+// GenerateCertificateSigningRequest generates a certificate signing request
+// and its private key
+func (g *webhookGenerator) GenerateCertificateSigningRequest(request utilscredsgen.CertificateGenerationRequest) ([]byte, []byte, error) {
+	return g.generator.GenerateCertificateSigningRequest(credsgen.CertificateGenerationRequest{
+		CommonName:       request.CommonName,
+		AlternativeNames: request.AlternativeNames,
+		IsCA:             request.IsCA,
+	})
+}
+
+// GenerateSSHKey generates an SSH key
+func (g *webhookGenerator) GenerateSSHKey(name string) (utilscredsgen.SSHKey, error) {
+	key, err := g.generator.GenerateSSHKey(name)
+	return utilscredsgen.SSHKey(key), err
+}
+
+// GenerateRSAKey generates an RSA key
+func (g *webhookGenerator) GenerateRSAKey(name string) (utilscredsgen.RSAKey, error) {
+	key, err := g.generator.GenerateRSAKey(name)
+	return utilscredsgen.RSAKey(key), err
+}
@@ -0,0 +1,307 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	qsv1b1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1beta1"
+)
+
+// CRDs returns the CRDs applied by the operator
+func CRDs() []*extv1.CustomResourceDefinition {
+	return []*extv1.CustomResourceDefinition{
+		quarksSecretCRD(),
+		quarksSecretRotationPolicyCRD(),
+		quarksSecretCopyPolicyCRD(),
+		quarksSecretNotificationConfigCRD(),
+		quarksSecretDefaultsCRD(),
+	}
+}
+
+// quarksSecretCRD returns the CRD for all versions of QuarksSecret.
+// v1alpha1 is the storage version. v1beta1 is not served until the
+// conversion webhook is configured by the operator, see controllers.AddHooks.
+// Applying the CRD again keeps the conversion and serving of v1beta1.
+func quarksSecretCRD() *extv1.CustomResourceDefinition {
+	return &extv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: qsv1a1.QuarksSecretResourceName,
+		},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: qsv1a1.SchemeGroupVersion.Group,
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:       qsv1a1.QuarksSecretResourceKind,
+				Plural:     qsv1a1.QuarksSecretResourcePlural,
+				ShortNames: qsv1a1.QuarksSecretResourceShortNames,
+			},
+			Scope: extv1.NamespaceScoped,
+			Conversion: &extv1.CustomResourceConversion{
+				Strategy: extv1.NoneConverter,
+			},
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{
+					Name:                     qsv1a1.SchemeGroupVersion.Version,
+					Served:                   true,
+					Storage:                  true,
+					Schema:                   &qsv1a1.QuarksSecretValidation,
+					AdditionalPrinterColumns: qsv1a1.QuarksSecretAdditionalPrinterColumns,
+					Subresources: &extv1.CustomResourceSubresources{
+						Status: &extv1.CustomResourceSubresourceStatus{},
+					},
+				},
+				{
+					Name:                     qsv1b1.SchemeGroupVersion.Version,
+					Served:                   false,
+					Storage:                  false,
+					Schema:                   &qsv1b1.QuarksSecretValidation,
+					AdditionalPrinterColumns: qsv1b1.QuarksSecretAdditionalPrinterColumns,
+					Subresources: &extv1.CustomResourceSubresources{
+						Status: &extv1.CustomResourceSubresourceStatus{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// quarksSecretRotationPolicyCRD returns the CRD for QuarksSecretRotationPolicy
+func quarksSecretRotationPolicyCRD() *extv1.CustomResourceDefinition {
+	return &extv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: qsv1a1.QuarksSecretRotationPolicyResourceName,
+		},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: qsv1a1.SchemeGroupVersion.Group,
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:       qsv1a1.QuarksSecretRotationPolicyResourceKind,
+				Plural:     qsv1a1.QuarksSecretRotationPolicyResourcePlural,
+				ShortNames: qsv1a1.QuarksSecretRotationPolicyResourceShortNames,
+			},
+			Scope: extv1.NamespaceScoped,
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{
+					Name:                     qsv1a1.SchemeGroupVersion.Version,
+					Served:                   true,
+					Storage:                  true,
+					Schema:                   &qsv1a1.QuarksSecretRotationPolicyValidation,
+					AdditionalPrinterColumns: qsv1a1.QuarksSecretRotationPolicyAdditionalPrinterColumns,
+					Subresources: &extv1.CustomResourceSubresources{
+						Status: &extv1.CustomResourceSubresourceStatus{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// quarksSecretCopyPolicyCRD returns the CRD for the cluster scoped
+// QuarksSecretCopyPolicy
+func quarksSecretCopyPolicyCRD() *extv1.CustomResourceDefinition {
+	return &extv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: qsv1a1.QuarksSecretCopyPolicyResourceName,
+		},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: qsv1a1.SchemeGroupVersion.Group,
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:       qsv1a1.QuarksSecretCopyPolicyResourceKind,
+				Plural:     qsv1a1.QuarksSecretCopyPolicyResourcePlural,
+				ShortNames: qsv1a1.QuarksSecretCopyPolicyResourceShortNames,
+			},
+			Scope: extv1.ClusterScoped,
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{
+					Name:                     qsv1a1.SchemeGroupVersion.Version,
+					Served:                   true,
+					Storage:                  true,
+					Schema:                   &qsv1a1.QuarksSecretCopyPolicyValidation,
+					AdditionalPrinterColumns: qsv1a1.QuarksSecretCopyPolicyAdditionalPrinterColumns,
+				},
+			},
+		},
+	}
+}
+
+// quarksSecretNotificationConfigCRD returns the CRD for
+// QuarksSecretNotificationConfig
+func quarksSecretNotificationConfigCRD() *extv1.CustomResourceDefinition {
+	return &extv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: qsv1a1.QuarksSecretNotificationConfigResourceName,
+		},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: qsv1a1.SchemeGroupVersion.Group,
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:       qsv1a1.QuarksSecretNotificationConfigResourceKind,
+				Plural:     qsv1a1.QuarksSecretNotificationConfigResourcePlural,
+				ShortNames: qsv1a1.QuarksSecretNotificationConfigResourceShortNames,
+			},
+			Scope: extv1.NamespaceScoped,
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{
+					Name:                     qsv1a1.SchemeGroupVersion.Version,
+					Served:                   true,
+					Storage:                  true,
+					Schema:                   &qsv1a1.QuarksSecretNotificationConfigValidation,
+					AdditionalPrinterColumns: qsv1a1.QuarksSecretNotificationConfigAdditionalPrinterColumns,
+				},
+			},
+		},
+	}
+}
+
+// quarksSecretDefaultsCRD returns the CRD for QuarksSecretDefaults
+func quarksSecretDefaultsCRD() *extv1.CustomResourceDefinition {
+	return &extv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: qsv1a1.QuarksSecretDefaultsResourceName,
+		},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: qsv1a1.SchemeGroupVersion.Group,
+			Names: extv1.CustomResourceDefinitionNames{
+				Kind:       qsv1a1.QuarksSecretDefaultsResourceKind,
+				Plural:     qsv1a1.QuarksSecretDefaultsResourcePlural,
+				ShortNames: qsv1a1.QuarksSecretDefaultsResourceShortNames,
+			},
+			Scope: extv1.NamespaceScoped,
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{
+					Name:                     qsv1a1.SchemeGroupVersion.Version,
+					Served:                   true,
+					Storage:                  true,
+					Schema:                   &qsv1a1.QuarksSecretDefaultsValidation,
+					AdditionalPrinterColumns: qsv1a1.QuarksSecretDefaultsAdditionalPrinterColumns,
+				},
+			},
+		},
+	}
+}
+
+// applyCRD creates or updates the CRD. Only the fields set by the operator
+// are compared and updated, so fields defaulted by the API server don't
+// cause an update on every start. The conversion and the served flag of
+// versions, which are not served by default, are owned by the conversion
+// webhook setup, see controllers.AddHooks, and are kept.
+func applyCRD(ctx context.Context, client extv1client.ApiextensionsV1Interface, crd *extv1.CustomResourceDefinition) error {
+	existing, err := client.CustomResourceDefinitions().Get(ctx, crd.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting CRD '%s'", crd.Name)
+		}
+		_, err := client.CustomResourceDefinitions().Create(ctx, crd, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "creating CRD '%s'", crd.Name)
+		}
+		return nil
+	}
+
+	updated, err := mergeCRD(crd, existing)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(updated.Spec, existing.Spec) {
+		return nil
+	}
+
+	_, err = client.CustomResourceDefinitions().Update(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "updating CRD '%s'", crd.Name)
+	}
+
+	return nil
+}
+
+// mergeCRD returns a copy of the existing CRD with the fields set by the
+// operator taken from the desired CRD
+func mergeCRD(desired *extv1.CustomResourceDefinition, existing *extv1.CustomResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+	// the existing CRD went through JSON, e.g. empty maps are nil
+	desired, err := normalizeCRD(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := existing.DeepCopy()
+	updated.Spec.Group = desired.Spec.Group
+	updated.Spec.Names.Kind = desired.Spec.Names.Kind
+	updated.Spec.Names.Plural = desired.Spec.Names.Plural
+	updated.Spec.Names.ShortNames = desired.Spec.Names.ShortNames
+	updated.Spec.Scope = desired.Spec.Scope
+	if updated.Spec.Conversion == nil {
+		updated.Spec.Conversion = desired.Spec.Conversion
+	}
+
+	versions := []extv1.CustomResourceDefinitionVersion{}
+	for _, want := range desired.Spec.Versions {
+		version := want
+		for _, have := range existing.Spec.Versions {
+			if have.Name != want.Name {
+				continue
+			}
+			version = *have.DeepCopy()
+			version.Served = want.Served || have.Served
+			version.Storage = want.Storage
+			version.Schema = want.Schema
+			version.AdditionalPrinterColumns = want.AdditionalPrinterColumns
+			version.Subresources = want.Subresources
+			break
+		}
+		versions = append(versions, version)
+	}
+	updated.Spec.Versions = versions
+
+	return updated, nil
+}
+
+// normalizeCRD returns a copy of the CRD, which went through JSON like the
+// CRDs read from the API server
+func normalizeCRD(crd *extv1.CustomResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+	data, err := json.Marshal(crd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshalling CRD '%s'", crd.Name)
+	}
+	normalized := &extv1.CustomResourceDefinition{}
+	err = json.Unmarshal(data, normalized)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling CRD '%s'", crd.Name)
+	}
+	return normalized, nil
+}
+
+// waitForCRDReady blocks until the CRD names are accepted
+func waitForCRDReady(ctx context.Context, client extv1client.ApiextensionsV1Interface, crdName string) error {
+	err := wait.ExponentialBackoff(
+		wait.Backoff{
+			Duration: time.Second,
+			Steps:    15,
+			Factor:   1,
+		},
+		func() (bool, error) {
+			crd, err := client.CustomResourceDefinitions().Get(ctx, crdName, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			for _, cond := range crd.Status.Conditions {
+				if cond.Type == extv1.NamesAccepted && cond.Status == extv1.ConditionTrue {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		})
+	if err != nil {
+		return errors.Wrapf(err, "Waiting for CRD ready failed")
+	}
+
+	return nil
+}
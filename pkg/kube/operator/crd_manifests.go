@@ -0,0 +1,70 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+const (
+	helmTemplateStart = "{{- if not .Values.applyCRD }}\n"
+	helmTemplateEnd   = "{{- end }}\n"
+)
+
+// CRDManifests returns the YAML manifests of the CRDs by file name, like
+// they are kept in docs/crds
+func CRDManifests() (map[string][]byte, error) {
+	manifests := map[string][]byte{}
+	for _, crd := range CRDs() {
+		manifest, err := crdManifest(crd)
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("quarks_%s_%s_crd.yaml", crd.Spec.Versions[0].Name, strings.ToLower(crd.Spec.Names.Kind))
+		manifests[name] = manifest
+	}
+	return manifests, nil
+}
+
+// CRDHelmTemplate returns the template of the helm chart, which installs
+// the CRDs, if the operator doesn't apply them
+func CRDHelmTemplate() ([]byte, error) {
+	manifests := []string{}
+	for _, crd := range CRDs() {
+		manifest, err := crdManifest(crd)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, string(manifest))
+	}
+	return []byte(helmTemplateStart + strings.Join(manifests, "---\n") + helmTemplateEnd), nil
+}
+
+// crdManifest returns the YAML manifest of the CRD with the names the API
+// server defaults, but without status
+func crdManifest(crd *extv1.CustomResourceDefinition) ([]byte, error) {
+	crd = crd.DeepCopy()
+	crd.APIVersion = extv1.SchemeGroupVersion.String()
+	crd.Kind = "CustomResourceDefinition"
+	crd.Spec.Names.ListKind = crd.Spec.Names.Kind + "List"
+	crd.Spec.Names.Singular = strings.ToLower(crd.Spec.Names.Kind)
+
+	data, err := json.Marshal(crd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshalling CRD '%s'", crd.Name)
+	}
+	manifest := map[string]interface{}{}
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling CRD '%s'", crd.Name)
+	}
+	delete(manifest, "status")
+	delete(manifest["metadata"].(map[string]interface{}), "creationTimestamp")
+
+	return yaml.Marshal(manifest)
+}
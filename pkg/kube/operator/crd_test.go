@@ -0,0 +1,163 @@
+package operator_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+	qsv1b1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1beta1"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/operator"
+)
+
+var _ = Describe("ApplyCRDs", func() {
+	var (
+		ctx       context.Context
+		clientset *fake.Clientset
+	)
+
+	updates := func() int {
+		count := 0
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "update" {
+				count++
+			}
+		}
+		return count
+	}
+
+	quarksSecretCRD := func() *extv1.CustomResourceDefinition {
+		crd, err := clientset.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, qsv1a1.QuarksSecretResourceName, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		return crd
+	}
+
+	served := func(crd *extv1.CustomResourceDefinition, name string) bool {
+		for _, version := range crd.Spec.Versions {
+			if version.Name == name {
+				return version.Served
+			}
+		}
+		Fail("version not found: " + name)
+		return false
+	}
+
+	// enableConversion updates the CRD like the conversion webhook setup
+	// of the operator and the defaulting of the API server
+	enableConversion := func() {
+		crd := quarksSecretCRD()
+		crd.Spec.Names.Singular = "quarkssecret"
+		crd.Spec.Names.ListKind = "QuarksSecretList"
+		crd.Spec.Conversion = &extv1.CustomResourceConversion{
+			Strategy: extv1.WebhookConverter,
+			Webhook: &extv1.WebhookConversion{
+				ClientConfig:             &extv1.WebhookClientConfig{CABundle: []byte("ca")},
+				ConversionReviewVersions: []string{"v1beta1"},
+			},
+		}
+		for i := range crd.Spec.Versions {
+			crd.Spec.Versions[i].Served = true
+		}
+		_, err := clientset.ApiextensionsV1().CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clientset = fake.NewSimpleClientset()
+		// the API server accepts the names of new CRDs
+		clientset.PrependReactor("create", "customresourcedefinitions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			crd := action.(k8stesting.CreateAction).GetObject().(*extv1.CustomResourceDefinition)
+			crd.Status.Conditions = []extv1.CustomResourceDefinitionCondition{
+				{Type: extv1.NamesAccepted, Status: extv1.ConditionTrue},
+			}
+			return false, nil, nil
+		})
+	})
+
+	It("creates the CRDs, without serving v1beta1 before the conversion webhook is set up", func() {
+		Expect(operator.ApplyCRDsWithClient(ctx, clientset.ApiextensionsV1())).To(Succeed())
+
+		crd := quarksSecretCRD()
+		Expect(crd.Spec.Conversion.Strategy).To(Equal(extv1.NoneConverter))
+		Expect(served(crd, qsv1a1.SchemeGroupVersion.Version)).To(BeTrue())
+		Expect(served(crd, qsv1b1.SchemeGroupVersion.Version)).To(BeFalse())
+	})
+
+	It("doesn't update unchanged CRDs", func() {
+		Expect(operator.ApplyCRDsWithClient(ctx, clientset.ApiextensionsV1())).To(Succeed())
+		Expect(operator.ApplyCRDsWithClient(ctx, clientset.ApiextensionsV1())).To(Succeed())
+
+		Expect(updates()).To(Equal(0))
+	})
+
+	It("keeps the conversion webhook and serving of v1beta1 across restarts", func() {
+		Expect(operator.ApplyCRDsWithClient(ctx, clientset.ApiextensionsV1())).To(Succeed())
+		enableConversion()
+		clientset.ClearActions()
+
+		// restart of the operator
+		Expect(operator.ApplyCRDsWithClient(ctx, clientset.ApiextensionsV1())).To(Succeed())
+		Expect(operator.ApplyCRDsWithClient(ctx, clientset.ApiextensionsV1())).To(Succeed())
+
+		Expect(updates()).To(Equal(0))
+		crd := quarksSecretCRD()
+		Expect(crd.Spec.Conversion.Strategy).To(Equal(extv1.WebhookConverter))
+		Expect(served(crd, qsv1a1.SchemeGroupVersion.Version)).To(BeTrue())
+		Expect(served(crd, qsv1b1.SchemeGroupVersion.Version)).To(BeTrue())
+	})
+
+	It("keeps the conversion webhook and serving of v1beta1 when updating the schema", func() {
+		Expect(operator.ApplyCRDsWithClient(ctx, clientset.ApiextensionsV1())).To(Succeed())
+		enableConversion()
+		crd := quarksSecretCRD()
+		crd.Spec.Versions[0].Schema = &extv1.CustomResourceValidation{OpenAPIV3Schema: &extv1.JSONSchemaProps{Type: "object"}}
+		_, err := clientset.ApiextensionsV1().CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		clientset.ClearActions()
+
+		Expect(operator.ApplyCRDsWithClient(ctx, clientset.ApiextensionsV1())).To(Succeed())
+
+		Expect(updates()).To(Equal(1))
+		crd = quarksSecretCRD()
+		Expect(crd.Spec.Versions[0].Schema).To(Equal(&qsv1a1.QuarksSecretValidation))
+		Expect(crd.Spec.Names.Singular).To(Equal("quarkssecret"))
+		Expect(crd.Spec.Conversion.Strategy).To(Equal(extv1.WebhookConverter))
+		Expect(served(crd, qsv1b1.SchemeGroupVersion.Version)).To(BeTrue())
+	})
+})
+
+var _ = Describe("CRD manifests", func() {
+	It("match the manifests in docs/crds, run 'make gen-crds' if not", func() {
+		manifests, err := operator.CRDManifests()
+		Expect(err).ToNot(HaveOccurred())
+
+		files, err := ioutil.ReadDir("../../../docs/crds")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(len(manifests)))
+		for _, file := range files {
+			Expect(manifests).To(HaveKey(file.Name()))
+			content, err := ioutil.ReadFile(filepath.Join("../../../docs/crds", file.Name()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(Equal(string(manifests[file.Name()])), file.Name())
+		}
+	})
+
+	It("match the CRDs template of the helm chart, run 'make gen-crds' if not", func() {
+		template, err := operator.CRDHelmTemplate()
+		Expect(err).ToNot(HaveOccurred())
+
+		content, err := ioutil.ReadFile("../../../deploy/helm/quarks-secret/templates/crds.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal(string(template)))
+	})
+})
@@ -5,21 +5,28 @@ import (
 
 	"github.com/pkg/errors"
 
-	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
-	extv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1beta1"
+	extv1client "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
-	"code.cloudfoundry.org/quarks-utils/pkg/config"
-	"code.cloudfoundry.org/quarks-utils/pkg/crd"
 	"code.cloudfoundry.org/quarks-utils/pkg/ctxlog"
 
-	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
 	"code.cloudfoundry.org/quarks-secret/pkg/kube/controllers"
+	"code.cloudfoundry.org/quarks-secret/pkg/kube/controllers/quarkssecret"
 )
 
-// NewManager adds schemes, controllers and starts the manager
-func NewManager(ctx context.Context, config *config.Config, cfg *rest.Config, options manager.Options) (manager.Manager, error) {
+// NewManager adds schemes, controllers and starts the manager. The
+// controllers and hooks share the quarks secret options, which need a
+// config.
+func NewManager(ctx context.Context, qsecOptions quarkssecret.Options, cfg *rest.Config, options manager.Options) (manager.Manager, error) {
+	config := qsecOptions.Config
+	if config == nil {
+		return nil, errors.New("quarks secret options need a config")
+	}
+	if controllers.WebhooksEnabled(config) {
+		options.Port = int(config.WebhookServerPort)
+	}
+
 	mgr, err := manager.New(cfg, options)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize new manager")
@@ -36,11 +43,26 @@ func NewManager(ctx context.Context, config *config.Config, cfg *rest.Config, op
 	}
 
 	// Setup all Controllers
-	err = controllers.AddToManager(ctx, config, mgr)
+	err = controllers.AddToManager(ctx, qsecOptions, mgr)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to add controllers to manager")
 	}
 
+	// Setup Hooks for all resources
+	if controllers.WebhooksEnabled(config) {
+		err = controllers.AddHooks(ctx, qsecOptions, mgr)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to add hooks to manager")
+		}
+	} else {
+		log.Info("Webhook server host is not set, skipping validating webhooks")
+		log.Info("Without the conversion webhook only the storage version of QuarksSecret is served")
+		err = controllers.DisableConversionWebhook(ctx, cfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to disable the conversion webhook")
+		}
+	}
+
 	return mgr, nil
 }
 
@@ -51,26 +73,21 @@ func ApplyCRDs(ctx context.Context, config *rest.Config) error {
 		return errors.Wrap(err, "Could not get kube client")
 	}
 
-	b := crd.New(
-		qsv1a1.QuarksSecretResourceName,
-		extv1.CustomResourceDefinitionNames{
-			Kind:       qsv1a1.QuarksSecretResourceKind,
-			Plural:     qsv1a1.QuarksSecretResourcePlural,
-			ShortNames: qsv1a1.QuarksSecretResourceShortNames,
-		},
-		qsv1a1.SchemeGroupVersion,
-	)
-
-	err = b.WithValidation(&qsv1a1.QuarksSecretValidation).
-		WithAdditionalPrinterColumns(qsv1a1.QuarksSecretAdditionalPrinterColumns).
-		Build().
-		Apply(ctx, client)
-	if err != nil {
-		return errors.Wrapf(err, "failed to apply CRD '%s'", qsv1a1.QuarksSecretResourceName)
-	}
-	err = crd.WaitForCRDReady(ctx, client, qsv1a1.QuarksSecretResourceName)
-	if err != nil {
-		return errors.Wrapf(err, "failed to wait for CRD '%s' ready", qsv1a1.QuarksSecretResourceName)
+	return ApplyCRDsWithClient(ctx, client)
+}
+
+// ApplyCRDsWithClient applies a collection of CRDs into the cluster, using
+// the given client
+func ApplyCRDsWithClient(ctx context.Context, client extv1client.ApiextensionsV1Interface) error {
+	for _, crd := range CRDs() {
+		err := applyCRD(ctx, client, crd)
+		if err != nil {
+			return errors.Wrapf(err, "failed to apply CRD '%s'", crd.Name)
+		}
+		err = waitForCRDReady(ctx, client, crd.Name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to wait for CRD '%s' ready", crd.Name)
+		}
 	}
 
 	return nil
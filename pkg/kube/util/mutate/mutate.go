@@ -25,6 +25,7 @@ func QuarksSecretMutateFn(qSec *qsv1a1.QuarksSecret) controllerutil.MutateFn {
 // SecretMutateFn returns MutateFn which mutates Secret including:
 // - labels, annotations
 // - stringData
+// - data, keys missing from stringData are removed
 func SecretMutateFn(s *corev1.Secret) controllerutil.MutateFn {
 	updated := s.DeepCopy()
 	return func() error {
@@ -40,6 +41,32 @@ func SecretMutateFn(s *corev1.Secret) controllerutil.MutateFn {
 				break
 			}
 		}
+
+		// Remove keys, which are no longer generated
+		if len(updated.StringData) > 0 {
+			data := map[string][]byte{}
+			for key, value := range s.Data {
+				if _, ok := updated.StringData[key]; ok {
+					data[key] = value
+				}
+			}
+			if len(data) != len(s.Data) {
+				s.Data = data
+			}
+		}
+		return nil
+	}
+}
+
+// SecretCopyMutateFn returns MutateFn which mutates copied Secrets including:
+// - labels, annotations
+// - data, keys missing from the copy are removed
+func SecretCopyMutateFn(s *corev1.Secret) controllerutil.MutateFn {
+	updated := s.DeepCopy()
+	return func() error {
+		s.Labels = updated.Labels
+		s.Annotations = updated.Annotations
+		s.Data = updated.Data
 		return nil
 	}
 }
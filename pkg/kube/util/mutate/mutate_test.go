@@ -183,6 +183,71 @@ var _ = Describe("Mutate", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ops).To(Equal(controllerutil.OperationResultNone))
 			})
+
+			It("removes keys, which are no longer in the string data", func() {
+				client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+					switch object := object.(type) {
+					case *corev1.Secret:
+						existing := &corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "foo",
+								Namespace: "default",
+							},
+							Data: map[string][]byte{
+								"dummy":    []byte("foo-value"),
+								"obsolete": []byte("value"),
+							},
+						}
+						existing.DeepCopyInto(object)
+
+						return nil
+					}
+
+					return apierrors.NewNotFound(schema.GroupResource{}, nn.Name)
+				})
+				ops, err := controllerutil.CreateOrUpdate(ctx, client, sec, mutate.SecretMutateFn(sec))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ops).To(Equal(controllerutil.OperationResultUpdated))
+				Expect(sec.Data).To(Equal(map[string][]byte{"dummy": []byte("foo-value")}))
+			})
+		})
+	})
+
+	Describe("SecretCopyMutateFn", func() {
+		It("replaces the data of the existing copy", func() {
+			sec := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"ca.crt": []byte("certificate"),
+				},
+			}
+			client.GetCalls(func(context context.Context, nn types.NamespacedName, object runtime.Object) error {
+				switch object := object.(type) {
+				case *corev1.Secret:
+					existing := &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "foo",
+							Namespace: "default",
+						},
+						Data: map[string][]byte{
+							"ca.crt":      []byte("certificate"),
+							"private_key": []byte("key"),
+						},
+					}
+					existing.DeepCopyInto(object)
+
+					return nil
+				}
+
+				return apierrors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+			ops, err := controllerutil.CreateOrUpdate(ctx, client, sec, mutate.SecretCopyMutateFn(sec))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ops).To(Equal(controllerutil.OperationResultUpdated))
+			Expect(sec.Data).To(Equal(map[string][]byte{"ca.crt": []byte("certificate")}))
 		})
 	})
 })
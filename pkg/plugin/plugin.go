@@ -0,0 +1,141 @@
+// Package plugin implements the exec plugin protocol for custom secret types.
+//
+// A quarks secret with a type like example.com/ldap-password is generated by
+// the executable example.com/ldap-password in the plugin directory. The
+// plugin reads a Request as JSON from stdin, writes a Response as JSON to
+// stdout and exits with 0. Any other exit code fails the generation, stderr
+// is part of the error.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	qsv1a1 "code.cloudfoundry.org/quarks-secret/pkg/kube/apis/quarkssecret/v1alpha1"
+)
+
+const (
+	// APIVersion of the plugin protocol
+	APIVersion = "quarks.cloudfoundry.org/v1alpha1"
+	// RequestKind is the kind of the request sent to plugins
+	RequestKind = "PluginRequest"
+	// ResponseKind is the kind of the response expected from plugins
+	ResponseKind = "PluginResponse"
+)
+
+// Request is sent to the plugin on stdin
+type Request struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Type of the quarks secret, which selects the plugin
+	Type string `json:"type"`
+	// Name and Namespace of the quarks secret
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// SecretName is the name of the generated secret
+	SecretName string `json:"secretName"`
+	// Parameters from the plugin request of the quarks secret
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Values of the secret keys referenced by the plugin request
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// Response is read from the stdout of the plugin
+type Response struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Data of the generated secret
+	Data map[string]string `json:"data"`
+	// SecretType of the generated secret, defaults to Opaque
+	SecretType corev1.SecretType `json:"secretType,omitempty"`
+}
+
+// Runner runs the plugins from a directory
+type Runner struct {
+	dir string
+}
+
+// NewRunner returns a runner for the plugins in the directory. Without a
+// directory no plugins are available.
+func NewRunner(dir string) *Runner {
+	return &Runner{dir: dir}
+}
+
+// Path returns the path of the executable for the custom secret type
+func (r *Runner) Path(secretType string) (string, error) {
+	if !qsv1a1.IsPluginType(secretType) {
+		return "", errors.Errorf("'%s' is not a plugin type, expected <domain>/<name>", secretType)
+	}
+	if r.dir == "" {
+		return "", errors.Errorf("no plugin directory is configured for type '%s'", secretType)
+	}
+
+	path := filepath.Join(r.dir, filepath.FromSlash(secretType))
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "no plugin for type '%s'", secretType)
+	}
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return "", errors.Errorf("plugin '%s' for type '%s' is not executable", path, secretType)
+	}
+	return path, nil
+}
+
+// Run executes the plugin for the type of the request and returns its
+// validated response. The plugin is killed, when the context is done.
+func (r *Runner) Run(ctx context.Context, request Request) (Response, error) {
+	path, err := r.Path(request.Type)
+	if err != nil {
+		return Response{}, err
+	}
+
+	request.APIVersion = APIVersion
+	request.Kind = RequestKind
+	input, err := json.Marshal(request)
+	if err != nil {
+		return Response{}, errors.Wrap(err, "could not marshal plugin request")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return Response{}, errors.Wrapf(err, "plugin '%s' failed: %s", path, strings.TrimSpace(stderr.String()))
+	}
+
+	response := Response{}
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return Response{}, errors.Wrapf(err, "could not decode response of plugin '%s'", path)
+	}
+	if err := response.validate(); err != nil {
+		return Response{}, errors.Wrapf(err, "invalid response of plugin '%s'", path)
+	}
+	return response, nil
+}
+
+// validate returns an error if the response can't be written to a secret
+func (r Response) validate() error {
+	if r.APIVersion != APIVersion || r.Kind != ResponseKind {
+		return errors.Errorf("expected %s %s, got %s %s", APIVersion, ResponseKind, r.APIVersion, r.Kind)
+	}
+	if len(r.Data) == 0 {
+		return errors.New("data is empty")
+	}
+	for key := range r.Data {
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return errors.Errorf("invalid secret key '%s': %s", key, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package plugin_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/quarks-secret/pkg/plugin"
+)
+
+var _ = Describe("Runner", func() {
+	var (
+		dir     string
+		runner  *plugin.Runner
+		request plugin.Request
+	)
+
+	writePlugin := func(secretType string, script string) {
+		path := filepath.Join(dir, filepath.FromSlash(secretType))
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "quarks-secret-plugins")
+		Expect(err).ToNot(HaveOccurred())
+		runner = plugin.NewRunner(dir)
+
+		request = plugin.Request{
+			Type:       "example.com/echo",
+			Name:       "foo",
+			Namespace:  "default",
+			SecretName: "generated",
+			Parameters: map[string]string{"length": "32"},
+			Values:     map[string]string{"seed": "s3cr3t"},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("passes the request on stdin and returns the response", func() {
+		// returns the request in the 'request' key
+		writePlugin("example.com/echo", `
+request=$(sed 's/"/\\"/g')
+echo '{"apiVersion": "quarks.cloudfoundry.org/v1alpha1", "kind": "PluginResponse", "secretType": "kubernetes.io/basic-auth", "data": {"request": "'"$request"'"}}'
+`)
+
+		response, err := runner.Run(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(response.SecretType)).To(Equal("kubernetes.io/basic-auth"))
+		Expect(response.Data["request"]).To(MatchJSON(`{
+			"apiVersion": "quarks.cloudfoundry.org/v1alpha1",
+			"kind": "PluginRequest",
+			"type": "example.com/echo",
+			"name": "foo",
+			"namespace": "default",
+			"secretName": "generated",
+			"parameters": {"length": "32"},
+			"values": {"seed": "s3cr3t"}
+		}`))
+	})
+
+	It("returns stderr if the plugin fails", func() {
+		writePlugin("example.com/echo", "echo 'ldap is down' >&2; exit 3")
+
+		_, err := runner.Run(context.Background(), request)
+		Expect(err).To(MatchError(ContainSubstring("exit status 3")))
+		Expect(err).To(MatchError(ContainSubstring("ldap is down")))
+	})
+
+	It("kills the plugin when the context is done", func() {
+		writePlugin("example.com/echo", "exec sleep 10")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := runner.Run(ctx, request)
+		Expect(err).To(MatchError(ContainSubstring("killed")))
+	})
+
+	It("returns an error if the response is not json", func() {
+		writePlugin("example.com/echo", "echo password")
+
+		_, err := runner.Run(context.Background(), request)
+		Expect(err).To(MatchError(ContainSubstring("could not decode response")))
+	})
+
+	It("returns an error if the response has the wrong kind", func() {
+		writePlugin("example.com/echo", `echo '{"apiVersion": "v1", "kind": "Secret", "data": {"password": "foo"}}'`)
+
+		_, err := runner.Run(context.Background(), request)
+		Expect(err).To(MatchError(ContainSubstring("expected quarks.cloudfoundry.org/v1alpha1 PluginResponse")))
+	})
+
+	It("returns an error if the response has no data", func() {
+		writePlugin("example.com/echo", `echo '{"apiVersion": "quarks.cloudfoundry.org/v1alpha1", "kind": "PluginResponse"}'`)
+
+		_, err := runner.Run(context.Background(), request)
+		Expect(err).To(MatchError(ContainSubstring("data is empty")))
+	})
+
+	It("returns an error if a key is not a valid secret key", func() {
+		writePlugin("example.com/echo", `echo '{"apiVersion": "quarks.cloudfoundry.org/v1alpha1", "kind": "PluginResponse", "data": {"a/b": "foo"}}'`)
+
+		_, err := runner.Run(context.Background(), request)
+		Expect(err).To(MatchError(ContainSubstring("invalid secret key 'a/b'")))
+	})
+
+	Describe("Path", func() {
+		It("returns an error if there is no plugin for the type", func() {
+			_, err := runner.Path("example.com/missing")
+			Expect(err).To(MatchError(ContainSubstring("no plugin for type 'example.com/missing'")))
+		})
+
+		It("returns an error if the plugin is not executable", func() {
+			writePlugin("example.com/echo", "")
+			Expect(os.Chmod(filepath.Join(dir, "example.com", "echo"), 0644)).To(Succeed())
+
+			_, err := runner.Path("example.com/echo")
+			Expect(err).To(MatchError(ContainSubstring("is not executable")))
+		})
+
+		It("returns an error for built-in types", func() {
+			_, err := runner.Path("password")
+			Expect(err).To(MatchError(ContainSubstring("is not a plugin type")))
+		})
+
+		It("doesn't leave the plugin directory", func() {
+			_, err := runner.Path("../etc/passwd")
+			Expect(err).To(MatchError(ContainSubstring("is not a plugin type")))
+		})
+
+		It("returns an error without a plugin directory", func() {
+			_, err := plugin.NewRunner("").Path("example.com/echo")
+			Expect(err).To(MatchError(ContainSubstring("no plugin directory is configured")))
+		})
+	})
+})